@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -15,6 +17,16 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate(os.Args[2:]))
+	}
+
+	var configPath string
+	flag.StringVar(&configPath, "config", "", "path to the config file, or a consul:// or etcd:// key URL (overrides "+config.ConfigEnv+")")
+	flag.StringVar(&configPath, "c", "", "shorthand for --config")
+	flag.Parse()
+	config.SetConfigFile(configPath)
+
 	config, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
@@ -26,29 +38,130 @@ func main() {
 	}
 
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", config.Server.Port),
-		Handler: application.Router(),
+		Addr:              fmt.Sprintf(":%d", config.Server.Port),
+		Handler:           application.Router(),
+		ReadTimeout:       config.Server.ReadTimeout,
+		ReadHeaderTimeout: config.Server.ReadHeaderTimeout,
+		WriteTimeout:      config.Server.WriteTimeout,
+		IdleTimeout:       config.Server.IdleTimeout,
+		MaxHeaderBytes:    config.Server.MaxHeaderBytes,
+	}
+	server.RegisterOnShutdown(application.CloseEvents)
+
+	server.Protocols = serverProtocols(config.Server)
+	server.HTTP2 = &http.HTTP2Config{
+		MaxConcurrentStreams:      config.Server.HTTP2.MaxConcurrentStreams,
+		MaxReadFrameSize:          config.Server.HTTP2.MaxReadFrameSize,
+		MaxReceiveBufferPerStream: config.Server.HTTP2.MaxReceiveBufferPerStream,
 	}
 
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
+	listener, err := application.Listen(server.Addr, config.Server.ProxyProtocol)
+	if err != nil {
+		log.Fatalf("Could not listen: %v\n", err)
+	}
+
+	server.TLSConfig = application.TLSConfig()
 	go func() {
-		log.Printf("Starting server on :%d", config.Server.Port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if server.TLSConfig != nil {
+			log.Printf("Starting HTTPS server on :%d", config.Server.Port)
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			log.Printf("Starting server on :%d", config.Server.Port)
+			err = server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Could not listen: %v\n", err)
 		}
 	}()
 
+	var redirectServer *http.Server
+	if port := config.Server.TLS.RedirectPort; config.Server.TLS.Enabled && port != 0 {
+		redirectServer = &http.Server{
+			Addr:              fmt.Sprintf(":%d", port),
+			Handler:           application.RedirectHandler(),
+			ReadHeaderTimeout: config.Server.ReadHeaderTimeout,
+			IdleTimeout:       config.Server.IdleTimeout,
+		}
+
+		redirectListener, err := net.Listen("tcp", redirectServer.Addr)
+		if err != nil {
+			log.Fatalf("Could not listen on redirect port: %v\n", err)
+		}
+
+		go func() {
+			log.Printf("Starting HTTP to HTTPS redirect server on :%d", port)
+			if err := redirectServer.Serve(redirectListener); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Could not listen on redirect port: %v\n", err)
+			}
+		}()
+	}
+
+	var adminServer *http.Server
+	if config.Admin.Address != "" {
+		adminServer = &http.Server{
+			Addr:              config.Admin.Address,
+			Handler:           application.AdminRouter(),
+			ReadTimeout:       config.Server.ReadTimeout,
+			ReadHeaderTimeout: config.Server.ReadHeaderTimeout,
+			WriteTimeout:      config.Server.WriteTimeout,
+			IdleTimeout:       config.Server.IdleTimeout,
+			MaxHeaderBytes:    config.Server.MaxHeaderBytes,
+		}
+		adminServer.RegisterOnShutdown(application.CloseEvents)
+
+		adminListener, err := net.Listen("tcp", adminServer.Addr)
+		if err != nil {
+			log.Fatalf("Could not listen on admin address: %v\n", err)
+		}
+
+		go func() {
+			log.Printf("Starting admin server on %s", config.Admin.Address)
+			if err := adminServer.Serve(adminListener); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("Could not listen on admin address: %v\n", err)
+			}
+		}()
+	}
+
 	<-stop
 	log.Println("Shutting down server...")
 
+	application.BeginShutdown()
+	if delay := config.Server.ShutdownDelay; delay > 0 {
+		log.Printf("Reporting not ready for %s before closing listeners", delay)
+		time.Sleep(delay)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	if redirectServer != nil {
+		if err := redirectServer.Shutdown(ctx); err != nil {
+			log.Printf("Redirect server forced to shutdown: %v", err)
+		}
+	}
+	if adminServer != nil {
+		if err := adminServer.Shutdown(ctx); err != nil {
+			log.Printf("Admin server forced to shutdown: %v", err)
+		}
+	}
 	if err := server.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
+	application.Close()
 
 	log.Println("Server exited properly")
 }
+
+func serverProtocols(cfg config.ServerConfig) *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if cfg.HTTP2.Enabled {
+		protocols.SetHTTP2(cfg.TLS.Enabled)
+		protocols.SetUnencryptedHTTP2(cfg.H2C)
+	}
+	return protocols
+}
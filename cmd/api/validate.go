@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"CloudBalancer/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+func validate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	var configPath, format string
+	flags.StringVar(&configPath, "config", "", "path to the config file, or a consul:// or etcd:// key URL (overrides "+config.ConfigEnv+")")
+	flags.StringVar(&configPath, "c", "", "shorthand for --config")
+	flags.StringVar(&format, "format", "yaml", "output format of the effective config: yaml, json or none")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if format != "yaml" && format != "json" && format != "none" {
+		fmt.Fprintf(os.Stderr, "unsupported format %q, expected yaml, json or none\n", format)
+		return 2
+	}
+
+	config.SetConfigFile(configPath)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+		return 1
+	}
+
+	dump := config.Redacted(cfg)
+	switch format {
+	case "yaml":
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(dump); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode config: %v\n", err)
+			return 1
+		}
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(dump); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode config: %v\n", err)
+			return 1
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Config is valid")
+	return 0
+}
@@ -1,71 +1,883 @@
 package config
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+const (
+	DefaultPool = "default"
+	ConfigEnv   = "CLOUDBALANCER_CONFIG"
+
+	DefaultBackendConnectTimeout = 5 * time.Second
+	DefaultBackendReadTimeout    = 60 * time.Second
+
+	hostResolveTimeout = 5 * time.Second
+)
+
+var configSearchPaths = []string{"./config", "../config"}
+
+var configExtensions = []string{"yaml", "yml", "json", "toml"}
+
+var configFormats = map[string]string{
+	"yaml": "yaml",
+	"yml":  "yaml",
+	"json": "json",
+	"toml": "toml",
+}
+
+var configFile string
+
+func SetConfigFile(path string) {
+	configFile = path
+}
+
+func configLocation() string {
+	if configFile != "" {
+		return configFile
+	}
+	return os.Getenv(ConfigEnv)
+}
+
 var SupportedBalancingMethods = []string{
 	"RoundRobin",
 }
 
+var SupportedRateLimitAlgorithms = []string{
+	"TokenBucket",
+	"FixedWindow",
+	"LeakyBucket",
+	"GCRA",
+}
+
+var SupportedAccessLogFormats = []string{
+	"json",
+	"combined",
+	"common",
+}
+
+var SupportedLogLevels = []string{
+	"debug",
+	"info",
+	"warn",
+	"error",
+}
+
+var SyslogFacilities = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+var SupportedLogSinks = []string{
+	"stdout",
+	"stderr",
+	"file",
+	"syslog",
+	"journald",
+}
+
+var TraceHeaderPolicies = []string{
+	"trust",
+	"generate",
+	"strip",
+}
+
+var AccessLogFields = []string{
+	"time",
+	"request_id",
+	"client_ip",
+	"remote_addr",
+	"method",
+	"uri",
+	"path",
+	"query",
+	"protocol",
+	"host",
+	"status",
+	"bytes",
+	"duration_ms",
+	"referer",
+	"user_agent",
+	"route",
+	"user",
+}
+
+var SupportedBackendProtocols = []string{
+	"http",
+	"https",
+	"h2c",
+}
+
+var SupportedHealthCheckTypes = []string{
+	"http",
+	"tcp",
+}
+
 type Config struct {
-	Server       ServerConfig       `mapstructure:"server"`
-	LoadBalancer LoadBalancerConfig `mapstructure:"loadBalancer"`
-	Backends     []BackendConfig    `mapstructure:"backends"`
-	Logging      LoggingConfig      `mapstructure:"logging"`
-	RateLimit    RateLimitConfig    `mapstructure:"rateLimit"`
+	Server          ServerConfig            `mapstructure:"server"`
+	LoadBalancer    LoadBalancerConfig      `mapstructure:"loadBalancer"`
+	BackendDefaults BackendDefaultsConfig   `mapstructure:"backendDefaults"`
+	HealthCheck     HealthCheckConfig       `mapstructure:"healthCheck"`
+	Backends        []BackendConfig         `mapstructure:"backends"`
+	Logging         LoggingConfig           `mapstructure:"logging"`
+	RateLimit       RateLimitConfig         `mapstructure:"rateLimit"`
+	Routes          []RouteConfig           `mapstructure:"routes"`
+	Cache           CacheConfig             `mapstructure:"cache"`
+	Headers         HeaderRulesConfig       `mapstructure:"headers"`
+	Rewrite         RewriteConfig           `mapstructure:"rewrite"`
+	ErrorPages      map[int]ErrorPageConfig `mapstructure:"errorPages"`
+	Maintenance     MaintenanceConfig       `mapstructure:"maintenance"`
+	Admin           AdminConfig             `mapstructure:"admin"`
+	Observability   ObservabilityConfig     `mapstructure:"observability"`
+	Alerts          AlertsConfig            `mapstructure:"alerts"`
+	Include         []string                `mapstructure:"include"`
+
+	defaulted []DefaultedField
+}
+
+type AlertsConfig struct {
+	Enabled  bool                 `mapstructure:"enabled"`
+	Interval time.Duration        `mapstructure:"interval"`
+	Cooldown time.Duration        `mapstructure:"cooldown"`
+	Webhooks []AlertWebhookConfig `mapstructure:"webhooks"`
+	SMTP     AlertSMTPConfig      `mapstructure:"smtp"`
+	Rules    AlertRulesConfig     `mapstructure:"rules"`
+}
+
+type AlertWebhookConfig struct {
+	URL     string            `mapstructure:"url" redact:"true"`
+	Headers map[string]string `mapstructure:"headers" redact:"true"`
+	Timeout time.Duration     `mapstructure:"timeout"`
+}
+
+type AlertSMTPConfig struct {
+	Address  string   `mapstructure:"address"`
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password" redact:"true"`
+}
+
+type AlertRulesConfig struct {
+	AllBackendsDown     bool                      `mapstructure:"allBackendsDown"`
+	ConfigReloadFailure bool                      `mapstructure:"configReloadFailure"`
+	ErrorRate           ErrorRateAlertConfig      `mapstructure:"errorRate"`
+	RateLimitSpike      RateLimitSpikeAlertConfig `mapstructure:"rateLimitSpike"`
+}
+
+type ErrorRateAlertConfig struct {
+	Threshold   float64       `mapstructure:"threshold"`
+	Window      time.Duration `mapstructure:"window"`
+	MinRequests int64         `mapstructure:"minRequests"`
+}
+
+type RateLimitSpikeAlertConfig struct {
+	Threshold int64         `mapstructure:"threshold"`
+	Window    time.Duration `mapstructure:"window"`
+}
+
+type ObservabilityConfig struct {
+	Metrics MetricsConfig `mapstructure:"metrics"`
+	Top     TopConfig     `mapstructure:"top"`
+	Pprof   PprofConfig   `mapstructure:"pprof"`
+	Capture CaptureConfig `mapstructure:"capture"`
+}
+
+type CaptureConfig struct {
+	Capacity      int           `mapstructure:"capacity"`
+	MaxBodySize   int           `mapstructure:"maxBodySize"`
+	MaxTTL        time.Duration `mapstructure:"maxTTL"`
+	RedactHeaders []string      `mapstructure:"redactHeaders"`
+}
+
+type PprofConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+type TopConfig struct {
+	Capacity int           `mapstructure:"capacity"`
+	Window   time.Duration `mapstructure:"window"`
+}
+
+type MetricsConfig struct {
+	Exporter string               `mapstructure:"exporter"`
+	Interval time.Duration        `mapstructure:"interval"`
+	OTLP     OTLPExporterConfig   `mapstructure:"otlp"`
+	StatsD   StatsDExporterConfig `mapstructure:"statsd"`
+}
+
+type OTLPExporterConfig struct {
+	Endpoint    string            `mapstructure:"endpoint"`
+	Headers     map[string]string `mapstructure:"headers" redact:"true"`
+	Timeout     time.Duration     `mapstructure:"timeout"`
+	ServiceName string            `mapstructure:"serviceName"`
+}
+
+type StatsDExporterConfig struct {
+	Address string            `mapstructure:"address"`
+	Prefix  string            `mapstructure:"prefix"`
+	Flavor  string            `mapstructure:"flavor"`
+	Tags    map[string]string `mapstructure:"tags"`
+}
+
+type AdminConfig struct {
+	Address string             `mapstructure:"address"`
+	Auth    AdminAuthConfig    `mapstructure:"auth"`
+	Audit   AdminAuditConfig   `mapstructure:"audit"`
+	History AdminHistoryConfig `mapstructure:"history"`
+}
+
+type AdminHistoryConfig struct {
+	MaxEntries int `mapstructure:"maxEntries"`
+}
+
+type AdminAuditConfig struct {
+	MaxEntries int    `mapstructure:"maxEntries"`
+	Path       string `mapstructure:"path"`
+}
+
+type AdminAuthConfig struct {
+	Enabled             bool          `mapstructure:"enabled"`
+	HMACSecret          string        `mapstructure:"hmacSecret" redact:"true"`
+	JWKSURL             string        `mapstructure:"jwksURL"`
+	JWKSRefreshInterval time.Duration `mapstructure:"jwksRefreshInterval"`
+	Issuer              string        `mapstructure:"issuer"`
+	Audience            string        `mapstructure:"audience"`
+	RolesClaim          string        `mapstructure:"rolesClaim"`
+	Leeway              time.Duration `mapstructure:"leeway"`
 }
 
 type ServerConfig struct {
-	Port int `mapstructure:"port"`
+	Port                  int                 `mapstructure:"port"`
+	H2C                   bool                `mapstructure:"h2c"`
+	HTTP2                 HTTP2Config         `mapstructure:"http2"`
+	TrustedProxies        []string            `mapstructure:"trustedProxies"`
+	RealIPHeader          string              `mapstructure:"realIPHeader"`
+	Via                   string              `mapstructure:"via"`
+	IdentificationHeaders bool                `mapstructure:"identificationHeaders"`
+	ProxyProtocol         ProxyProtocolConfig `mapstructure:"proxyProtocol"`
+	ShutdownDelay         time.Duration       `mapstructure:"shutdownDelay"`
+	TraceHeaders          TraceHeadersConfig  `mapstructure:"traceHeaders"`
+	ReadTimeout           time.Duration       `mapstructure:"readTimeout"`
+	ReadHeaderTimeout     time.Duration       `mapstructure:"readHeaderTimeout"`
+	WriteTimeout          time.Duration       `mapstructure:"writeTimeout"`
+	IdleTimeout           time.Duration       `mapstructure:"idleTimeout"`
+	MaxHeaderBytes        int                 `mapstructure:"maxHeaderBytes"`
+	TLS                   TLSConfig           `mapstructure:"tls"`
+}
+
+type HTTP2Config struct {
+	Enabled                   bool `mapstructure:"enabled"`
+	MaxConcurrentStreams      int  `mapstructure:"maxConcurrentStreams"`
+	MaxReadFrameSize          int  `mapstructure:"maxReadFrameSize"`
+	MaxReceiveBufferPerStream int  `mapstructure:"maxReceiveBufferPerStream"`
+}
+
+type TLSConfig struct {
+	Enabled      bool       `mapstructure:"enabled"`
+	CertFile     string     `mapstructure:"certFile"`
+	KeyFile      string     `mapstructure:"keyFile" redact:"true"`
+	MinVersion   string     `mapstructure:"minVersion"`
+	CipherSuites []string   `mapstructure:"cipherSuites"`
+	ClientCAFile string     `mapstructure:"clientCAFile"`
+	ClientAuth   string     `mapstructure:"clientAuth"`
+	RedirectPort int        `mapstructure:"redirectPort"`
+	ACME         ACMEConfig `mapstructure:"acme"`
+}
+
+type ACMEConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Domains      []string `mapstructure:"domains"`
+	CacheDir     string   `mapstructure:"cacheDir"`
+	Email        string   `mapstructure:"email"`
+	DirectoryURL string   `mapstructure:"directoryURL"`
+	AcceptTOS    bool     `mapstructure:"acceptTOS"`
+}
+
+type TraceHeadersConfig struct {
+	RequestID   string `mapstructure:"requestID"`
+	Traceparent string `mapstructure:"traceparent"`
+	B3          string `mapstructure:"b3"`
+}
+
+type ProxyProtocolConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Required       bool          `mapstructure:"required"`
+	HeaderTimeout  time.Duration `mapstructure:"headerTimeout"`
+	AllowedSources []string      `mapstructure:"allowedSources"`
 }
 
 type LoadBalancerConfig struct {
-	Method              string        `mapstructure:"method"`
-	HealthCheckInterval time.Duration `mapstructure:"healthCheckInterval"`
+	Method               string                 `mapstructure:"method"`
+	HealthCheckInterval  time.Duration          `mapstructure:"healthCheckInterval"`
+	RequestTimeout       time.Duration          `mapstructure:"requestTimeout"`
+	WebSocketIdleTimeout time.Duration          `mapstructure:"webSocketIdleTimeout"`
+	FlushInterval        time.Duration          `mapstructure:"flushInterval"`
+	MaxBodySize          int64                  `mapstructure:"maxBodySize"`
+	Retry                RetryConfig            `mapstructure:"retry"`
+	RequestBuffering     RequestBufferingConfig `mapstructure:"requestBuffering"`
+	Canary               CanaryConfig           `mapstructure:"canary"`
+	BlueGreen            BlueGreenConfig        `mapstructure:"blueGreen"`
+	Queue                QueueConfig            `mapstructure:"queue"`
+	BackendRateLimit     BackendRateLimitPolicy `mapstructure:"backendRateLimit"`
+}
+
+type BackendRateLimitPolicy struct {
+	OnExceeded string `mapstructure:"onExceeded"`
+}
+
+type CanaryConfig struct {
+	Weight float64 `mapstructure:"weight"`
+}
+
+type QueueConfig struct {
+	MaxDepth int           `mapstructure:"maxDepth"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+type BlueGreenConfig struct {
+	ActiveGroup      string        `mapstructure:"activeGroup"`
+	ValidationWindow time.Duration `mapstructure:"validationWindow"`
+	MaxErrorRate     float64       `mapstructure:"maxErrorRate"`
+	MinRequests      int           `mapstructure:"minRequests"`
+}
+
+type RetryConfig struct {
+	Attempts          int               `mapstructure:"attempts"`
+	Methods           []string          `mapstructure:"methods"`
+	IdempotencyHeader string            `mapstructure:"idempotencyHeader"`
+	PerTryTimeout     time.Duration     `mapstructure:"perTryTimeout"`
+	Budget            RetryBudgetConfig `mapstructure:"budget"`
+}
+
+type RetryBudgetConfig struct {
+	Enabled      bool    `mapstructure:"enabled"`
+	Ratio        float64 `mapstructure:"ratio"`
+	MinPerSecond int     `mapstructure:"minPerSecond"`
+}
+
+type RouteRetryConfig struct {
+	Attempts      *int          `mapstructure:"attempts"`
+	Methods       []string      `mapstructure:"methods"`
+	PerTryTimeout time.Duration `mapstructure:"perTryTimeout"`
+}
+
+type RequestBufferingConfig struct {
+	Enabled bool  `mapstructure:"enabled"`
+	MaxSize int64 `mapstructure:"maxSize"`
 }
 
 type BackendConfig struct {
-	ID             string        `mapstructure:"id"`
-	Host           string        `mapstructure:"host"`
-	Port           int           `mapstructure:"port"`
-	ConnectTimeout time.Duration `mapstructure:"connectTimeout"`
-	ReadTimeout    time.Duration `mapstructure:"readTimeout"`
-	MaxConnection  int           `mapstructure:"maxConnection"`
-	Enabled        bool          `mapstructure:"enabled"`
+	ID             string                   `mapstructure:"id"`
+	Host           string                   `mapstructure:"host"`
+	Port           int                      `mapstructure:"port"`
+	ConnectTimeout time.Duration            `mapstructure:"connectTimeout"`
+	ReadTimeout    time.Duration            `mapstructure:"readTimeout"`
+	MaxConnection  int                      `mapstructure:"maxConnection"`
+	Enabled        bool                     `mapstructure:"enabled"`
+	Protocol       string                   `mapstructure:"protocol"`
+	Group          string                   `mapstructure:"group"`
+	Pool           string                   `mapstructure:"pool"`
+	Headers        HeaderRulesConfig        `mapstructure:"headers"`
+	ProxyProtocol  string                   `mapstructure:"proxyProtocol"`
+	HealthCheck    BackendHealthCheckConfig `mapstructure:"healthCheck"`
+	RateLimit      BackendRateLimitConfig   `mapstructure:"rateLimit"`
+}
+
+type BackendDefaultsConfig struct {
+	TLS BackendTLSConfig `mapstructure:"tls"`
+}
+
+type BackendTLSConfig struct {
+	CAFile             string `mapstructure:"caFile"`
+	CertFile           string `mapstructure:"certFile"`
+	KeyFile            string `mapstructure:"keyFile" redact:"true"`
+	ServerName         string `mapstructure:"serverName"`
+	MinVersion         string `mapstructure:"minVersion"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+}
+
+type BackendRateLimitConfig struct {
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+}
+
+type HealthCheckConfig struct {
+	Type             string        `mapstructure:"type"`
+	Path             string        `mapstructure:"path"`
+	Method           string        `mapstructure:"method"`
+	Interval         time.Duration `mapstructure:"interval"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	Rise             int           `mapstructure:"rise"`
+	Fall             int           `mapstructure:"fall"`
+	ExpectedStatuses []string      `mapstructure:"expectedStatuses"`
+}
+
+type BackendHealthCheckConfig struct {
+	Host             string        `mapstructure:"host"`
+	Port             int           `mapstructure:"port"`
+	Type             string        `mapstructure:"type"`
+	Path             string        `mapstructure:"path"`
+	Method           string        `mapstructure:"method"`
+	Interval         time.Duration `mapstructure:"interval"`
+	Timeout          time.Duration `mapstructure:"timeout"`
+	Rise             int           `mapstructure:"rise"`
+	Fall             int           `mapstructure:"fall"`
+	ExpectedStatuses []string      `mapstructure:"expectedStatuses"`
 }
 
 type LoggingConfig struct {
-	Environment string `mapstructure:"environment"`
-	Level       string `mapstructure:"level"`
+	Environment string            `mapstructure:"environment"`
+	Level       string            `mapstructure:"level"`
+	File        LogFileConfig     `mapstructure:"file"`
+	Access      AccessLogConfig   `mapstructure:"access"`
+	Sampling    LogSamplingConfig `mapstructure:"sampling"`
+	Async       AsyncLogConfig    `mapstructure:"async"`
+	Sinks       []LogSinkConfig   `mapstructure:"sinks"`
+}
+
+type LogSinkConfig struct {
+	Type   string           `mapstructure:"type"`
+	Level  string           `mapstructure:"level"`
+	Tag    string           `mapstructure:"tag"`
+	File   LogFileConfig    `mapstructure:"file"`
+	Syslog SyslogSinkConfig `mapstructure:"syslog"`
+}
+
+type SyslogSinkConfig struct {
+	Network  string `mapstructure:"network"`
+	Address  string `mapstructure:"address"`
+	Facility string `mapstructure:"facility"`
+}
+
+type LogSamplingConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Rate          int           `mapstructure:"rate"`
+	SlowThreshold time.Duration `mapstructure:"slowThreshold"`
+}
+
+type AsyncLogConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	BufferSize    int           `mapstructure:"bufferSize"`
+	FlushInterval time.Duration `mapstructure:"flushInterval"`
+}
+
+type LogFileConfig struct {
+	Path       string        `mapstructure:"path"`
+	MaxSize    int64         `mapstructure:"maxSize"`
+	MaxAge     time.Duration `mapstructure:"maxAge"`
+	MaxBackups int           `mapstructure:"maxBackups"`
+	Compress   bool          `mapstructure:"compress"`
+}
+
+type AccessLogConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Format  string        `mapstructure:"format"`
+	Fields  []string      `mapstructure:"fields"`
+	Output  string        `mapstructure:"output"`
+	File    LogFileConfig `mapstructure:"file"`
 }
 
 type RateLimitConfig struct {
-	Enabled      bool    `mapstructure:"enabled"`
-	DefaultRate  float64 `mapstructure:"defaultRate"`
-	DefaultBurst int     `mapstructure:"defaultBurst"`
+	Enabled      bool                   `mapstructure:"enabled"`
+	Algorithm    string                 `mapstructure:"algorithm"`
+	DefaultRate  float64                `mapstructure:"defaultRate"`
+	DefaultBurst int                    `mapstructure:"defaultBurst"`
+	Window       time.Duration          `mapstructure:"window"`
+	Delay        bool                   `mapstructure:"delay"`
+	IdleTTL      time.Duration          `mapstructure:"idleTTL"`
+	MaxClients   int                    `mapstructure:"maxClients"`
+	Concurrency  ConcurrencyLimitConfig `mapstructure:"concurrency"`
+	Allowlist    AllowlistConfig        `mapstructure:"allowlist"`
+	Ban          BanConfig              `mapstructure:"ban"`
+	Store        RateLimitStoreConfig   `mapstructure:"store"`
+	Tiers        map[string]TierConfig  `mapstructure:"tiers"`
+	Clients      []ClientLimitConfig    `mapstructure:"clients"`
+	Bandwidth    BandwidthLimitConfig   `mapstructure:"bandwidth"`
+	Shaping      ShapingConfig          `mapstructure:"shaping"`
+	Quota        QuotaConfig            `mapstructure:"quota"`
+}
+
+type QuotaConfig struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Limit         int64         `mapstructure:"limit"`
+	Period        string        `mapstructure:"period"`
+	Timezone      string        `mapstructure:"timezone"`
+	Path          string        `mapstructure:"path"`
+	FlushInterval time.Duration `mapstructure:"flushInterval"`
+}
+
+type ShapingConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	MaxWait time.Duration `mapstructure:"maxWait"`
+}
+
+type BandwidthLimitConfig struct {
+	BytesPerSecond int64 `mapstructure:"bytesPerSecond"`
+	Burst          int   `mapstructure:"burst"`
+}
+
+type TierConfig struct {
+	Rate        float64  `mapstructure:"rate"`
+	Burst       int      `mapstructure:"burst"`
+	Concurrency int      `mapstructure:"concurrency"`
+	Clients     []string `mapstructure:"clients" redact:"true"`
+}
+
+type ClientLimitConfig struct {
+	ID    string  `mapstructure:"id" redact:"true"`
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+	Tier  string  `mapstructure:"tier"`
+}
+
+type RateLimitStoreConfig struct {
+	Type string `mapstructure:"type"`
+	Path string `mapstructure:"path"`
+}
+
+type BanConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Threshold   int           `mapstructure:"threshold"`
+	Window      time.Duration `mapstructure:"window"`
+	Duration    time.Duration `mapstructure:"duration"`
+	MaxDuration time.Duration `mapstructure:"maxDuration"`
+	Multiplier  float64       `mapstructure:"multiplier"`
+	Status      int           `mapstructure:"status"`
+}
+
+type AllowlistConfig struct {
+	Clients []string          `mapstructure:"clients" json:"clients" redact:"true"`
+	CIDRs   []string          `mapstructure:"cidrs" json:"cidrs"`
+	Headers map[string]string `mapstructure:"headers" json:"headers" redact:"true"`
+}
+
+type ConcurrencyLimitConfig struct {
+	PerClient int `mapstructure:"perClient"`
+	Global    int `mapstructure:"global"`
+}
+
+type CacheConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	DefaultTTL   time.Duration `mapstructure:"defaultTTL"`
+	MaxEntrySize int64         `mapstructure:"maxEntrySize"`
+	MaxMemory    int64         `mapstructure:"maxMemory"`
+}
+
+type RouteConfig struct {
+	Host         string               `mapstructure:"host"`
+	Path         string               `mapstructure:"path"`
+	PathRegex    string               `mapstructure:"pathRegex"`
+	Rewrite      string               `mapstructure:"rewrite"`
+	Pool         string               `mapstructure:"pool"`
+	Methods      []string             `mapstructure:"methods"`
+	MatchHeaders map[string]string    `mapstructure:"matchHeaders"`
+	Timeout      time.Duration        `mapstructure:"timeout"`
+	Streaming    bool                 `mapstructure:"streaming"`
+	MaxBodySize  int64                `mapstructure:"maxBodySize"`
+	CacheTTL     time.Duration        `mapstructure:"cacheTTL"`
+	Hedge        HedgeConfig          `mapstructure:"hedge"`
+	Retry        RouteRetryConfig     `mapstructure:"retry"`
+	Static       StaticConfig         `mapstructure:"static"`
+	Cost         int                  `mapstructure:"cost"`
+	Headers      HeaderRulesConfig    `mapstructure:"headers"`
+	AccessLog    RouteAccessLogConfig `mapstructure:"accessLog"`
+}
+
+type RouteAccessLogConfig struct {
+	Disabled   bool                   `mapstructure:"disabled" json:"disabled,omitempty"`
+	SampleRate int                    `mapstructure:"sampleRate" json:"sample_rate,omitempty"`
+	Fields     []AccessLogFieldConfig `mapstructure:"fields" json:"fields,omitempty"`
+}
+
+type AccessLogFieldConfig struct {
+	Name   string `mapstructure:"name" json:"name"`
+	Header string `mapstructure:"header" json:"header,omitempty"`
+	Query  string `mapstructure:"query" json:"query,omitempty"`
+	Hash   bool   `mapstructure:"hash" json:"hash,omitempty"`
+}
+
+type HedgeConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Percentile float64       `mapstructure:"percentile"`
+	Delay      time.Duration `mapstructure:"delay"`
+}
+
+type StaticConfig struct {
+	Root         string `mapstructure:"root"`
+	Index        string `mapstructure:"index"`
+	SPA          bool   `mapstructure:"spa"`
+	CacheControl string `mapstructure:"cacheControl"`
+	StripPrefix  bool   `mapstructure:"stripPrefix"`
+}
+
+type MaintenanceConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Pools      []string      `mapstructure:"pools"`
+	Paths      []string      `mapstructure:"paths"`
+	Message    string        `mapstructure:"message"`
+	RetryAfter time.Duration `mapstructure:"retryAfter"`
+}
+
+type ErrorPageConfig struct {
+	ContentType string `mapstructure:"contentType"`
+	Template    string `mapstructure:"template"`
+	File        string `mapstructure:"file"`
+}
+
+type RewriteConfig struct {
+	DebugHeader string              `mapstructure:"debugHeader"`
+	Rules       []RewriteRuleConfig `mapstructure:"rules"`
+}
+
+type RewriteRuleConfig struct {
+	Name        string `mapstructure:"name"`
+	Target      string `mapstructure:"target"`
+	Prefix      string `mapstructure:"prefix"`
+	Regex       string `mapstructure:"regex"`
+	Replacement string `mapstructure:"replacement"`
+}
+
+type HeaderRulesConfig struct {
+	Request  HeaderActionsConfig `mapstructure:"request" json:"request"`
+	Response HeaderActionsConfig `mapstructure:"response" json:"response"`
+}
+
+type HeaderActionsConfig struct {
+	Add    map[string]string `mapstructure:"add" json:"add,omitempty" redact:"true"`
+	Set    map[string]string `mapstructure:"set" json:"set,omitempty" redact:"true"`
+	Remove []string          `mapstructure:"remove" json:"remove,omitempty"`
 }
 
 func LoadConfig() (*Config, error) {
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
+	path := configLocation()
+	if path == "" {
+		var err error
+		if path, err = findConfigFile(); err != nil {
+			return nil, err
+		}
+	}
+
+	remote, err := parseRemoteSource(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var format string
+	var data []byte
+	if remote != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteFetchTimeout)
+		data, _, err = remote.fetch(ctx)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		format = remote.format
+		viper.SetConfigType(format)
+		if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("error reading remote config: %w", err)
+		}
+
+		fmt.Fprintf(os.Stderr, "Using remote config: %s\n", remote)
+	} else {
+		var ok bool
+		format, ok = configFormats[strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))]
+		if !ok {
+			return nil, fmt.Errorf("unsupported config file extension %q, expected one of .yaml, .yml, .json or .toml", filepath.Ext(path))
+		}
+		viper.SetConfigFile(path)
+		viper.SetConfigType(format)
 
-	viper.AddConfigPath("./config")
-	viper.AddConfigPath("../config")
+		if err := viper.ReadInConfig(); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("config file %s not found", path)
+			}
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
+		data, _ = os.ReadFile(path)
 
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("error reading config file: %w", err)
+		fmt.Fprintf(os.Stderr, "Using config file: %s\n", viper.ConfigFileUsed())
 	}
 
-	fmt.Printf("Using config file: %s\n", viper.ConfigFileUsed())
+	if includes := viper.GetStringSlice("include"); len(includes) > 0 {
+		baseDir := "."
+		if remote == nil {
+			baseDir = filepath.Dir(path)
+		}
+		if err := mergeIncludes(includes, baseDir); err != nil {
+			return nil, err
+		}
+		data = nil
+	}
 
 	viper.SetDefault("loadBalancer.method", "RoundRobin")
 	viper.SetDefault("loadBalancer.healthCheckInterval", "10s")
+	viper.SetDefault("loadBalancer.requestTimeout", "60s")
+
+	viper.SetDefault("healthCheck.type", "http")
+	viper.SetDefault("healthCheck.path", "/health")
+	viper.SetDefault("healthCheck.method", http.MethodGet)
+	viper.SetDefault("healthCheck.interval", viper.GetString("loadBalancer.healthCheckInterval"))
+	viper.SetDefault("healthCheck.timeout", min(5*time.Second, viper.GetDuration("healthCheck.interval")/2))
+	viper.SetDefault("healthCheck.rise", 1)
+	viper.SetDefault("healthCheck.fall", 1)
+	viper.SetDefault("healthCheck.expectedStatuses", []string{"200"})
+
+	viper.SetDefault("loadBalancer.retry.attempts", 0)
+	viper.SetDefault("loadBalancer.retry.methods", []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE"})
+	viper.SetDefault("loadBalancer.retry.idempotencyHeader", "Idempotency-Key")
+	viper.SetDefault("loadBalancer.retry.perTryTimeout", "0s")
+	viper.SetDefault("loadBalancer.retry.budget.enabled", true)
+	viper.SetDefault("loadBalancer.retry.budget.ratio", 0.2)
+	viper.SetDefault("loadBalancer.retry.budget.minPerSecond", 10)
+	viper.SetDefault("loadBalancer.requestBuffering.enabled", true)
+	viper.SetDefault("loadBalancer.requestBuffering.maxSize", 1<<20)
+
+	viper.SetDefault("loadBalancer.blueGreen.activeGroup", "blue")
+	viper.SetDefault("loadBalancer.blueGreen.validationWindow", "60s")
+	viper.SetDefault("loadBalancer.blueGreen.maxErrorRate", 0.1)
+	viper.SetDefault("loadBalancer.blueGreen.minRequests", 20)
+	viper.SetDefault("loadBalancer.queue.maxDepth", 100)
+	viper.SetDefault("loadBalancer.queue.timeout", "10s")
+	viper.SetDefault("loadBalancer.backendRateLimit.onExceeded", "queue")
+
+	viper.SetDefault("cache.enabled", false)
+	viper.SetDefault("cache.defaultTTL", "0s")
+	viper.SetDefault("cache.maxEntrySize", 1<<20)
+	viper.SetDefault("cache.maxMemory", 64<<20)
+
+	viper.SetDefault("server.h2c", false)
+	viper.SetDefault("server.http2.enabled", true)
+	viper.SetDefault("server.http2.maxConcurrentStreams", 250)
+	viper.SetDefault("server.http2.maxReadFrameSize", 1<<20)
+	viper.SetDefault("server.http2.maxReceiveBufferPerStream", 1<<20)
+	viper.SetDefault("server.realIPHeader", "X-Forwarded-For")
+	viper.SetDefault("server.via", "cloudbalancer")
+	viper.SetDefault("server.identificationHeaders", false)
+	viper.SetDefault("server.proxyProtocol.enabled", false)
+	viper.SetDefault("server.proxyProtocol.headerTimeout", "5s")
+	viper.SetDefault("server.shutdownDelay", "0s")
+	viper.SetDefault("server.readTimeout", "0s")
+	viper.SetDefault("server.readHeaderTimeout", "10s")
+	viper.SetDefault("server.writeTimeout", "0s")
+	viper.SetDefault("server.idleTimeout", "120s")
+	viper.SetDefault("server.maxHeaderBytes", 1<<20)
+	viper.SetDefault("server.tls.enabled", false)
+	viper.SetDefault("server.tls.minVersion", "1.2")
+	viper.SetDefault("server.tls.redirectPort", 0)
+	viper.SetDefault("server.tls.acme.enabled", false)
+	viper.SetDefault("server.tls.acme.cacheDir", "acme-cache")
+	viper.SetDefault("server.tls.acme.directoryURL", "https://acme-v02.api.letsencrypt.org/directory")
+	viper.SetDefault("backendDefaults.tls.minVersion", "1.2")
+	viper.SetDefault("server.traceHeaders.requestID", "trust")
+	viper.SetDefault("server.traceHeaders.traceparent", "trust")
+	viper.SetDefault("server.traceHeaders.b3", "trust")
+
+	viper.SetDefault("maintenance.enabled", false)
+	viper.SetDefault("maintenance.message", "Service is temporarily down for maintenance")
+	viper.SetDefault("maintenance.retryAfter", "300s")
+
+	viper.SetDefault("admin.address", "")
+	viper.SetDefault("admin.auth.enabled", false)
+	viper.SetDefault("admin.auth.jwksRefreshInterval", "10m")
+	viper.SetDefault("admin.auth.rolesClaim", "roles")
+	viper.SetDefault("admin.auth.leeway", "30s")
+	viper.SetDefault("admin.audit.maxEntries", 1000)
+	viper.SetDefault("admin.audit.path", "")
+	viper.SetDefault("admin.history.maxEntries", 20)
+
+	production := viper.GetString("logging.environment") == "production"
+	viper.SetDefault("logging.sampling.enabled", production)
+	viper.SetDefault("logging.sampling.rate", 100)
+	viper.SetDefault("logging.sampling.slowThreshold", "1s")
+	viper.SetDefault("logging.async.enabled", production)
+	viper.SetDefault("logging.async.bufferSize", 256<<10)
+	viper.SetDefault("logging.async.flushInterval", "1s")
+	viper.SetDefault("logging.file.path", "")
+	viper.SetDefault("logging.file.maxSize", 100<<20)
+	viper.SetDefault("logging.file.maxAge", "24h")
+	viper.SetDefault("logging.file.maxBackups", 7)
+	viper.SetDefault("logging.file.compress", true)
+	viper.SetDefault("logging.access.enabled", false)
+	viper.SetDefault("logging.access.format", "json")
+	viper.SetDefault("logging.access.fields", AccessLogFields)
+	viper.SetDefault("logging.access.output", "stdout")
+	viper.SetDefault("logging.access.file.path", "")
+	viper.SetDefault("logging.access.file.maxSize", 100<<20)
+	viper.SetDefault("logging.access.file.maxAge", "24h")
+	viper.SetDefault("logging.access.file.maxBackups", 7)
+	viper.SetDefault("logging.access.file.compress", true)
+
+	viper.SetDefault("observability.pprof.enabled", false)
+	viper.SetDefault("observability.capture.capacity", 200)
+	viper.SetDefault("observability.capture.maxBodySize", 4096)
+	viper.SetDefault("observability.capture.maxTTL", "1h")
+	viper.SetDefault("observability.capture.redactHeaders", []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-API-Key"})
+	viper.SetDefault("observability.top.capacity", 100)
+	viper.SetDefault("observability.top.window", "5m")
+	viper.SetDefault("observability.metrics.exporter", "none")
+	viper.SetDefault("observability.metrics.interval", "10s")
+	viper.SetDefault("observability.metrics.otlp.timeout", "10s")
+	viper.SetDefault("observability.metrics.otlp.serviceName", "cloudbalancer")
+	viper.SetDefault("observability.metrics.statsd.prefix", "")
+	viper.SetDefault("observability.metrics.statsd.flavor", "statsd")
+
+	viper.SetDefault("alerts.enabled", false)
+	viper.SetDefault("alerts.interval", "15s")
+	viper.SetDefault("alerts.cooldown", "5m")
+	viper.SetDefault("alerts.rules.allBackendsDown", true)
+	viper.SetDefault("alerts.rules.configReloadFailure", true)
+	viper.SetDefault("alerts.rules.errorRate.threshold", 0)
+	viper.SetDefault("alerts.rules.errorRate.window", "1m")
+	viper.SetDefault("alerts.rules.errorRate.minRequests", 20)
+	viper.SetDefault("alerts.rules.rateLimitSpike.threshold", 0)
+	viper.SetDefault("alerts.rules.rateLimitSpike.window", "1m")
 
 	viper.SetDefault("rateLimit.enabled", true)
+	viper.SetDefault("rateLimit.algorithm", "TokenBucket")
+	viper.SetDefault("rateLimit.window", "1m")
+	viper.SetDefault("rateLimit.delay", false)
+	viper.SetDefault("rateLimit.idleTTL", "10m")
+	viper.SetDefault("rateLimit.maxClients", 100000)
+	viper.SetDefault("rateLimit.concurrency.perClient", 0)
+	viper.SetDefault("rateLimit.concurrency.global", 0)
+	viper.SetDefault("rateLimit.ban.enabled", false)
+	viper.SetDefault("rateLimit.ban.threshold", 20)
+	viper.SetDefault("rateLimit.ban.window", "1m")
+	viper.SetDefault("rateLimit.ban.duration", "1m")
+	viper.SetDefault("rateLimit.ban.maxDuration", "1h")
+	viper.SetDefault("rateLimit.ban.multiplier", 2.0)
+	viper.SetDefault("rateLimit.ban.status", 429)
+	viper.SetDefault("rateLimit.quota.enabled", false)
+	viper.SetDefault("rateLimit.quota.limit", 100000)
+	viper.SetDefault("rateLimit.quota.period", "day")
+	viper.SetDefault("rateLimit.quota.timezone", "UTC")
+	viper.SetDefault("rateLimit.quota.path", "")
+	viper.SetDefault("rateLimit.quota.flushInterval", "10s")
+	viper.SetDefault("rateLimit.shaping.enabled", false)
+	viper.SetDefault("rateLimit.shaping.maxWait", "500ms")
+	viper.SetDefault("rateLimit.bandwidth.bytesPerSecond", 0)
+	viper.SetDefault("rateLimit.bandwidth.burst", 0)
+	viper.SetDefault("rateLimit.store.type", "memory")
+	viper.SetDefault("rateLimit.store.path", "")
 	viper.SetDefault("rateLimit.defaultRate", 100.0)
 	viper.SetDefault("rateLimit.defaultBurst", 50)
 
@@ -74,56 +886,1093 @@ func LoadConfig() (*Config, error) {
 	viper.RegisterAlias("backends.readTimeout", "backends.readTimeout")
 
 	var config Config
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := viper.Unmarshal(&config, viper.DecodeHook(decodeHook())); err != nil {
 		return nil, fmt.Errorf("error unmarshaling config: %w", err)
 	}
 
-	if err := validateConfig(&config); err != nil {
+	for i := range config.Logging.Sinks {
+		sink := &config.Logging.Sinks[i]
+		sink.Type = strings.ToLower(sink.Type)
+		if sink.File.MaxSize == 0 {
+			sink.File.MaxSize = config.Logging.File.MaxSize
+		}
+		if sink.File.MaxAge == 0 {
+			sink.File.MaxAge = config.Logging.File.MaxAge
+		}
+		if sink.File.MaxBackups == 0 {
+			sink.File.MaxBackups = config.Logging.File.MaxBackups
+		}
+		if sink.Syslog.Facility == "" {
+			sink.Syslog.Facility = "daemon"
+		}
+		if sink.Tag == "" {
+			sink.Tag = "cloudbalancer"
+		}
+	}
+
+	for i := range config.Backends {
+		if config.Backends[i].Protocol == "" {
+			config.Backends[i].Protocol = "http"
+		}
+		if config.Backends[i].Group == "" {
+			config.Backends[i].Group = "stable"
+		}
+		if config.Backends[i].Pool == "" {
+			config.Backends[i].Pool = DefaultPool
+		}
+		config.defaulted = append(config.defaulted, ApplyBackendTimeoutDefaults(&config.Backends[i])...)
+	}
+
+	for i, method := range config.LoadBalancer.Retry.Methods {
+		config.LoadBalancer.Retry.Methods[i] = strings.ToUpper(method)
+	}
+	config.LoadBalancer.Retry.IdempotencyHeader = http.CanonicalHeaderKey(config.LoadBalancer.Retry.IdempotencyHeader)
+
+	for i := range config.Rewrite.Rules {
+		if config.Rewrite.Rules[i].Name == "" {
+			config.Rewrite.Rules[i].Name = fmt.Sprintf("rewrite-%d", i)
+		}
+		if config.Rewrite.Rules[i].Target == "" {
+			config.Rewrite.Rules[i].Target = "path"
+		}
+	}
+
+	for i := range config.Routes {
+		config.Routes[i].Host = strings.ToLower(config.Routes[i].Host)
+		for j, method := range config.Routes[i].Methods {
+			config.Routes[i].Methods[j] = strings.ToUpper(method)
+		}
+		for j, method := range config.Routes[i].Retry.Methods {
+			config.Routes[i].Retry.Methods[j] = strings.ToUpper(method)
+		}
+		if matchHeaders := config.Routes[i].MatchHeaders; len(matchHeaders) > 0 {
+			config.Routes[i].MatchHeaders = make(map[string]string, len(matchHeaders))
+			for name, value := range matchHeaders {
+				config.Routes[i].MatchHeaders[http.CanonicalHeaderKey(name)] = value
+			}
+		}
+		if config.Routes[i].Path == "" {
+			config.Routes[i].Path = "/"
+		}
+		if config.Routes[i].Pool == "" && config.Routes[i].Static.Root == "" {
+			config.Routes[i].Pool = DefaultPool
+		}
+	}
+
+	var lines map[string]int
+	if format == "yaml" {
+		lines = yamlLines(data)
+	}
+
+	backends, origins, err := expandBackends(config.Backends)
+	if err != nil {
+		var problem ValidationError
+		if errors.As(err, &problem) {
+			problem.Line = (&validator{lines: lines}).line(problem.Path)
+			return nil, ValidationErrors{problem}
+		}
+		return nil, err
+	}
+	config.Backends = backends
+	lines = remapBackendLines(lines, origins)
+	if err := validateConfig(&config, lines); err != nil {
 		return nil, err
 	}
 
 	return &config, nil
 }
 
-func validateConfig(config *Config) error {
-	validMethod := false
-	for _, method := range SupportedBalancingMethods {
-		if config.LoadBalancer.Method == method {
-			validMethod = true
-			break
+func validateConfig(config *Config, lines map[string]int) error {
+	v := &validator{lines: lines}
+
+	for i, proxy := range config.Server.TrustedProxies {
+		if !isIPOrCIDR(proxy) {
+			v.addf(fmt.Sprintf("server.trustedProxies[%d]", i), "invalid trusted proxy %q: must be an IP address or CIDR", proxy)
 		}
 	}
-	if !validMethod {
-		return fmt.Errorf("unsupported balancing method: %s. Supported methods: %v",
-			config.LoadBalancer.Method, SupportedBalancingMethods)
+
+	if header := config.Server.RealIPHeader; header == "" || strings.ContainsAny(header, ": \t\r\n") {
+		v.addf("server.realIPHeader", "invalid real IP header %q: must be a single header name", header)
 	}
 
-	if len(config.Backends) == 0 {
-		return fmt.Errorf("no backends configured")
+	if strings.ContainsAny(config.Server.Via, ", \t\r\n") {
+		v.addf("server.via", "invalid via pseudonym %q: must be a single token", config.Server.Via)
 	}
 
-	enabledBackends := 0
-	for i, backend := range config.Backends {
-		if backend.ID == "" {
-			return fmt.Errorf("backend #%d has empty ID", i)
+	if config.Server.ShutdownDelay < 0 {
+		v.addf("server.shutdownDelay", "shutdown delay must not be negative, got %s", config.Server.ShutdownDelay)
+	}
+
+	if config.Server.ReadTimeout < 0 {
+		v.addf("server.readTimeout", "read timeout must not be negative, got %s", config.Server.ReadTimeout)
+	}
+	if config.Server.ReadHeaderTimeout <= 0 {
+		v.addf("server.readHeaderTimeout", "read header timeout must be positive, got %s", config.Server.ReadHeaderTimeout)
+	} else if config.Server.ReadTimeout > 0 && config.Server.ReadHeaderTimeout > config.Server.ReadTimeout {
+		v.addf("server.readHeaderTimeout", "read header timeout %s must not exceed the read timeout %s", config.Server.ReadHeaderTimeout, config.Server.ReadTimeout)
+	}
+	if config.Server.WriteTimeout < 0 {
+		v.addf("server.writeTimeout", "write timeout must not be negative, got %s", config.Server.WriteTimeout)
+	}
+	if config.Server.IdleTimeout < 0 {
+		v.addf("server.idleTimeout", "idle timeout must not be negative, got %s", config.Server.IdleTimeout)
+	}
+	if config.Server.MaxHeaderBytes < 4096 || config.Server.MaxHeaderBytes > 64<<20 {
+		v.addf("server.maxHeaderBytes", "max header bytes must be between 4096 and %d, got %d", 64<<20, config.Server.MaxHeaderBytes)
+	}
+
+	if http2 := config.Server.HTTP2; http2.Enabled {
+		if http2.MaxConcurrentStreams < 1 {
+			v.addf("server.http2.maxConcurrentStreams", "HTTP/2 max concurrent streams must be positive, got %d", http2.MaxConcurrentStreams)
 		}
-		if backend.Enabled {
-			enabledBackends++
+		if http2.MaxReadFrameSize < 16<<10 || http2.MaxReadFrameSize > 1<<24-1 {
+			v.addf("server.http2.maxReadFrameSize", "HTTP/2 max read frame size must be between 16384 and 16777215, got %d", http2.MaxReadFrameSize)
 		}
+		if http2.MaxReceiveBufferPerStream < 1 {
+			v.addf("server.http2.maxReceiveBufferPerStream", "HTTP/2 max receive buffer per stream must be positive, got %d", http2.MaxReceiveBufferPerStream)
+		}
+	} else if config.Server.H2C {
+		v.addf("server.h2c", "h2c requires server.http2.enabled")
+	}
+	if config.Server.H2C && config.Server.TLS.Enabled {
+		v.addf("server.h2c", "h2c applies only to the plaintext listener and cannot be combined with TLS")
 	}
 
-	if enabledBackends == 0 {
-		return fmt.Errorf("no enabled backends configured")
+	validateServerTLS(v, config.Server.Port, config.Server.TLS)
+	validateBackendTLS(v, config.BackendDefaults.TLS)
+
+	if policy := config.Server.TraceHeaders.RequestID; policy != "trust" && policy != "generate" {
+		v.addf("server.traceHeaders.requestID", "unsupported request ID header policy %q. Supported policies: [trust generate]", policy)
+	}
+	if policy := config.Server.TraceHeaders.Traceparent; !slices.Contains(TraceHeaderPolicies, policy) {
+		v.addf("server.traceHeaders.traceparent", "unsupported traceparent header policy %q. Supported policies: %v", policy, TraceHeaderPolicies)
+	}
+	if policy := config.Server.TraceHeaders.B3; !slices.Contains(TraceHeaderPolicies, policy) {
+		v.addf("server.traceHeaders.b3", "unsupported B3 header policy %q. Supported policies: %v", policy, TraceHeaderPolicies)
 	}
 
-	if config.RateLimit.Enabled {
-		if config.RateLimit.DefaultRate <= 0 {
-			return fmt.Errorf("rate limit default rate must be positive, got %f", config.RateLimit.DefaultRate)
+	if config.Server.ProxyProtocol.HeaderTimeout < 0 {
+		v.addf("server.proxyProtocol.headerTimeout", "proxy protocol header timeout must not be negative, got %s", config.Server.ProxyProtocol.HeaderTimeout)
+	}
+	for i, source := range config.Server.ProxyProtocol.AllowedSources {
+		if !isIPOrCIDR(source) {
+			v.addf(fmt.Sprintf("server.proxyProtocol.allowedSources[%d]", i), "invalid proxy protocol allowed source %q: must be an IP address or CIDR", source)
 		}
-		if config.RateLimit.DefaultBurst <= 0 {
-			return fmt.Errorf("rate limit default burst must be positive, got %d", config.RateLimit.DefaultBurst)
+	}
+
+	if !slices.Contains(SupportedBalancingMethods, config.LoadBalancer.Method) {
+		v.addf("loadBalancer.method", "unsupported balancing method: %s. Supported methods: %v",
+			config.LoadBalancer.Method, SupportedBalancingMethods)
+	}
+
+	if config.LoadBalancer.RequestTimeout < 0 {
+		v.addf("loadBalancer.requestTimeout", "request timeout must not be negative, got %s", config.LoadBalancer.RequestTimeout)
+	}
+
+	if config.LoadBalancer.WebSocketIdleTimeout < 0 {
+		v.addf("loadBalancer.webSocketIdleTimeout", "websocket idle timeout must not be negative, got %s", config.LoadBalancer.WebSocketIdleTimeout)
+	}
+
+	if config.LoadBalancer.MaxBodySize < 0 {
+		v.addf("loadBalancer.maxBodySize", "max body size must not be negative, got %d", config.LoadBalancer.MaxBodySize)
+	}
+
+	if config.LoadBalancer.Retry.Attempts < 0 {
+		v.addf("loadBalancer.retry.attempts", "retry attempts must not be negative, got %d", config.LoadBalancer.Retry.Attempts)
+	}
+	v.add("loadBalancer.retry.methods", validateRetryMethods(config.LoadBalancer.Retry.Methods))
+	if config.LoadBalancer.Retry.PerTryTimeout < 0 {
+		v.addf("loadBalancer.retry.perTryTimeout", "retry per-try timeout must not be negative, got %s", config.LoadBalancer.Retry.PerTryTimeout)
+	}
+	if budget := config.LoadBalancer.Retry.Budget; budget.Enabled {
+		if budget.Ratio < 0 {
+			v.addf("loadBalancer.retry.budget.ratio", "retry budget ratio must not be negative, got %g", budget.Ratio)
+		}
+		if budget.MinPerSecond < 0 {
+			v.addf("loadBalancer.retry.budget.minPerSecond", "retry budget min per second must not be negative, got %d", budget.MinPerSecond)
 		}
 	}
 
+	if config.LoadBalancer.RequestBuffering.Enabled && config.LoadBalancer.RequestBuffering.MaxSize <= 0 {
+		v.addf("loadBalancer.requestBuffering.maxSize", "request buffering max size must be positive, got %d", config.LoadBalancer.RequestBuffering.MaxSize)
+	}
+
+	if config.LoadBalancer.Canary.Weight < 0 || config.LoadBalancer.Canary.Weight > 100 {
+		v.addf("loadBalancer.canary.weight", "canary weight must be between 0 and 100, got %g", config.LoadBalancer.Canary.Weight)
+	}
+
+	blueGreen := config.LoadBalancer.BlueGreen
+	if blueGreen.ActiveGroup != "blue" && blueGreen.ActiveGroup != "green" {
+		v.addf("loadBalancer.blueGreen.activeGroup", "blue-green active group must be blue or green, got %q", blueGreen.ActiveGroup)
+	}
+	if blueGreen.ValidationWindow < 0 {
+		v.addf("loadBalancer.blueGreen.validationWindow", "blue-green validation window must not be negative, got %s", blueGreen.ValidationWindow)
+	}
+	if blueGreen.MaxErrorRate < 0 || blueGreen.MaxErrorRate > 1 {
+		v.addf("loadBalancer.blueGreen.maxErrorRate", "blue-green max error rate must be between 0 and 1, got %g", blueGreen.MaxErrorRate)
+	}
+	if blueGreen.MinRequests < 0 {
+		v.addf("loadBalancer.blueGreen.minRequests", "blue-green min requests must not be negative, got %d", blueGreen.MinRequests)
+	}
+
+	queue := config.LoadBalancer.Queue
+	if queue.MaxDepth < 0 {
+		v.addf("loadBalancer.queue.maxDepth", "queue max depth must not be negative, got %d", queue.MaxDepth)
+	}
+	if queue.MaxDepth > 0 && queue.Timeout <= 0 {
+		v.addf("loadBalancer.queue.timeout", "queue timeout must be positive, got %s", queue.Timeout)
+	}
+
+	if onExceeded := config.LoadBalancer.BackendRateLimit.OnExceeded; onExceeded != "queue" && onExceeded != "reject" {
+		v.addf("loadBalancer.backendRateLimit.onExceeded", "backend rate limit action must be queue or reject, got %q", onExceeded)
+	}
+
+	validateHealthCheck(v, "healthCheck", config.HealthCheck)
+
+	if len(config.Backends) == 0 {
+		v.addf("backends", "no backends configured")
+	}
+
+	enabledBackends := 0
+	backendsValid := true
+	for i, backend := range config.Backends {
+		path := fmt.Sprintf("backends[%d]", i)
+		if backend.ID == "" {
+			v.addf(path+".id", "backend #%d has empty ID", i)
+			backendsValid = false
+		} else if err := ValidateBackend(backend); err != nil {
+			v.add(path, err)
+			backendsValid = false
+		} else if hc := backend.HealthCheck; hc.Interval > 0 || hc.Timeout > 0 {
+			if err := ValidateHealthCheckTiming(config.HealthCheck.ForBackend(hc)); err != nil {
+				v.add(path+".healthCheck.timeout", fmt.Errorf("backend %s: %w", backend.ID, err))
+				backendsValid = false
+			}
+		}
+		if backend.Enabled {
+			enabledBackends++
+		}
+	}
+
+	if len(config.Backends) > 0 && enabledBackends == 0 {
+		v.addf("backends", "no enabled backends configured")
+	}
+	checkDuplicateBackends(v, config.Backends)
+	if backendsValid {
+		resolveBackendHosts(v, config.Backends)
+	}
+
+	if config.RateLimit.Enabled {
+		if config.RateLimit.DefaultRate <= 0 {
+			v.addf("rateLimit.defaultRate", "rate limit default rate must be positive, got %f", config.RateLimit.DefaultRate)
+		}
+		if config.RateLimit.DefaultBurst <= 0 {
+			v.addf("rateLimit.defaultBurst", "rate limit default burst must be positive, got %d", config.RateLimit.DefaultBurst)
+		}
+		if !slices.Contains(SupportedRateLimitAlgorithms, config.RateLimit.Algorithm) {
+			v.addf("rateLimit.algorithm", "unsupported rate limit algorithm: %s. Supported algorithms: %v",
+				config.RateLimit.Algorithm, SupportedRateLimitAlgorithms)
+		}
+		if config.RateLimit.Window <= 0 {
+			v.addf("rateLimit.window", "rate limit window must be positive, got %s", config.RateLimit.Window)
+		}
+	}
+
+	if config.RateLimit.IdleTTL < 0 {
+		v.addf("rateLimit.idleTTL", "rate limit idle TTL must not be negative, got %s", config.RateLimit.IdleTTL)
+	}
+	if config.RateLimit.MaxClients < 0 {
+		v.addf("rateLimit.maxClients", "rate limit max clients must not be negative, got %d", config.RateLimit.MaxClients)
+	}
+
+	for i, cidr := range config.RateLimit.Allowlist.CIDRs {
+		if !isIPOrCIDR(cidr) {
+			v.addf(fmt.Sprintf("rateLimit.allowlist.cidrs[%d]", i), "invalid rate limit allowlist entry %q: must be an IP address or CIDR", cidr)
+		}
+	}
+
+	if quota := config.RateLimit.Quota; quota.Enabled {
+		if quota.Limit <= 0 {
+			v.addf("rateLimit.quota.limit", "rate limit quota must be positive, got %d", quota.Limit)
+		}
+		if quota.Period != "hour" && quota.Period != "day" && quota.Period != "month" {
+			v.addf("rateLimit.quota.period", "rate limit quota period must be hour, day or month, got %q", quota.Period)
+		}
+		if _, err := time.LoadLocation(quota.Timezone); err != nil {
+			v.addf("rateLimit.quota.timezone", "invalid rate limit quota timezone %q: %s", quota.Timezone, err)
+		}
+		if quota.FlushInterval <= 0 {
+			v.addf("rateLimit.quota.flushInterval", "rate limit quota flush interval must be positive, got %s", quota.FlushInterval)
+		}
+	}
+
+	if config.RateLimit.Shaping.Enabled && config.RateLimit.Shaping.MaxWait <= 0 {
+		v.addf("rateLimit.shaping.maxWait", "rate limit shaping max wait must be positive, got %s", config.RateLimit.Shaping.MaxWait)
+	}
+
+	if config.RateLimit.Bandwidth.BytesPerSecond < 0 {
+		v.addf("rateLimit.bandwidth.bytesPerSecond", "rate limit bandwidth must be non-negative, got %d", config.RateLimit.Bandwidth.BytesPerSecond)
+	}
+	if config.RateLimit.Bandwidth.Burst < 0 {
+		v.addf("rateLimit.bandwidth.burst", "rate limit bandwidth burst must be non-negative, got %d", config.RateLimit.Bandwidth.Burst)
+	}
+
+	tierNames := slices.Sorted(maps.Keys(config.RateLimit.Tiers))
+	tierClients := make(map[string]string)
+	for _, name := range tierNames {
+		tier := config.RateLimit.Tiers[name]
+		path := "rateLimit.tiers." + name
+		if tier.Rate <= 0 || tier.Burst <= 0 {
+			v.addf(path, "rate limit tier %s: rate and burst must be positive", name)
+		}
+		if tier.Concurrency < 0 {
+			v.addf(path+".concurrency", "rate limit tier %s: concurrency must be non-negative, got %d", name, tier.Concurrency)
+		}
+		for i, clientID := range tier.Clients {
+			if other, ok := tierClients[clientID]; ok {
+				v.addf(fmt.Sprintf("%s.clients[%d]", path, i), "client %s is assigned to both rate limit tiers %s and %s", clientID, other, name)
+				continue
+			}
+			tierClients[clientID] = name
+		}
+	}
+
+	clientIDs := make(map[string]bool)
+	for i, client := range config.RateLimit.Clients {
+		path := fmt.Sprintf("rateLimit.clients[%d]", i)
+		switch {
+		case client.ID == "":
+			v.addf(path+".id", "rate limit client #%d has empty ID", i)
+		case clientIDs[client.ID]:
+			v.addf(path+".id", "rate limit client %s is configured more than once", client.ID)
+		}
+		clientIDs[client.ID] = true
+
+		if client.Tier != "" {
+			if _, ok := config.RateLimit.Tiers[client.Tier]; !ok {
+				v.addf(path+".tier", "rate limit client %s references unknown tier %q", client.ID, client.Tier)
+			}
+			if client.Rate != 0 || client.Burst != 0 {
+				v.addf(path, "rate limit client %s must set either a tier or rate and burst, not both", client.ID)
+			}
+			if other, ok := tierClients[client.ID]; ok {
+				v.addf(path+".tier", "client %s is assigned to both rate limit tiers %s and %s", client.ID, other, client.Tier)
+			}
+		} else if client.Rate <= 0 || client.Burst <= 0 {
+			v.addf(path, "rate limit client %s: rate and burst must be positive", client.ID)
+		}
+	}
+
+	switch config.RateLimit.Store.Type {
+	case "memory":
+	case "file", "bolt":
+		if config.RateLimit.Store.Path == "" {
+			v.addf("rateLimit.store.path", "rate limit %s store requires a path", config.RateLimit.Store.Type)
+		}
+	default:
+		v.addf("rateLimit.store.type", "unsupported rate limit store type: %s", config.RateLimit.Store.Type)
+	}
+
+	if ban := config.RateLimit.Ban; ban.Enabled {
+		if ban.Threshold <= 0 {
+			v.addf("rateLimit.ban.threshold", "rate limit ban threshold must be positive, got %d", ban.Threshold)
+		}
+		if ban.Window <= 0 {
+			v.addf("rateLimit.ban.window", "rate limit ban window must be positive, got %s", ban.Window)
+		}
+		if ban.Duration <= 0 {
+			v.addf("rateLimit.ban.duration", "rate limit ban duration must be positive, got %s", ban.Duration)
+		}
+		if ban.MaxDuration < ban.Duration {
+			v.addf("rateLimit.ban.maxDuration", "rate limit ban max duration must be at least the ban duration, got %s", ban.MaxDuration)
+		}
+		if ban.Multiplier < 1 {
+			v.addf("rateLimit.ban.multiplier", "rate limit ban multiplier must be at least 1, got %g", ban.Multiplier)
+		}
+		if ban.Status != http.StatusTooManyRequests && ban.Status != http.StatusForbidden {
+			v.addf("rateLimit.ban.status", "rate limit ban status must be 429 or 403, got %d", ban.Status)
+		}
+	}
+
+	if config.RateLimit.Concurrency.PerClient < 0 {
+		v.addf("rateLimit.concurrency.perClient", "per-client concurrency limit must not be negative, got %d", config.RateLimit.Concurrency.PerClient)
+	}
+	if config.RateLimit.Concurrency.Global < 0 {
+		v.addf("rateLimit.concurrency.global", "global concurrency limit must not be negative, got %d", config.RateLimit.Concurrency.Global)
+	}
+
+	if config.Cache.Enabled {
+		if config.Cache.DefaultTTL < 0 {
+			v.addf("cache.defaultTTL", "cache default TTL must not be negative, got %s", config.Cache.DefaultTTL)
+		}
+		if config.Cache.MaxEntrySize <= 0 {
+			v.addf("cache.maxEntrySize", "cache max entry size must be positive, got %d", config.Cache.MaxEntrySize)
+		} else if config.Cache.MaxMemory < config.Cache.MaxEntrySize {
+			v.addf("cache.maxMemory", "cache max memory (%d) must not be less than max entry size (%d)",
+				config.Cache.MaxMemory, config.Cache.MaxEntrySize)
+		}
+	}
+
+	pools := make(map[string]bool)
+	for _, backend := range config.Backends {
+		pools[backend.Pool] = true
+	}
+	for i, route := range config.Routes {
+		path := fmt.Sprintf("routes[%d]", i)
+		if err := ValidateRoute(route); err != nil {
+			v.add(path, err)
+		}
+		if err := config.RateLimit.ValidateCost(route.Cost); err != nil {
+			v.add(path+".cost", err)
+		}
+		if route.Static.Root == "" && !pools[route.Pool] {
+			v.addf(path+".pool", "route references unknown backend pool %q", route.Pool)
+		}
+		for j, other := range config.Routes[:i] {
+			if RoutesOverlap(other, route) {
+				v.addf(path, "route overlaps route #%d: both can match requests to host %q, path %q with the same priority (overlaps between different path regexes are not detected)", j, route.Host, routeMatchPath(route))
+				break
+			}
+		}
+	}
+
+	for i, rule := range config.Rewrite.Rules {
+		path := fmt.Sprintf("rewrite.rules[%d]", i)
+		if rule.Target != "path" && rule.Target != "query" {
+			v.addf(path+".target", "rewrite rule %s target must be path or query, got %q", rule.Name, rule.Target)
+		}
+		if (rule.Prefix == "") == (rule.Regex == "") {
+			v.addf(path, "rewrite rule %s must define exactly one of prefix or regex", rule.Name)
+		}
+		if rule.Regex != "" {
+			if _, err := regexp.Compile(rule.Regex); err != nil {
+				v.addf(path+".regex", "rewrite rule %s has invalid regex %q: %s", rule.Name, rule.Regex, err)
+			}
+		}
+	}
+
+	if config.Maintenance.RetryAfter < 0 {
+		v.addf("maintenance.retryAfter", "maintenance retry after must not be negative, got %s", config.Maintenance.RetryAfter)
+	}
+	for i, path := range config.Maintenance.Paths {
+		if !strings.HasPrefix(path, "/") {
+			v.addf(fmt.Sprintf("maintenance.paths[%d]", i), "maintenance path must start with '/', got %q", path)
+		}
+	}
+
+	if config.Admin.Address != "" {
+		if _, port, err := net.SplitHostPort(config.Admin.Address); err != nil {
+			v.addf("admin.address", "invalid admin address %q: %s", config.Admin.Address, err)
+		} else if portNum, err := strconv.Atoi(port); err != nil || portNum < 1 || portNum > 65535 {
+			v.addf("admin.address", "invalid admin port %q", port)
+		} else if portNum == config.Server.Port {
+			v.addf("admin.address", "admin port %d must differ from the server port", portNum)
+		}
+	}
+
+	if auth := config.Admin.Auth; auth.Enabled {
+		if auth.HMACSecret == "" && auth.JWKSURL == "" {
+			v.addf("admin.auth", "admin auth requires an HMAC secret or a JWKS URL")
+		}
+		if auth.JWKSURL != "" {
+			if u, err := url.Parse(auth.JWKSURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				v.addf("admin.auth.jwksURL", "invalid admin auth JWKS URL %q", auth.JWKSURL)
+			}
+			if auth.JWKSRefreshInterval <= 0 {
+				v.addf("admin.auth.jwksRefreshInterval", "admin auth JWKS refresh interval must be positive, got %s", auth.JWKSRefreshInterval)
+			}
+		}
+		if auth.RolesClaim == "" {
+			v.addf("admin.auth.rolesClaim", "admin auth roles claim must not be empty")
+		}
+		if auth.Leeway < 0 {
+			v.addf("admin.auth.leeway", "admin auth leeway must not be negative, got %s", auth.Leeway)
+		}
+	}
+	if config.Observability.Pprof.Enabled && !config.Admin.Auth.Enabled && config.Admin.Address == "" {
+		v.addf("observability.pprof.enabled", "pprof requires admin auth or a separate admin.address")
+	}
+	if config.Admin.Audit.MaxEntries <= 0 {
+		v.addf("admin.audit.maxEntries", "admin audit max entries must be positive, got %d", config.Admin.Audit.MaxEntries)
+	}
+	if config.Admin.History.MaxEntries <= 0 {
+		v.addf("admin.history.maxEntries", "admin config history max entries must be positive, got %d", config.Admin.History.MaxEntries)
+	}
+
+	if config.Logging.Level != "" && !slices.Contains(SupportedLogLevels, strings.ToLower(config.Logging.Level)) {
+		v.addf("logging.level", "unsupported log level %q. Supported levels: %v", config.Logging.Level, SupportedLogLevels)
+	}
+
+	if sampling := config.Logging.Sampling; sampling.Enabled {
+		if sampling.Rate < 1 {
+			v.addf("logging.sampling.rate", "log sampling rate must be at least 1, got %d", sampling.Rate)
+		}
+		if sampling.SlowThreshold < 0 {
+			v.addf("logging.sampling.slowThreshold", "log sampling slow threshold must not be negative, got %s", sampling.SlowThreshold)
+		}
+	}
+	if async := config.Logging.Async; async.Enabled {
+		if async.BufferSize <= 0 {
+			v.addf("logging.async.bufferSize", "async log buffer size must be positive, got %d", async.BufferSize)
+		}
+		if async.FlushInterval <= 0 {
+			v.addf("logging.async.flushInterval", "async log flush interval must be positive, got %s", async.FlushInterval)
+		}
+	}
+
+	if access := config.Logging.Access; access.Enabled {
+		if !slices.Contains(SupportedAccessLogFormats, access.Format) {
+			v.addf("logging.access.format", "unsupported access log format %q. Supported formats: %v", access.Format, SupportedAccessLogFormats)
+		}
+		for i, field := range access.Fields {
+			if !slices.Contains(AccessLogFields, field) {
+				v.addf(fmt.Sprintf("logging.access.fields[%d]", i), "unknown access log field %q. Supported fields: %v", field, AccessLogFields)
+			}
+		}
+		switch access.Output {
+		case "stdout", "stderr":
+		case "file":
+			if access.File.Path == "" {
+				v.addf("logging.access.file.path", "access log file output requires a path")
+			}
+			validateLogFile(v, "logging.access.file", "access log", access.File)
+		default:
+			v.addf("logging.access.output", "access log output must be stdout, stderr or file, got %q", access.Output)
+		}
+	}
+	if config.Logging.File.Path != "" {
+		validateLogFile(v, "logging.file", "log", config.Logging.File)
+	}
+	for i, sink := range config.Logging.Sinks {
+		validateLogSink(v, fmt.Sprintf("logging.sinks[%d]", i), sink)
+	}
+
+	if config.Observability.Top.Capacity <= 0 {
+		v.addf("observability.top.capacity", "top-N capacity must be positive, got %d", config.Observability.Top.Capacity)
+	}
+	if config.Observability.Top.Window <= 0 {
+		v.addf("observability.top.window", "top-N window must be positive, got %s", config.Observability.Top.Window)
+	}
+
+	if config.Observability.Capture.Capacity <= 0 {
+		v.addf("observability.capture.capacity", "capture capacity must be positive, got %d", config.Observability.Capture.Capacity)
+	}
+	if config.Observability.Capture.MaxBodySize < 0 {
+		v.addf("observability.capture.maxBodySize", "capture max body size must not be negative, got %d", config.Observability.Capture.MaxBodySize)
+	}
+	if config.Observability.Capture.MaxTTL <= 0 {
+		v.addf("observability.capture.maxTTL", "capture max TTL must be positive, got %s", config.Observability.Capture.MaxTTL)
+	}
+
+	validateMetrics(v, config.Observability.Metrics)
+	validateAlerts(v, config.Alerts)
+
+	for _, status := range slices.Sorted(maps.Keys(config.ErrorPages)) {
+		page := config.ErrorPages[status]
+		path := fmt.Sprintf("errorPages.%d", status)
+		if status < 400 || status > 599 {
+			v.addf(path, "error page status must be between 400 and 599, got %d", status)
+		}
+		if (page.Template == "") == (page.File == "") {
+			v.addf(path, "error page %d must define exactly one of template or file", status)
+		}
+	}
+
+	v.add("headers", validateHeaderRules("headers", config.Headers))
+
+	return v.err()
+}
+
+func validateLogFile(v *validator, path, scope string, file LogFileConfig) {
+	if file.MaxSize <= 0 {
+		v.addf(path+".maxSize", "%s file max size must be positive, got %d", scope, file.MaxSize)
+	}
+	if file.MaxAge < 0 {
+		v.addf(path+".maxAge", "%s file max age must not be negative, got %s", scope, file.MaxAge)
+	}
+	if file.MaxBackups < 0 {
+		v.addf(path+".maxBackups", "%s file max backups must not be negative, got %d", scope, file.MaxBackups)
+	}
+}
+
+func validateLogSink(v *validator, path string, sink LogSinkConfig) {
+	if !slices.Contains(SupportedLogSinks, sink.Type) {
+		v.addf(path+".type", "unsupported type %q. Supported types: %v", sink.Type, SupportedLogSinks)
+	}
+	if sink.Level != "" && !slices.Contains(SupportedLogLevels, strings.ToLower(sink.Level)) {
+		v.addf(path+".level", "unsupported level %q. Supported levels: %v", sink.Level, SupportedLogLevels)
+	}
+
+	switch sink.Type {
+	case "file":
+		if sink.File.Path == "" {
+			v.addf(path+".file.path", "file sink requires a path")
+		}
+		validateLogFile(v, path+".file", "sink", sink.File)
+	case "syslog":
+		switch sink.Syslog.Network {
+		case "":
+		case "udp", "tcp", "unix", "unixgram":
+			if sink.Syslog.Address == "" {
+				v.addf(path+".syslog.address", "syslog sink requires an address for network %s", sink.Syslog.Network)
+			}
+		default:
+			v.addf(path+".syslog.network", "syslog network must be udp, tcp, unix or unixgram, got %q", sink.Syslog.Network)
+		}
+		if !slices.Contains(SyslogFacilities, sink.Syslog.Facility) {
+			v.addf(path+".syslog.facility", "unsupported syslog facility %q", sink.Syslog.Facility)
+		}
+	}
+}
+
+func validateMetrics(v *validator, metrics MetricsConfig) {
+	const path = "observability.metrics"
+	switch metrics.Exporter {
+	case "none":
+		return
+	case "otlp":
+		if u, err := url.Parse(metrics.OTLP.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.addf(path+".otlp.endpoint", "invalid OTLP metrics endpoint %q", metrics.OTLP.Endpoint)
+		}
+		if metrics.OTLP.Timeout <= 0 {
+			v.addf(path+".otlp.timeout", "OTLP metrics timeout must be positive, got %s", metrics.OTLP.Timeout)
+		}
+	case "statsd":
+		if _, _, err := net.SplitHostPort(metrics.StatsD.Address); err != nil {
+			v.addf(path+".statsd.address", "invalid StatsD address %q: %s", metrics.StatsD.Address, err)
+		}
+		if metrics.StatsD.Flavor != "statsd" && metrics.StatsD.Flavor != "datadog" {
+			v.addf(path+".statsd.flavor", "StatsD flavor must be statsd or datadog, got %q", metrics.StatsD.Flavor)
+		}
+	default:
+		v.addf(path+".exporter", "unsupported metrics exporter %q: must be none, otlp or statsd", metrics.Exporter)
+		return
+	}
+
+	if metrics.Interval <= 0 {
+		v.addf(path+".interval", "metrics export interval must be positive, got %s", metrics.Interval)
+	}
+}
+
+func validateAlerts(v *validator, alerts AlertsConfig) {
+	if !alerts.Enabled {
+		return
+	}
+	if alerts.Interval <= 0 {
+		v.addf("alerts.interval", "alert evaluation interval must be positive, got %s", alerts.Interval)
+	}
+	if alerts.Cooldown < 0 {
+		v.addf("alerts.cooldown", "alert cooldown must not be negative, got %s", alerts.Cooldown)
+	}
+	if len(alerts.Webhooks) == 0 && alerts.SMTP.Address == "" {
+		v.addf("alerts", "alerts are enabled but no webhook or SMTP notifier is configured")
+	}
+	for i, webhook := range alerts.Webhooks {
+		path := fmt.Sprintf("alerts.webhooks[%d]", i)
+		if u, err := url.Parse(webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.addf(path+".url", "invalid alert webhook URL %q", webhook.URL)
+		}
+		if webhook.Timeout < 0 {
+			v.addf(path+".timeout", "alert webhook timeout must not be negative, got %s", webhook.Timeout)
+		}
+	}
+	if smtp := alerts.SMTP; smtp.Address != "" {
+		if _, _, err := net.SplitHostPort(smtp.Address); err != nil {
+			v.addf("alerts.smtp.address", "invalid alert SMTP address %q: %s", smtp.Address, err)
+		}
+		if smtp.From == "" || len(smtp.To) == 0 {
+			v.addf("alerts.smtp", "alert SMTP notifier requires from and at least one to address")
+		}
+	}
+
+	rules := alerts.Rules
+	if rules.ErrorRate.Threshold < 0 || rules.ErrorRate.Threshold > 1 {
+		v.addf("alerts.rules.errorRate.threshold", "error rate alert threshold must be between 0 and 1, got %g", rules.ErrorRate.Threshold)
+	}
+	if rules.ErrorRate.Threshold > 0 && rules.ErrorRate.Window <= 0 {
+		v.addf("alerts.rules.errorRate.window", "error rate alert window must be positive, got %s", rules.ErrorRate.Window)
+	}
+	if rules.RateLimitSpike.Threshold < 0 {
+		v.addf("alerts.rules.rateLimitSpike.threshold", "rate limit spike alert threshold must not be negative, got %d", rules.RateLimitSpike.Threshold)
+	}
+	if rules.RateLimitSpike.Threshold > 0 && rules.RateLimitSpike.Window <= 0 {
+		v.addf("alerts.rules.rateLimitSpike.window", "rate limit spike alert window must be positive, got %s", rules.RateLimitSpike.Window)
+	}
+}
+
+type DefaultedField struct {
+	Backend string
+	Name    string
+	Value   time.Duration
+}
+
+func (c *Config) Defaulted() []DefaultedField {
+	return c.defaulted
+}
+
+func ApplyBackendTimeoutDefaults(backend *BackendConfig) []DefaultedField {
+	var defaulted []DefaultedField
+	if backend.ConnectTimeout == 0 {
+		backend.ConnectTimeout = DefaultBackendConnectTimeout
+		defaulted = append(defaulted, DefaultedField{Backend: backend.ID, Name: "connectTimeout", Value: DefaultBackendConnectTimeout})
+	}
+	if backend.ReadTimeout == 0 {
+		backend.ReadTimeout = DefaultBackendReadTimeout
+		defaulted = append(defaulted, DefaultedField{Backend: backend.ID, Name: "readTimeout", Value: DefaultBackendReadTimeout})
+	}
+	return defaulted
+}
+
+func ValidateBackend(backend BackendConfig) error {
+	if backend.ID == "" {
+		return fmt.Errorf("backend has empty ID")
+	}
+	if !isValidHost(backend.Host) {
+		return fmt.Errorf("backend %s has invalid host %q: must be a hostname or IP address", backend.ID, backend.Host)
+	}
+	if backend.Port < 1 || backend.Port > 65535 {
+		return fmt.Errorf("backend %s has invalid port %d: must be between 1 and 65535", backend.ID, backend.Port)
+	}
+	if _, err := url.Parse("http://" + net.JoinHostPort(backend.Host, strconv.Itoa(backend.Port))); err != nil {
+		return fmt.Errorf("backend %s address does not form a valid URL: %w", backend.ID, err)
+	}
+	if backend.HealthCheck.Host != "" && !isValidHost(backend.HealthCheck.Host) {
+		return fmt.Errorf("backend %s has invalid health check host %q: must be a hostname or IP address", backend.ID, backend.HealthCheck.Host)
+	}
+	if err := validateBackendTimeout(backend.ID, "connect timeout", backend.ConnectTimeout); err != nil {
+		return err
+	}
+	if err := validateBackendTimeout(backend.ID, "read timeout", backend.ReadTimeout); err != nil {
+		return err
+	}
+	if !slices.Contains(SupportedBackendProtocols, backend.Protocol) {
+		return fmt.Errorf("backend %s has unsupported protocol: %s. Supported protocols: %v",
+			backend.ID, backend.Protocol, SupportedBackendProtocols)
+	}
+	if backend.ProxyProtocol != "" && backend.ProxyProtocol != "v1" && backend.ProxyProtocol != "v2" {
+		return fmt.Errorf("backend %s has unsupported proxy protocol version: %s. Supported versions: [v1 v2]",
+			backend.ID, backend.ProxyProtocol)
+	}
+	if backend.MaxConnection < 0 {
+		return fmt.Errorf("backend %s max connection must not be negative, got %d", backend.ID, backend.MaxConnection)
+	}
+	if backend.RateLimit.Rate < 0 {
+		return fmt.Errorf("backend %s rate limit must not be negative, got %g", backend.ID, backend.RateLimit.Rate)
+	}
+	if backend.RateLimit.Burst < 0 {
+		return fmt.Errorf("backend %s rate limit burst must not be negative, got %d", backend.ID, backend.RateLimit.Burst)
+	}
+	if backend.HealthCheck.Port < 0 || backend.HealthCheck.Port > 65535 {
+		return fmt.Errorf("backend %s has invalid health check port: %d", backend.ID, backend.HealthCheck.Port)
+	}
+	if err := validateBackendHealthCheck(backend.ID, backend.HealthCheck); err != nil {
+		return err
+	}
+	return validateHeaderRules("backend "+backend.ID+" headers", backend.Headers)
+}
+
+func isValidHost(host string) bool {
+	if _, err := netip.ParseAddr(host); err == nil {
+		return true
+	}
+	host = strings.TrimSuffix(host, ".")
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(host, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range label {
+			if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' && c != '_' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func checkDuplicateBackends(v *validator, backends []BackendConfig) {
+	var idOrder, addressOrder []string
+	ids := make(map[string][]int)
+	addresses := make(map[string][]int)
+	for i, backend := range backends {
+		if _, ok := ids[backend.ID]; !ok {
+			idOrder = append(idOrder, backend.ID)
+		}
+		ids[backend.ID] = append(ids[backend.ID], i)
+
+		host := strings.ToLower(strings.TrimSuffix(backend.Host, "."))
+		if addr, err := netip.ParseAddr(host); err == nil {
+			host = addr.Unmap().String()
+		}
+		address := net.JoinHostPort(host, strconv.Itoa(backend.Port))
+		if _, ok := addresses[address]; !ok {
+			addressOrder = append(addressOrder, address)
+		}
+		addresses[address] = append(addresses[address], i)
+	}
+
+	for _, id := range idOrder {
+		if indexes := ids[id]; len(indexes) > 1 {
+			users := make([]string, len(indexes))
+			for i, index := range indexes {
+				users[i] = "#" + strconv.Itoa(index)
+			}
+			v.addf(fmt.Sprintf("backends[%d].id", indexes[1]), "duplicate backend ID %q is used by backends %s", id, strings.Join(users, ", "))
+		}
+	}
+	for _, address := range addressOrder {
+		if indexes := addresses[address]; len(indexes) > 1 {
+			users := make([]string, len(indexes))
+			for i, index := range indexes {
+				users[i] = backends[index].ID
+			}
+			v.addf(fmt.Sprintf("backends[%d].host", indexes[1]), "duplicate address %s is used by backends %s", address, strings.Join(users, ", "))
+		}
+	}
+}
+
+func resolveBackendHosts(v *validator, backends []BackendConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), hostResolveTimeout)
+	defer cancel()
+
+	resolved := make(map[string]bool)
+	for i, backend := range backends {
+		hosts := [][2]string{{"host", backend.Host}, {"healthCheck.host", backend.HealthCheck.Host}}
+		for _, entry := range hosts {
+			field, host := entry[0], entry[1]
+			if host == "" || resolved[host] {
+				continue
+			}
+			resolved[host] = true
+			if _, err := netip.ParseAddr(host); err != nil {
+				if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+					v.addf(fmt.Sprintf("backends[%d].%s", i, field), "backend %s host %q does not resolve: %s", backend.ID, host, err)
+				}
+			}
+		}
+	}
+}
+
+func validateBackendTimeout(id, name string, timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("backend %s %s must not be negative, got %s", id, name, timeout)
+	}
+	if timeout > 0 && timeout < time.Millisecond {
+		return fmt.Errorf("backend %s %s must be at least 1ms, got %s", id, name, timeout)
+	}
+	return nil
+}
+
+func (c RateLimitConfig) ValidateCost(cost int) error {
+	if !c.Enabled || cost <= 1 || (c.Algorithm == "LeakyBucket" && c.Delay) {
+		return nil
+	}
+
+	capacity := func(rate float64, burst int) int {
+		if c.Algorithm == "FixedWindow" {
+			return int(math.Ceil(rate * c.Window.Seconds()))
+		}
+		return burst
+	}
+	if limit := capacity(c.DefaultRate, c.DefaultBurst); cost > limit {
+		return fmt.Errorf("rate limit cost %d exceeds the default rate limit capacity %d", cost, limit)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Tiers)) {
+		tier := c.Tiers[name]
+		if limit := capacity(tier.Rate, tier.Burst); cost > limit {
+			return fmt.Errorf("rate limit cost %d exceeds the capacity %d of rate limit tier %s", cost, limit, name)
+		}
+	}
+	for i, client := range c.Clients {
+		if client.Tier != "" {
+			continue
+		}
+		if limit := capacity(client.Rate, client.Burst); cost > limit {
+			return fmt.Errorf("rate limit cost %d exceeds the capacity %d of rate limit client #%d", cost, limit, i)
+		}
+	}
+	return nil
+}
+
+func ValidateRoute(route RouteConfig) error {
+	if !strings.HasPrefix(route.Path, "/") {
+		return fmt.Errorf("path must start with '/', got %q", route.Path)
+	}
+	if host := strings.TrimPrefix(route.Host, "*."); strings.ContainsAny(host, "*/: ") {
+		return fmt.Errorf("invalid host %q, expected a hostname or a wildcard like *.example.com", route.Host)
+	}
+	if route.PathRegex != "" {
+		if _, err := regexp.Compile(route.PathRegex); err != nil {
+			return fmt.Errorf("invalid path regex %q: %w", route.PathRegex, err)
+		}
+	} else if route.Rewrite != "" {
+		return fmt.Errorf("rewrite requires a path regex")
+	}
+	if route.Static.Root != "" {
+		if route.Pool != "" {
+			return fmt.Errorf("static route must not reference a backend pool")
+		}
+		if route.Hedge.Enabled {
+			return fmt.Errorf("static route must not enable hedging")
+		}
+	} else if route.Pool == "" {
+		return fmt.Errorf("pool must not be empty")
+	}
+	for _, method := range route.Methods {
+		if method == "" || strings.ContainsAny(method, " \t") {
+			return fmt.Errorf("invalid method %q", method)
+		}
+	}
+	for name := range route.MatchHeaders {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("match header name must not be empty")
+		}
+	}
+	if route.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %s", route.Timeout)
+	}
+	if route.MaxBodySize < 0 {
+		return fmt.Errorf("max body size must not be negative, got %d", route.MaxBodySize)
+	}
+	if route.CacheTTL < 0 {
+		return fmt.Errorf("cache TTL must not be negative, got %s", route.CacheTTL)
+	}
+	if route.Cost < 0 {
+		return fmt.Errorf("rate limit cost must not be negative, got %d", route.Cost)
+	}
+	if route.Hedge.Percentile < 0 || route.Hedge.Percentile > 100 {
+		return fmt.Errorf("hedge percentile must be between 0 and 100, got %g", route.Hedge.Percentile)
+	}
+	if route.Hedge.Delay < 0 {
+		return fmt.Errorf("hedge delay must not be negative, got %s", route.Hedge.Delay)
+	}
+	if route.Retry.Attempts != nil && *route.Retry.Attempts < 0 {
+		return fmt.Errorf("retry attempts must not be negative, got %d", *route.Retry.Attempts)
+	}
+	if err := validateRetryMethods(route.Retry.Methods); err != nil {
+		return err
+	}
+	if route.Retry.PerTryTimeout < 0 {
+		return fmt.Errorf("retry per-try timeout must not be negative, got %s", route.Retry.PerTryTimeout)
+	}
+	if err := validateHeaderRules("headers", route.Headers); err != nil {
+		return err
+	}
+	return validateRouteAccessLog(route.AccessLog)
+}
+
+func RoutesOverlap(a, b RouteConfig) bool {
+	if !strings.EqualFold(a.Host, b.Host) || routeConditions(a) != routeConditions(b) {
+		return false
+	}
+	switch {
+	case a.PathRegex != "" && b.PathRegex != "":
+		if a.PathRegex != b.PathRegex {
+			return false
+		}
+	case a.PathRegex != "":
+		if matched, err := regexp.MatchString(a.PathRegex, b.Path); err != nil || !matched {
+			return false
+		}
+	case b.PathRegex != "":
+		if matched, err := regexp.MatchString(b.PathRegex, a.Path); err != nil || !matched {
+			return false
+		}
+	case a.Path != b.Path:
+		return false
+	}
+	if len(a.Methods) == 0 || len(b.Methods) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(a.Methods, func(method string) bool {
+		return slices.ContainsFunc(b.Methods, func(other string) bool {
+			return strings.EqualFold(method, other)
+		})
+	})
+}
+
+func routeConditions(route RouteConfig) int {
+	n := len(route.MatchHeaders)
+	if len(route.Methods) > 0 {
+		n++
+	}
+	return n
+}
+
+func routeMatchPath(route RouteConfig) string {
+	if route.PathRegex != "" {
+		return route.PathRegex
+	}
+	return route.Path
+}
+
+func findConfigFile() (string, error) {
+	var searched []string
+	for _, searchPath := range configSearchPaths {
+		for _, ext := range configExtensions {
+			path := filepath.Join(searchPath, "config."+ext)
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+			searched = append(searched, path)
+		}
+	}
+	return "", fmt.Errorf("config file not found, searched %s; use --config or %s to set its path", strings.Join(searched, ", "), ConfigEnv)
+}
+
+func validateRouteAccessLog(accessLog RouteAccessLogConfig) error {
+	if accessLog.SampleRate < 0 {
+		return fmt.Errorf("access log sample rate must not be negative, got %d", accessLog.SampleRate)
+	}
+	names := make(map[string]bool)
+	for _, field := range accessLog.Fields {
+		if field.Name == "" || strings.ContainsAny(field.Name, " \t\"=") {
+			return fmt.Errorf("invalid access log field name %q", field.Name)
+		}
+		if slices.Contains(AccessLogFields, field.Name) || names[field.Name] {
+			return fmt.Errorf("duplicate access log field %q", field.Name)
+		}
+		names[field.Name] = true
+		if (field.Header == "") == (field.Query == "") {
+			return fmt.Errorf("access log field %q must set exactly one of header or query", field.Name)
+		}
+	}
+	return nil
+}
+
+func validateRetryMethods(methods []string) error {
+	for _, method := range methods {
+		if method == "" || strings.ContainsAny(method, " \t") {
+			return fmt.Errorf("invalid retry method %q", method)
+		}
+	}
+	return nil
+}
+
+func isIPOrCIDR(value string) bool {
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(value)
+	return err == nil
+}
+
+func validateHeaderRules(scope string, rules HeaderRulesConfig) error {
+	for _, actions := range []HeaderActionsConfig{rules.Request, rules.Response} {
+		for _, name := range actions.Remove {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("%s: header name to remove must not be empty", scope)
+			}
+		}
+		for _, values := range []map[string]string{actions.Add, actions.Set} {
+			for name := range values {
+				if strings.TrimSpace(name) == "" {
+					return fmt.Errorf("%s: header name must not be empty", scope)
+				}
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"reflect"
+)
+
+type Change struct {
+	Path string `json:"path"`
+	Old  any    `json:"old"`
+	New  any    `json:"new"`
+}
+
+func Diff(old, new *Config) []string {
+	var changed []string
+	for _, change := range Changes(old, new) {
+		changed = append(changed, change.Path)
+	}
+	return changed
+}
+
+func Changes(old, new *Config) []Change {
+	var changes []Change
+	diffValue("", reflect.ValueOf(*old), reflect.ValueOf(*new), false, &changes)
+	return changes
+}
+
+func diffValue(path string, old, new reflect.Value, redact bool, changes *[]Change) {
+	if old.Kind() != reflect.Struct {
+		if !reflect.DeepEqual(old.Interface(), new.Interface()) {
+			*changes = append(*changes, Change{
+				Path: path,
+				Old:  changeValue(old, redact),
+				New:  changeValue(new, redact),
+			})
+		}
+		return
+	}
+
+	for i := 0; i < old.NumField(); i++ {
+		field := old.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			name = field.Name
+		}
+		if path != "" {
+			name = path + "." + name
+		}
+		diffValue(name, old.Field(i), new.Field(i), redact || field.Tag.Get("redact") == "true", changes)
+	}
+}
+
+func changeValue(v reflect.Value, redact bool) any {
+	if redact && !v.IsZero() {
+		return redactedValue
+	}
+	return dumpValue(v)
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type StatusRange struct {
+	Min int
+	Max int
+}
+
+func (c HealthCheckConfig) ForBackend(override BackendHealthCheckConfig) HealthCheckConfig {
+	effective := c
+	if override.Type != "" {
+		effective.Type = override.Type
+	}
+	if override.Path != "" {
+		effective.Path = override.Path
+	}
+	if override.Method != "" {
+		effective.Method = override.Method
+	}
+	if override.Interval > 0 {
+		effective.Interval = override.Interval
+	}
+	if override.Timeout > 0 {
+		effective.Timeout = override.Timeout
+	}
+	if override.Rise > 0 {
+		effective.Rise = override.Rise
+	}
+	if override.Fall > 0 {
+		effective.Fall = override.Fall
+	}
+	if len(override.ExpectedStatuses) > 0 {
+		effective.ExpectedStatuses = override.ExpectedStatuses
+	}
+	return effective
+}
+
+func ParseExpectedStatuses(statuses []string) ([]StatusRange, error) {
+	ranges := make([]StatusRange, 0, len(statuses))
+	for _, status := range statuses {
+		status = strings.TrimSpace(status)
+		var r StatusRange
+		switch {
+		case len(status) == 3 && strings.HasSuffix(strings.ToLower(status), "xx"):
+			class, err := strconv.Atoi(status[:1])
+			if err != nil || class < 1 || class > 5 {
+				return nil, fmt.Errorf("invalid expected status class %q", status)
+			}
+			r = StatusRange{Min: class * 100, Max: class*100 + 99}
+		case strings.Contains(status, "-"):
+			from, to, _ := strings.Cut(status, "-")
+			low, err1 := strconv.Atoi(strings.TrimSpace(from))
+			high, err2 := strconv.Atoi(strings.TrimSpace(to))
+			if err1 != nil || err2 != nil || low > high {
+				return nil, fmt.Errorf("invalid expected status range %q", status)
+			}
+			r = StatusRange{Min: low, Max: high}
+		default:
+			code, err := strconv.Atoi(status)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expected status %q", status)
+			}
+			r = StatusRange{Min: code, Max: code}
+		}
+		if r.Min < 100 || r.Max > 599 {
+			return nil, fmt.Errorf("expected status %q is outside 100-599", status)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+func StatusExpected(ranges []StatusRange, code int) bool {
+	for _, r := range ranges {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+func validateHealthCheck(v *validator, path string, hc HealthCheckConfig) {
+	if !slices.Contains(SupportedHealthCheckTypes, hc.Type) {
+		v.addf(path+".type", "unsupported health check type %q. Supported types: %v", hc.Type, SupportedHealthCheckTypes)
+	}
+	if hc.Type == "http" {
+		if !strings.HasPrefix(hc.Path, "/") {
+			v.addf(path+".path", "health check path must start with /, got %q", hc.Path)
+		}
+		if !isValidMethod(hc.Method) {
+			v.addf(path+".method", "invalid health check method %q", hc.Method)
+		}
+		if len(hc.ExpectedStatuses) == 0 {
+			v.addf(path+".expectedStatuses", "at least one expected status is required")
+		} else if _, err := ParseExpectedStatuses(hc.ExpectedStatuses); err != nil {
+			v.add(path+".expectedStatuses", err)
+		}
+	}
+	if hc.Interval <= 0 {
+		v.addf(path+".interval", "health check interval must be positive, got %s", hc.Interval)
+	}
+	if hc.Timeout <= 0 {
+		v.addf(path+".timeout", "health check timeout must be positive, got %s", hc.Timeout)
+	} else if err := ValidateHealthCheckTiming(hc); err != nil {
+		v.add(path+".timeout", err)
+	}
+	if hc.Rise < 1 {
+		v.addf(path+".rise", "health check rise must be at least 1, got %d", hc.Rise)
+	}
+	if hc.Fall < 1 {
+		v.addf(path+".fall", "health check fall must be at least 1, got %d", hc.Fall)
+	}
+}
+
+func ValidateHealthCheckTiming(hc HealthCheckConfig) error {
+	if hc.Timeout > 0 && hc.Interval > 0 && hc.Timeout >= hc.Interval {
+		return fmt.Errorf("health check timeout %s must be less than the interval %s", hc.Timeout, hc.Interval)
+	}
+	return nil
+}
+
+func validateBackendHealthCheck(id string, hc BackendHealthCheckConfig) error {
+	if hc.Type != "" && !slices.Contains(SupportedHealthCheckTypes, hc.Type) {
+		return fmt.Errorf("backend %s has unsupported health check type %q. Supported types: %v", id, hc.Type, SupportedHealthCheckTypes)
+	}
+	if hc.Path != "" && !strings.HasPrefix(hc.Path, "/") {
+		return fmt.Errorf("backend %s health check path must start with /, got %q", id, hc.Path)
+	}
+	if hc.Method != "" && !isValidMethod(hc.Method) {
+		return fmt.Errorf("backend %s has invalid health check method %q", id, hc.Method)
+	}
+	if hc.Interval < 0 {
+		return fmt.Errorf("backend %s health check interval must not be negative, got %s", id, hc.Interval)
+	}
+	if hc.Timeout < 0 {
+		return fmt.Errorf("backend %s health check timeout must not be negative, got %s", id, hc.Timeout)
+	}
+	if hc.Rise < 0 || hc.Fall < 0 {
+		return fmt.Errorf("backend %s health check rise and fall must not be negative", id)
+	}
+	if _, err := ParseExpectedStatuses(hc.ExpectedStatuses); err != nil {
+		return fmt.Errorf("backend %s health check: %w", id, err)
+	}
+	return nil
+}
+
+func isValidMethod(method string) bool {
+	return method != "" && !strings.ContainsAny(method, " \t\r\n")
+}
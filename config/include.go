@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+var appendedListKeys = []string{"backends", "routes", "ratelimit.clients"}
+
+func includeFiles(patterns []string, baseDir string) ([]string, error) {
+	var files []string
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("included config file %s not found", pattern)
+		}
+		slices.Sort(matches)
+		for _, match := range matches {
+			if !slices.Contains(files, match) {
+				files = append(files, match)
+			}
+		}
+	}
+	return files, nil
+}
+
+func mergeIncludes(patterns []string, baseDir string) error {
+	files, err := includeFiles(patterns, baseDir)
+	if err != nil {
+		return err
+	}
+
+	settings := viper.AllSettings()
+	for _, file := range files {
+		format, ok := configFormats[strings.ToLower(strings.TrimPrefix(filepath.Ext(file), "."))]
+		if !ok {
+			return fmt.Errorf("unsupported included config file extension %q, expected one of .yaml, .yml, .json or .toml", filepath.Ext(file))
+		}
+
+		include := viper.New()
+		include.SetConfigFile(file)
+		include.SetConfigType(format)
+		if err := include.ReadInConfig(); err != nil {
+			return fmt.Errorf("error reading included config file %s: %w", file, err)
+		}
+		if include.IsSet("include") {
+			return fmt.Errorf("included config file %s must not include other files", file)
+		}
+
+		mergeSettings(settings, include.AllSettings(), "")
+		fmt.Fprintf(os.Stderr, "Including config file: %s\n", file)
+	}
+	return viper.MergeConfigMap(settings)
+}
+
+func mergeSettings(dst, src map[string]any, prefix string) {
+	for key, value := range src {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		if slices.Contains(appendedListKeys, path) {
+			dst[key] = append(toList(dst[key]), toList(value)...)
+			continue
+		}
+		dstMap, dstOK := dst[key].(map[string]any)
+		srcMap, srcOK := value.(map[string]any)
+		if dstOK && srcOK {
+			mergeSettings(dstMap, srcMap, path)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+func toList(value any) []any {
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		if value == nil {
+			return nil
+		}
+		return []any{value}
+	}
+	list := make([]any, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+	return list
+}
@@ -0,0 +1,268 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ConsulTokenEnv = "CONSUL_HTTP_TOKEN"
+
+	remoteFetchTimeout  = 10 * time.Second
+	remoteWatchWait     = 5 * time.Minute
+	remoteRetryInterval = 5 * time.Second
+)
+
+var remoteClient = &http.Client{}
+
+type remoteSource struct {
+	provider string
+	endpoint string
+	key      string
+	format   string
+}
+
+func parseRemoteSource(location string) (*remoteSource, error) {
+	u, err := url.Parse(location)
+	if err != nil || (u.Scheme != "consul" && u.Scheme != "etcd") {
+		return nil, nil
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return nil, fmt.Errorf("invalid remote config location %q, expected %s://host:port/key", location, u.Scheme)
+	}
+
+	format := "yaml"
+	if ext := strings.ToLower(strings.TrimPrefix(path.Ext(key), ".")); ext != "" {
+		var ok bool
+		if format, ok = configFormats[ext]; !ok {
+			return nil, fmt.Errorf("unsupported remote config key extension %q, expected one of .yaml, .yml, .json or .toml", path.Ext(key))
+		}
+	}
+
+	scheme := "http"
+	if u.Query().Get("tls") == "true" {
+		scheme = "https"
+	}
+	return &remoteSource{provider: u.Scheme, endpoint: scheme + "://" + u.Host, key: key, format: format}, nil
+}
+
+func (s *remoteSource) String() string {
+	return s.provider + " key " + s.key + " at " + s.endpoint
+}
+
+func (s *remoteSource) fetch(ctx context.Context) ([]byte, uint64, error) {
+	if s.provider == "consul" {
+		return s.consulGet(ctx, 0, 0)
+	}
+
+	var resp struct {
+		Kvs []struct {
+			Value       string `json:"value"`
+			ModRevision string `json:"mod_revision"`
+		} `json:"kvs"`
+		Header struct {
+			Revision string `json:"revision"`
+		} `json:"header"`
+	}
+	if err := s.etcdCall(ctx, "/v3/kv/range", map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(s.key))}, &resp); err != nil {
+		return nil, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, 0, fmt.Errorf("remote config key %s not found in etcd at %s", s.key, s.endpoint)
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Kvs[0].Value)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid etcd value for key %s: %w", s.key, err)
+	}
+	revision, _ := strconv.ParseUint(resp.Header.Revision, 10, 64)
+	return data, revision, nil
+}
+
+func (s *remoteSource) consulGet(ctx context.Context, index uint64, wait time.Duration) ([]byte, uint64, error) {
+	query := url.Values{"raw": {""}}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", strconv.Itoa(int(wait.Seconds()))+"s")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.endpoint+"/v1/kv/"+s.key+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if token := os.Getenv(ConsulTokenEnv); token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading consul key %s: %w", s.key, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading consul key %s: %w", s.key, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, 0, fmt.Errorf("remote config key %s not found in consul at %s", s.key, s.endpoint)
+	default:
+		return nil, 0, fmt.Errorf("error reading consul key %s: unexpected status %d: %s", s.key, resp.StatusCode, bytes.TrimSpace(data))
+	}
+	next, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	return data, next, nil
+}
+
+func (s *remoteSource) etcdCall(ctx context.Context, endpoint string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error reading etcd key %s: %w", s.key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("error reading etcd key %s: unexpected status %d: %s", s.key, resp.StatusCode, bytes.TrimSpace(data))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error reading etcd key %s: %w", s.key, err)
+	}
+	return nil
+}
+
+func (s *remoteSource) wait(ctx context.Context, index uint64) (uint64, error) {
+	if s.provider == "consul" {
+		_, next, err := s.consulGet(ctx, index, remoteWatchWait)
+		if err != nil {
+			return index, err
+		}
+		if next < index {
+			return 0, nil
+		}
+		return next, nil
+	}
+
+	key := base64.StdEncoding.EncodeToString([]byte(s.key))
+	payload, err := json.Marshal(map[string]any{"create_request": map[string]any{"key": key, "start_revision": strconv.FormatUint(index+1, 10)}})
+	if err != nil {
+		return index, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/v3/watch", bytes.NewReader(payload))
+	if err != nil {
+		return index, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return index, fmt.Errorf("error watching etcd key %s: %w", s.key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return index, fmt.Errorf("error watching etcd key %s: unexpected status %d", s.key, resp.StatusCode)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var message struct {
+			Result struct {
+				Header struct {
+					Revision string `json:"revision"`
+				} `json:"header"`
+				Events []json.RawMessage `json:"events"`
+			} `json:"result"`
+		}
+		if err := decoder.Decode(&message); err != nil {
+			return index, fmt.Errorf("error watching etcd key %s: %w", s.key, err)
+		}
+		if len(message.Result.Events) > 0 {
+			revision, _ := strconv.ParseUint(message.Result.Header.Revision, 10, 64)
+			return max(revision, index+1), nil
+		}
+	}
+}
+
+func IsRemoteConfig() bool {
+	source, err := parseRemoteSource(configLocation())
+	return err == nil && source != nil
+}
+
+func WatchRemoteConfig(ctx context.Context, changed func(), failed func(error)) {
+	source, err := parseRemoteSource(configLocation())
+	if err != nil || source == nil {
+		return
+	}
+
+	var current []byte
+	var index uint64
+	for ctx.Err() == nil {
+		if current == nil {
+			fetchCtx, cancel := context.WithTimeout(ctx, remoteFetchTimeout)
+			current, index, err = source.fetch(fetchCtx)
+			cancel()
+			if err != nil {
+				current = nil
+				failed(err)
+				sleepContext(ctx, remoteRetryInterval)
+				continue
+			}
+		}
+
+		next, err := source.wait(ctx, index)
+		if err != nil {
+			if ctx.Err() == nil {
+				failed(err)
+				sleepContext(ctx, remoteRetryInterval)
+			}
+			continue
+		}
+		if next == index {
+			continue
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, remoteFetchTimeout)
+		data, revision, err := source.fetch(fetchCtx)
+		cancel()
+		if err != nil {
+			failed(err)
+			index = next
+			continue
+		}
+		index = max(next, revision)
+		if !bytes.Equal(data, current) {
+			current = data
+			changed()
+		}
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+const secretFilePrefix = "file:"
+
+var envSecretRef = regexp.MustCompile(`\$\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+func resolveSecretRef(value string) (string, error) {
+	if path, ok := strings.CutPrefix(value, secretFilePrefix); ok && strings.HasPrefix(path, "/") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("cannot read secret file %s: %w", path, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	var missing []string
+	resolved := envSecretRef.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envSecretRef.FindStringSubmatch(ref)[1]
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return secret
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s referenced by %q is not set", strings.Join(missing, ", "), value)
+	}
+	return resolved, nil
+}
+
+func secretRefHook(from reflect.Type, _ reflect.Type, data any) (any, error) {
+	value, ok := data.(string)
+	if from.Kind() != reflect.String || !ok {
+		return data, nil
+	}
+	return resolveSecretRef(value)
+}
+
+func decodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		secretRefHook,
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
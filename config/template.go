@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxExpandedBackends = 4096
+
+var hostRangePattern = regexp.MustCompile(`\[(\d+)\.\.(\d+)\]`)
+
+func isBackendTemplate(host string) bool {
+	return strings.Contains(host, "/") || hostRangePattern.MatchString(host)
+}
+
+func expandBackends(backends []BackendConfig) ([]BackendConfig, []int, error) {
+	expanded := make([]BackendConfig, 0, len(backends))
+	origins := make([]int, 0, len(backends))
+	for i, backend := range backends {
+		if !isBackendTemplate(backend.Host) {
+			expanded = append(expanded, backend)
+			origins = append(origins, i)
+			continue
+		}
+
+		hosts, err := expandHost(backend.Host)
+		if err != nil {
+			return nil, nil, ValidationError{Path: fmt.Sprintf("backends[%d].host", i), Message: err.Error()}
+		}
+		for _, host := range hosts {
+			generated := backend
+			generated.Host = host.name
+			generated.ID = host.name
+			if backend.ID != "" {
+				generated.ID = backend.ID + "-" + host.suffix
+			}
+			expanded = append(expanded, generated)
+			origins = append(origins, i)
+		}
+		if len(expanded) > maxExpandedBackends {
+			return nil, nil, ValidationError{Path: fmt.Sprintf("backends[%d].host", i), Message: fmt.Sprintf("backend templates expand to more than %d backends", maxExpandedBackends)}
+		}
+	}
+	return expanded, origins, nil
+}
+
+type expandedHost struct {
+	name   string
+	suffix string
+}
+
+func expandHost(pattern string) ([]expandedHost, error) {
+	if strings.Contains(pattern, "/") {
+		return expandCIDR(pattern)
+	}
+
+	hosts := []expandedHost{{name: pattern}}
+	for {
+		match := hostRangePattern.FindStringSubmatchIndex(hosts[0].name)
+		if match == nil {
+			return hosts, nil
+		}
+		start, end := hosts[0].name[match[2]:match[3]], hosts[0].name[match[4]:match[5]]
+		values, err := expandRange(start, end)
+		if err != nil {
+			return nil, fmt.Errorf("invalid host range in %q: %w", pattern, err)
+		}
+		if len(hosts)*len(values) > maxExpandedBackends {
+			return nil, fmt.Errorf("host pattern %q expands to more than %d backends", pattern, maxExpandedBackends)
+		}
+
+		next := make([]expandedHost, 0, len(hosts)*len(values))
+		for _, host := range hosts {
+			for _, value := range values {
+				suffix := value
+				if host.suffix != "" {
+					suffix = host.suffix + "-" + value
+				}
+				next = append(next, expandedHost{name: host.name[:match[0]] + value + host.name[match[1]:], suffix: suffix})
+			}
+		}
+		hosts = next
+	}
+}
+
+func expandRange(start, end string) ([]string, error) {
+	from, err := strconv.Atoi(start)
+	if err != nil {
+		return nil, err
+	}
+	to, err := strconv.Atoi(end)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, fmt.Errorf("range start %s is greater than end %s", start, end)
+	}
+	if to-from >= maxExpandedBackends {
+		return nil, fmt.Errorf("range [%s..%s] is larger than %d", start, end, maxExpandedBackends)
+	}
+
+	width := 0
+	if len(start) > 1 && start[0] == '0' {
+		width = len(start)
+	}
+	values := make([]string, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		values = append(values, fmt.Sprintf("%0*d", width, n))
+	}
+	return values, nil
+}
+
+func expandCIDR(cidr string) ([]expandedHost, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host CIDR %q: %w", cidr, err)
+	}
+	prefix = prefix.Masked()
+
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits > 12 {
+		return nil, fmt.Errorf("host CIDR %q expands to more than %d backends", cidr, maxExpandedBackends)
+	}
+	skipEdges := prefix.Addr().Is4() && hostBits > 1
+
+	var hosts []expandedHost
+	addr := prefix.Addr()
+	for i := 0; i < 1<<hostBits; i++ {
+		last := i == 1<<hostBits-1
+		if !skipEdges || (i != 0 && !last) {
+			hosts = append(hosts, expandedHost{name: addr.String(), suffix: strings.NewReplacer(".", "-", ":", "-").Replace(addr.String())})
+		}
+		addr = addr.Next()
+	}
+	return hosts, nil
+}
+
+func remapBackendLines(lines map[string]int, origins []int) map[string]int {
+	if lines == nil {
+		return nil
+	}
+	remapped := make(map[string]int, len(lines))
+	for key, line := range lines {
+		if !strings.HasPrefix(key, "backends[") {
+			remapped[key] = line
+		}
+	}
+	for i, origin := range origins {
+		from := fmt.Sprintf("backends[%d]", origin)
+		to := fmt.Sprintf("backends[%d]", i)
+		for key, line := range lines {
+			if rest, ok := strings.CutPrefix(key, from); ok && (rest == "" || rest[0] == '.') {
+				remapped[to+rest] = line
+			}
+		}
+	}
+	return remapped
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var SupportedClientAuthModes = []string{
+	"none",
+	"optional",
+	"require",
+}
+
+func ParseTLSVersion(version string) (uint16, error) {
+	if version == "" {
+		return tls.VersionTLS12, nil
+	}
+	if v, ok := TLSVersions[version]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q. Supported versions: [1.0 1.1 1.2 1.3]", version)
+}
+
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := cipherSuiteID(name)
+		if !ok {
+			return nil, fmt.Errorf("unsupported cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func cipherSuiteID(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
+
+func IsInlinePEM(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN ")
+}
+
+func readPEM(value string) ([]byte, error) {
+	if IsInlinePEM(value) {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+func pemSource(value string) string {
+	if IsInlinePEM(value) {
+		return "inline PEM"
+	}
+	return value
+}
+
+func LoadX509KeyPair(certFile, keyFile string) (tls.Certificate, error) {
+	certPEM, err := readPEM(certFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("cannot read certificate: %w", err)
+	}
+	keyPEM, err := readPEM(keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("cannot read key: %w", err)
+	}
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	data, err := readPEM(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("CA file %s contains no PEM certificates", pemSource(file))
+	}
+	return pool, nil
+}
+
+func validateServerTLS(v *validator, port int, cfg TLSConfig) {
+	if _, err := ParseTLSVersion(cfg.MinVersion); err != nil {
+		v.add("server.tls.minVersion", err)
+	}
+	if _, err := ParseCipherSuites(cfg.CipherSuites); err != nil {
+		v.add("server.tls.cipherSuites", err)
+	}
+	if cfg.ClientAuth != "" && !slices.Contains(SupportedClientAuthModes, cfg.ClientAuth) {
+		v.addf("server.tls.clientAuth", "unsupported client auth mode %q. Supported modes: %v", cfg.ClientAuth, SupportedClientAuthModes)
+	}
+	if cfg.ClientCAFile == "" && (cfg.ClientAuth == "optional" || cfg.ClientAuth == "require") {
+		v.addf("server.tls.clientCAFile", "client CA file is required when client auth is %s", cfg.ClientAuth)
+	}
+	if cfg.RedirectPort < 0 || cfg.RedirectPort > 65535 {
+		v.addf("server.tls.redirectPort", "invalid redirect port %d: must be between 0 and 65535", cfg.RedirectPort)
+	} else if cfg.RedirectPort != 0 && cfg.RedirectPort == port {
+		v.addf("server.tls.redirectPort", "redirect port must differ from the server port %d", port)
+	}
+	if !cfg.Enabled {
+		if cfg.RedirectPort != 0 {
+			v.addf("server.tls.redirectPort", "HTTPS redirect requires TLS to be enabled")
+		}
+		if cfg.ACME.Enabled {
+			v.addf("server.tls.acme.enabled", "ACME requires TLS to be enabled")
+		}
+		return
+	}
+
+	if cfg.ACME.Enabled {
+		validateACME(v, cfg.ACME)
+		if port != 443 && cfg.RedirectPort != 80 {
+			v.addf("server.tls.acme.enabled", "ACME needs server.port 443 for TLS-ALPN-01 or server.tls.redirectPort 80 for HTTP-01 challenges")
+		}
+	}
+	switch {
+	case cfg.CertFile == "" && cfg.KeyFile == "" && cfg.ACME.Enabled:
+	case cfg.CertFile == "" || cfg.KeyFile == "":
+		v.addf("server.tls.certFile", "TLS certificate and key files are required together unless ACME is enabled")
+	default:
+		if _, err := LoadX509KeyPair(cfg.CertFile, cfg.KeyFile); err != nil {
+			v.addf("server.tls.certFile", "cannot load TLS certificate: %v", err)
+		}
+	}
+	if cfg.ClientCAFile != "" {
+		if _, err := LoadCertPool(cfg.ClientCAFile); err != nil {
+			v.add("server.tls.clientCAFile", err)
+		}
+	}
+}
+
+func validateACME(v *validator, cfg ACMEConfig) {
+	if len(cfg.Domains) == 0 {
+		v.addf("server.tls.acme.domains", "at least one ACME domain is required")
+	}
+	for i, domain := range cfg.Domains {
+		if strings.Contains(domain, "*") || !isValidHost(domain) {
+			v.addf(fmt.Sprintf("server.tls.acme.domains[%d]", i), "invalid ACME domain %q: must be a hostname without wildcards", domain)
+		}
+	}
+	if cfg.CacheDir == "" {
+		v.addf("server.tls.acme.cacheDir", "ACME cache directory must not be empty")
+	}
+	if u, err := url.Parse(cfg.DirectoryURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		v.addf("server.tls.acme.directoryURL", "invalid ACME directory URL %q: must be an https URL", cfg.DirectoryURL)
+	}
+	if !cfg.AcceptTOS {
+		v.addf("server.tls.acme.acceptTOS", "the ACME CA terms of service must be accepted to request certificates")
+	}
+}
+
+func validateBackendTLS(v *validator, cfg BackendTLSConfig) {
+	if _, err := ParseTLSVersion(cfg.MinVersion); err != nil {
+		v.add("backendDefaults.tls.minVersion", err)
+	}
+	if cfg.ServerName != "" && !isValidHost(cfg.ServerName) {
+		v.addf("backendDefaults.tls.serverName", "invalid TLS server name %q: must be a hostname or IP address", cfg.ServerName)
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		v.addf("backendDefaults.tls.certFile", "client certificate and key files must be set together")
+	} else if cfg.CertFile != "" {
+		if _, err := LoadX509KeyPair(cfg.CertFile, cfg.KeyFile); err != nil {
+			v.addf("backendDefaults.tls.certFile", "cannot load client certificate: %v", err)
+		}
+	}
+	if cfg.CAFile != "" {
+		if _, err := LoadCertPool(cfg.CAFile); err != nil {
+			v.add("backendDefaults.tls.caFile", err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ValidationError struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s (line %d): %s", e.Path, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	problems := make([]string, len(e))
+	for i, err := range e {
+		problems[i] = "  - " + err.Error()
+	}
+	return fmt.Sprintf("%d config problems:\n%s", len(e), strings.Join(problems, "\n"))
+}
+
+type validator struct {
+	errs  ValidationErrors
+	lines map[string]int
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Line: v.line(path), Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) add(path string, err error) {
+	if err != nil {
+		v.addf(path, "%s", err)
+	}
+}
+
+func (v *validator) line(path string) int {
+	for key := strings.ToLower(path); key != ""; key = parentPath(key) {
+		if line, ok := v.lines[key]; ok {
+			return line
+		}
+	}
+	return 0
+}
+
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func parentPath(path string) string {
+	if i := strings.LastIndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+func yamlLines(data []byte) map[string]int {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+	lines := make(map[string]int)
+	indexYAMLNode(root.Content[0], "", lines)
+	return lines
+}
+
+func indexYAMLNode(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := strings.ToLower(node.Content[i].Value)
+			if path != "" {
+				key = path + "." + key
+			}
+			lines[key] = node.Content[i].Line
+			indexYAMLNode(node.Content[i+1], key, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			key := fmt.Sprintf("%s[%d]", path, i)
+			lines[key] = item.Line
+			indexYAMLNode(item, key, lines)
+		}
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			indexYAMLNode(node.Alias, path, lines)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+	"CloudBalancer/pkg/logger"
+)
+
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+type Entry struct {
+	Time       time.Time
+	RequestID  string
+	ClientIP   string
+	RemoteAddr string
+	Method     string
+	URI        string
+	Path       string
+	Query      string
+	Protocol   string
+	Host       string
+	Status     int
+	Bytes      int64
+	Duration   time.Duration
+	Referer    string
+	UserAgent  string
+	Route      string
+	User       string
+	Extra      []Field
+}
+
+type Logger struct {
+	format string
+	fields []string
+	out    io.Writer
+	mu     sync.Mutex
+}
+
+func NewLogger(cfg config.AccessLogConfig) (*Logger, error) {
+	var out io.Writer
+	switch cfg.Output {
+	case "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	case "file":
+		file, err := logger.NewRotatingFile(cfg.File.Path, cfg.File.MaxSize, cfg.File.MaxAge, cfg.File.MaxBackups, cfg.File.Compress)
+		if err != nil {
+			return nil, err
+		}
+		out = file
+	default:
+		return nil, fmt.Errorf("unknown access log output: %s", cfg.Output)
+	}
+
+	return &Logger{
+		format: cfg.Format,
+		fields: cfg.Fields,
+		out:    out,
+	}, nil
+}
+
+func (l *Logger) Log(e Entry) {
+	var line []byte
+	switch l.format {
+	case "combined":
+		line = l.appendCommon(nil, e)
+		line = append(line, ` "`...)
+		line = appendEscaped(line, e.Referer)
+		line = append(line, `" "`...)
+		line = appendEscaped(line, e.UserAgent)
+		line = append(line, '"')
+		line = appendExtra(line, e.Extra)
+	case "common":
+		line = l.appendCommon(nil, e)
+		line = appendExtra(line, e.Extra)
+	default:
+		line = l.appendJSON(nil, e)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(line)
+}
+
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if closer, ok := l.out.(io.Closer); ok && l.out != os.Stdout && l.out != os.Stderr {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (l *Logger) appendCommon(b []byte, e Entry) []byte {
+	b = append(b, orDash(e.ClientIP)...)
+	b = append(b, " - "...)
+	b = appendEscapedToken(b, e.User)
+	b = append(b, " ["...)
+	b = e.Time.AppendFormat(b, clfTimeFormat)
+	b = append(b, `] "`...)
+	b = appendEscaped(b, e.Method+" "+e.URI+" "+e.Protocol)
+	b = append(b, `" `...)
+	b = strconv.AppendInt(b, int64(e.Status), 10)
+	b = append(b, ' ')
+	if e.Bytes == 0 {
+		b = append(b, '-')
+	} else {
+		b = strconv.AppendInt(b, e.Bytes, 10)
+	}
+	return b
+}
+
+func (l *Logger) appendJSON(b []byte, e Entry) []byte {
+	b = append(b, '{')
+	for i, field := range l.fields {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONValue(b, field)
+		b = append(b, ':')
+
+		var value any
+		switch field {
+		case "time":
+			value = e.Time.Format(time.RFC3339Nano)
+		case "request_id":
+			value = e.RequestID
+		case "client_ip":
+			value = e.ClientIP
+		case "remote_addr":
+			value = e.RemoteAddr
+		case "method":
+			value = e.Method
+		case "uri":
+			value = e.URI
+		case "path":
+			value = e.Path
+		case "query":
+			value = e.Query
+		case "protocol":
+			value = e.Protocol
+		case "host":
+			value = e.Host
+		case "status":
+			value = e.Status
+		case "bytes":
+			value = e.Bytes
+		case "duration_ms":
+			value = float64(e.Duration) / float64(time.Millisecond)
+		case "referer":
+			value = e.Referer
+		case "user_agent":
+			value = e.UserAgent
+		case "route":
+			value = e.Route
+		case "user":
+			value = e.User
+		}
+		b = appendJSONValue(b, value)
+	}
+	for i, field := range e.Extra {
+		if i > 0 || len(l.fields) > 0 {
+			b = append(b, ',')
+		}
+		b = appendJSONValue(b, field.Name)
+		b = append(b, ':')
+		b = appendJSONValue(b, field.Value)
+	}
+	return append(b, '}')
+}
+
+func appendJSONValue(b []byte, value any) []byte {
+	encoded, _ := json.Marshal(value)
+	return append(b, encoded...)
+}
+
+func appendExtra(b []byte, fields []Field) []byte {
+	for _, field := range fields {
+		b = append(b, ' ')
+		b = append(b, field.Name...)
+		b = append(b, `="`...)
+		b = appendEscaped(b, field.Value)
+		b = append(b, '"')
+	}
+	return b
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func appendEscaped(b []byte, value string) []byte {
+	return appendEscapedValue(b, value, false)
+}
+
+func appendEscapedToken(b []byte, value string) []byte {
+	return appendEscapedValue(b, value, true)
+}
+
+func appendEscapedValue(b []byte, value string, escapeSpace bool) []byte {
+	if value == "" {
+		return append(b, '-')
+	}
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < 0x20 || c == 0x7f || c == ' ' && escapeSpace:
+			b = append(b, fmt.Sprintf(`\x%02x`, c)...)
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+package accesslog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"CloudBalancer/config"
+	"CloudBalancer/pkg/logger"
+)
+
+const hashLength = 16
+
+type Field struct {
+	Name  string
+	Value string
+}
+
+type RoutePolicy struct {
+	disabled bool
+	sampler  *logger.Sampler
+	fields   []config.AccessLogFieldConfig
+}
+
+func NewRoutePolicy(cfg config.RouteAccessLogConfig) *RoutePolicy {
+	policy := &RoutePolicy{disabled: cfg.Disabled, fields: cfg.Fields}
+	if cfg.SampleRate > 1 {
+		policy.sampler = logger.NewSampler(cfg.SampleRate, 0)
+	}
+	return policy
+}
+
+func (p *RoutePolicy) Keep(status int) bool {
+	if p == nil {
+		return true
+	}
+	if p.disabled {
+		return false
+	}
+	return p.sampler == nil || p.sampler.Keep(p.sampler.Sample(), status, 0)
+}
+
+func (p *RoutePolicy) Fields(r *http.Request) []Field {
+	if p == nil || len(p.fields) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, len(p.fields))
+	for _, field := range p.fields {
+		var value string
+		if field.Header != "" {
+			value = r.Header.Get(field.Header)
+		} else {
+			value = r.URL.Query().Get(field.Query)
+		}
+		if field.Hash && value != "" {
+			sum := sha256.Sum256([]byte(value))
+			value = hex.EncodeToString(sum[:])[:hashLength]
+		}
+		fields = append(fields, Field{Name: field.Name, Value: value})
+	}
+	return fields
+}
@@ -0,0 +1,299 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/events"
+	"CloudBalancer/internal/load_balancer"
+	"CloudBalancer/internal/rate_limiter"
+
+	"go.uber.org/zap"
+)
+
+const (
+	RuleAllBackendsDown     = "all_backends_down"
+	RuleErrorRate           = "error_rate"
+	RuleRateLimitSpike      = "rate_limit_spike"
+	RuleConfigReloadFailure = "config_reload_failure"
+)
+
+const (
+	StatusFiring   = "firing"
+	StatusResolved = "resolved"
+)
+
+type Alert struct {
+	Key        string            `json:"key"`
+	Rule       string            `json:"rule"`
+	Status     string            `json:"status"`
+	Summary    string            `json:"summary"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Value      float64           `json:"value"`
+	StartedAt  time.Time         `json:"started_at"`
+	ResolvedAt *time.Time        `json:"resolved_at,omitempty"`
+}
+
+type activeAlert struct {
+	Alert
+	notified bool
+}
+
+type rejectionSample struct {
+	at       time.Time
+	rejected int64
+}
+
+type Manager struct {
+	cfg          config.AlertsConfig
+	loadBalancer load_balancer.LoadBalancer
+	rateLimits   *rate_limiter.Metrics
+	bus          *events.Bus
+	notifiers    []Notifier
+	logger       *zap.Logger
+	active       map[string]*activeAlert
+	lastNotified map[string]time.Time
+	rejections   []rejectionSample
+	mu           sync.Mutex
+	stop         chan struct{}
+	done         chan struct{}
+	once         sync.Once
+}
+
+func NewManager(cfg config.AlertsConfig, lb load_balancer.LoadBalancer, rateLimits *rate_limiter.Metrics, bus *events.Bus, logger *zap.Logger) *Manager {
+	m := &Manager{
+		cfg:          cfg,
+		loadBalancer: lb,
+		rateLimits:   rateLimits,
+		bus:          bus,
+		notifiers:    NewNotifiers(cfg),
+		logger:       logger,
+		active:       make(map[string]*activeAlert),
+		lastNotified: make(map[string]time.Time),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+func (m *Manager) Active() []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := make([]Alert, 0, len(m.active))
+	for _, alert := range m.active {
+		alerts = append(alerts, alert.Alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].Key < alerts[j].Key
+	})
+	return alerts
+}
+
+func (m *Manager) Close() {
+	m.once.Do(func() {
+		close(m.stop)
+	})
+	<-m.done
+}
+
+func (m *Manager) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	sub, _ := m.bus.Subscribe(0)
+	defer func() {
+		m.bus.Unsubscribe(sub)
+	}()
+	updates := sub.C
+	var lastID int64
+
+	for {
+		select {
+		case event, ok := <-updates:
+			if !ok {
+				if !m.bus.Dropped(sub) {
+					updates = nil
+					continue
+				}
+				var missed []events.Event
+				sub, missed = m.bus.Subscribe(lastID)
+				updates = sub.C
+				for _, event := range missed {
+					m.handleEvent(event)
+				}
+				continue
+			}
+			lastID = event.ID
+			m.handleEvent(event)
+		case <-ticker.C:
+			m.evaluate()
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+func (m *Manager) handleEvent(event events.Event) {
+	switch event.Type {
+	case events.BackendUp, events.BackendDown:
+		if m.cfg.Rules.AllBackendsDown {
+			m.dispatch(m.evaluateBackends())
+		}
+	case events.ConfigReloadFailed:
+		if m.cfg.Rules.ConfigReloadFailure {
+			summary := "Configuration reload failed"
+			if data, ok := event.Data.(map[string]string); ok && data["error"] != "" {
+				summary += ": " + data["error"]
+			}
+			m.dispatch(m.set(RuleConfigReloadFailure, RuleConfigReloadFailure, true, summary, nil, 1))
+		}
+	case events.ConfigReloaded:
+		if m.cfg.Rules.ConfigReloadFailure {
+			m.dispatch(m.set(RuleConfigReloadFailure, RuleConfigReloadFailure, false, "Configuration reloaded successfully", nil, 0))
+		}
+	}
+}
+
+func (m *Manager) evaluate() {
+	var notifications []Alert
+	if m.cfg.Rules.AllBackendsDown {
+		notifications = append(notifications, m.evaluateBackends()...)
+	}
+	if m.cfg.Rules.ErrorRate.Threshold > 0 {
+		notifications = append(notifications, m.evaluateErrorRate()...)
+	}
+	if m.cfg.Rules.RateLimitSpike.Threshold > 0 && m.rateLimits != nil {
+		notifications = append(notifications, m.evaluateRateLimitSpike()...)
+	}
+	m.dispatch(notifications)
+}
+
+func (m *Manager) evaluateBackends() []Alert {
+	total := make(map[string]int)
+	healthy := make(map[string]int)
+	for _, b := range m.loadBalancer.GetBackends() {
+		total[b.Pool]++
+		if b.IsHealthy() {
+			healthy[b.Pool]++
+		}
+	}
+
+	var notifications []Alert
+	for _, pool := range m.loadBalancer.GetPools() {
+		down := total[pool] > 0 && healthy[pool] == 0
+		summary := fmt.Sprintf("All %d backends in pool %s are down", total[pool], pool)
+		if !down {
+			summary = fmt.Sprintf("%d of %d backends in pool %s are healthy", healthy[pool], total[pool], pool)
+		}
+		notifications = append(notifications, m.set(RuleAllBackendsDown+"/"+pool, RuleAllBackendsDown, down, summary,
+			map[string]string{"pool": pool}, float64(total[pool]-healthy[pool]))...)
+	}
+	return notifications
+}
+
+func (m *Manager) evaluateErrorRate() []Alert {
+	rule := m.cfg.Rules.ErrorRate
+
+	var requests, failed int64
+	for _, b := range m.loadBalancer.GetBackends() {
+		snapshot := b.Stats.Window(rule.Window)
+		requests += snapshot.Requests
+		failed += snapshot.Failed
+	}
+
+	var errorRate float64
+	if requests > 0 {
+		errorRate = float64(failed) / float64(requests)
+	}
+	firing := requests >= rule.MinRequests && errorRate > rule.Threshold
+	summary := fmt.Sprintf("Error rate %.1f%% over the last %s (%d of %d requests failed, threshold %.1f%%)",
+		errorRate*100, rule.Window, failed, requests, rule.Threshold*100)
+	return m.set(RuleErrorRate, RuleErrorRate, firing, summary, nil, errorRate)
+}
+
+func (m *Manager) evaluateRateLimitSpike() []Alert {
+	rule := m.cfg.Rules.RateLimitSpike
+	now := time.Now()
+
+	m.rejections = append(m.rejections, rejectionSample{at: now, rejected: m.rateLimits.Total().Rejected()})
+	for len(m.rejections) > 1 && now.Sub(m.rejections[1].at) >= rule.Window {
+		m.rejections = m.rejections[1:]
+	}
+
+	rejected := m.rejections[len(m.rejections)-1].rejected - m.rejections[0].rejected
+	firing := rejected >= rule.Threshold
+	summary := fmt.Sprintf("%d requests rejected by rate limiting over the last %s (threshold %d)", rejected, rule.Window, rule.Threshold)
+	return m.set(RuleRateLimitSpike, RuleRateLimitSpike, firing, summary, nil, float64(rejected))
+}
+
+func (m *Manager) set(key, rule string, firing bool, summary string, labels map[string]string, value float64) []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	alert, active := m.active[key]
+	switch {
+	case firing && active:
+		alert.Summary = summary
+		alert.Value = value
+		return nil
+	case firing:
+		alert = &activeAlert{Alert: Alert{
+			Key:       key,
+			Rule:      rule,
+			Status:    StatusFiring,
+			Summary:   summary,
+			Labels:    labels,
+			Value:     value,
+			StartedAt: now,
+		}}
+		m.active[key] = alert
+		if last, ok := m.lastNotified[key]; ok && now.Sub(last) < m.cfg.Cooldown {
+			m.logger.Info("Alert suppressed by cooldown", zap.String("alert", key), zap.String("summary", summary))
+			return nil
+		}
+		alert.notified = true
+		m.lastNotified[key] = now
+		return []Alert{alert.Alert}
+	case active:
+		delete(m.active, key)
+		if !alert.notified {
+			return nil
+		}
+		resolved := alert.Alert
+		resolved.Status = StatusResolved
+		resolved.Summary = summary
+		resolved.Value = value
+		resolved.ResolvedAt = &now
+		return []Alert{resolved}
+	}
+	return nil
+}
+
+func (m *Manager) dispatch(alerts []Alert) {
+	for _, alert := range alerts {
+		if alert.Status == StatusFiring {
+			m.logger.Warn("Alert firing", zap.String("alert", alert.Key), zap.String("summary", alert.Summary))
+		} else {
+			m.logger.Info("Alert resolved", zap.String("alert", alert.Key), zap.String("summary", alert.Summary))
+		}
+
+		for _, notifier := range m.notifiers {
+			if err := notifier.Notify(context.Background(), alert); err != nil {
+				m.logger.Warn("Failed to send alert notification",
+					zap.String("notifier", notifier.Name()),
+					zap.String("alert", alert.Key),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
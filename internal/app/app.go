@@ -1,16 +1,41 @@
 package app
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
+	"net/netip"
+	"os"
+	"time"
 
 	"CloudBalancer/config"
+	"CloudBalancer/internal/accesslog"
+	"CloudBalancer/internal/alerting"
+	"CloudBalancer/internal/audit"
+	"CloudBalancer/internal/auth"
+	"CloudBalancer/internal/buildinfo"
+	"CloudBalancer/internal/cache"
+	"CloudBalancer/internal/capture"
+	"CloudBalancer/internal/clientip"
+	"CloudBalancer/internal/errorpage"
+	"CloudBalancer/internal/events"
 	"CloudBalancer/internal/load_balancer"
+	"CloudBalancer/internal/maintenance"
+	"CloudBalancer/internal/metrics"
+	"CloudBalancer/internal/proxyproto"
 	"CloudBalancer/internal/rate_limiter"
+	"CloudBalancer/internal/rewrite"
+	"CloudBalancer/internal/routing"
+	"CloudBalancer/internal/tlsconfig"
+	"CloudBalancer/internal/topk"
 	"CloudBalancer/internal/transport/http/router"
 	"CloudBalancer/pkg/logger"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/crypto/acme/autocert"
 )
 
 type App struct {
@@ -19,37 +44,202 @@ type App struct {
 	router       *router.Router
 	loadBalancer load_balancer.LoadBalancer
 	rateLimiter  rate_limiter.RateLimiter
+	quota        *rate_limiter.Quota
+	limitStore   rate_limiter.OverrideStore
+	audit        *audit.Log
+	events       *events.Bus
+	pusher       *metrics.Pusher
+	accessLog    *accesslog.Logger
+	logFiles     []*logger.RotatingFile
+	alerts       *alerting.Manager
+	serverTLS    *tls.Config
+	acme         *autocert.Manager
+	stopRemote   context.CancelFunc
 }
 
 func NewApp(config *config.Config) (*App, error) {
-	log, err := logger.NewLogger(config.Logging.Environment)
+	sinks, logFiles, err := newLogSinks(config.Logging)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize log sinks: %w", err)
+	}
+
+	var bufferSize int
+	if config.Logging.Async.Enabled {
+		bufferSize = config.Logging.Async.BufferSize
+	}
+
+	log, err := logger.NewLogger(config.Logging.Environment, config.Logging.Level, sinks, bufferSize, config.Logging.Async.FlushInterval)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize logger: %w", err)
 	}
+	for _, field := range config.Defaulted() {
+		log.Logger.Info("Backend timeout not set, using default",
+			zap.String("backend", field.Backend),
+			zap.String("field", field.Name),
+			zap.Duration("value", field.Value),
+		)
+	}
+
+	ipResolver, err := clientip.NewResolver(config.Server.TrustedProxies, config.Server.RealIPHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize client IP resolver: %w", err)
+	}
+
+	errorPages, err := errorpage.NewPages(config.ErrorPages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load error pages: %w", err)
+	}
 
-	lb, err := load_balancer.NewLoadBalancer(config, log.Logger)
+	bus := events.NewBus()
+	registry := metrics.NewRegistry()
+	registry.RegisterRuntime(buildinfo.Get())
+
+	backendTLS, err := tlsconfig.NewClientConfig(config.BackendDefaults.TLS, log.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize backend TLS: %w", err)
+	}
+
+	var serverTLS *tls.Config
+	var acmeManager *autocert.Manager
+	if config.Server.TLS.Enabled {
+		if acme := config.Server.TLS.ACME; acme.Enabled {
+			acmeManager = tlsconfig.NewACMEManager(acme)
+			log.Logger.Info("ACME certificates enabled",
+				zap.Strings("domains", acme.Domains),
+				zap.String("cacheDir", acme.CacheDir),
+				zap.String("directoryURL", acme.DirectoryURL),
+			)
+		}
+		serverTLS, err = tlsconfig.NewServerConfig(config.Server.TLS, acmeManager, log.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize server TLS: %w", err)
+		}
+	}
+
+	lb, err := load_balancer.NewLoadBalancer(config, ipResolver, backendTLS, errorPages, bus, registry, log.Logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize load balancer: %w", err)
 	}
 
 	var rl rate_limiter.RateLimiter
 	if config.RateLimit.Enabled {
-		rl = rate_limiter.NewTokenBucket(
-			config.RateLimit.DefaultRate,
-			config.RateLimit.DefaultBurst,
-			log.Logger,
-		)
+		rl, err = rate_limiter.New(config.RateLimit, log.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize rate limiter: %w", err)
+		}
 		log.Logger.Info("Rate limiter initialized",
+			zap.String("algorithm", config.RateLimit.Algorithm),
 			zap.Float64("defaultRate", config.RateLimit.DefaultRate),
 			zap.Int("defaultBurst", config.RateLimit.DefaultBurst),
 		)
 	} else {
 		log.Logger.Info("Rate limiting is disabled")
-		rl = rate_limiter.NewTokenBucket(1000000, 1000000, log.Logger)
+		rl = rate_limiter.NewTokenBucket(1000000, 1000000, config.RateLimit.IdleTTL, config.RateLimit.MaxClients, log.Logger)
+	}
+
+	store, err := rate_limiter.NewStore(config.RateLimit.Store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize rate limit store: %w", err)
+	}
+	rl, err = rate_limiter.NewPersistent(rl, store, config.RateLimit.Clients, log.Logger)
+	if err != nil {
+		return nil, err
+	}
+
+	allowlist, err := rate_limiter.NewAllowlist(config.RateLimit.Allowlist)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize rate limit allowlist: %w", err)
+	}
+
+	bans := rate_limiter.NewBanList(config.RateLimit.Ban)
+
+	concurrencyLimiter := rate_limiter.NewConcurrencyLimiter(
+		config.RateLimit.Concurrency.PerClient,
+		config.RateLimit.Concurrency.Global,
+		log.Logger,
+	)
+
+	bandwidthLimiter := rate_limiter.NewBandwidthLimiter(
+		config.RateLimit.Bandwidth.BytesPerSecond,
+		config.RateLimit.Bandwidth.Burst,
+		config.RateLimit.IdleTTL,
+		config.RateLimit.MaxClients,
+		log.Logger,
+	)
+
+	tiers, err := rate_limiter.NewTiers(config.RateLimit, rl, concurrencyLimiter, store, log.Logger)
+	if err != nil {
+		return nil, err
+	}
+	top := topk.NewTracker(config.Observability.Top.Capacity, config.Observability.Top.Window)
+	rateLimitMetrics := rate_limiter.NewMetrics(config.RateLimit.IdleTTL, config.RateLimit.MaxClients, tiers.TierOf, registry, top)
+
+	routes, err := routing.NewTable(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize routing table: %w", err)
+	}
+
+	rewrites, err := rewrite.NewEngine(config.Rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize rewrite engine: %w", err)
+	}
+
+	maintenanceMode := maintenance.NewMode(config.Maintenance)
+
+	var responseCache *cache.Cache
+	if config.Cache.Enabled {
+		responseCache = cache.NewCache(config.Cache.MaxEntrySize, config.Cache.MaxMemory, config.Server.IdentificationHeaders, log.Logger)
+	}
+
+	quota, err := rate_limiter.NewQuota(config.RateLimit.Quota, log.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize request quota: %w", err)
 	}
 
-	r := router.NewRouter(log.Logger, lb, rl)
-	r.SetupRoutes()
+	var shapingWait time.Duration
+	if config.RateLimit.Shaping.Enabled {
+		shapingWait = config.RateLimit.Shaping.MaxWait
+	}
+
+	auditLog, err := audit.NewLog(config.Admin.Audit, log.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize admin audit log: %w", err)
+	}
+
+	var accessLog *accesslog.Logger
+	if config.Logging.Access.Enabled {
+		accessLog, err = accesslog.NewLogger(config.Logging.Access)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize access log: %w", err)
+		}
+	}
+
+	r := router.NewRouter(config, log.Logger, lb, rl, shapingWait, quota, concurrencyLimiter, bandwidthLimiter, allowlist, bans, tiers, rateLimitMetrics, ipResolver, routes, rewrites, responseCache, errorPages, maintenanceMode, auth.NewAuthenticator(config.Admin.Auth), auditLog, bus, registry, accessLog, log.Level, top, capture.NewStore(config.Observability.Capture))
+	r.SetupRoutes(config.Admin.Address != "")
+
+	exporter, err := metrics.NewExporter(config.Observability.Metrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize metrics exporter: %w", err)
+	}
+	var pusher *metrics.Pusher
+	if exporter != nil {
+		pusher = metrics.NewPusher(registry, exporter, config.Observability.Metrics.Interval, log.Logger)
+		log.Logger.Info("Metrics exporter enabled",
+			zap.String("exporter", exporter.Name()),
+			zap.Duration("interval", config.Observability.Metrics.Interval),
+		)
+	}
+
+	var alerts *alerting.Manager
+	if config.Alerts.Enabled {
+		alerts = alerting.NewManager(config.Alerts, lb, rateLimitMetrics, bus, log.Logger)
+		log.Logger.Info("Alerting enabled",
+			zap.Duration("interval", config.Alerts.Interval),
+			zap.Duration("cooldown", config.Alerts.Cooldown),
+			zap.Int("webhooks", len(config.Alerts.Webhooks)),
+			zap.Bool("smtp", config.Alerts.SMTP.Address != ""),
+		)
+	}
 
 	return &App{
 		config:       config,
@@ -57,9 +247,178 @@ func NewApp(config *config.Config) (*App, error) {
 		router:       r,
 		loadBalancer: lb,
 		rateLimiter:  rl,
+		quota:        quota,
+		limitStore:   store,
+		audit:        auditLog,
+		events:       bus,
+		pusher:       pusher,
+		accessLog:    accessLog,
+		logFiles:     logFiles,
+		alerts:       alerts,
+		serverTLS:    serverTLS,
+		acme:         acmeManager,
+		stopRemote:   watchRemoteConfig(r, log),
 	}, nil
 }
 
+func watchRemoteConfig(r *router.Router, log *logger.Logger) context.CancelFunc {
+	if !config.IsRemoteConfig() {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go config.WatchRemoteConfig(ctx, r.ReloadRemoteConfig, func(err error) {
+		log.Warn("Remote config watch failed", zap.Error(err))
+	})
+	log.Info("Watching remote config for changes")
+	return cancel
+}
+
+func (a *App) Close() {
+	a.stopRemote()
+	if err := a.quota.Flush(); err != nil {
+		a.logger.Error("Failed to persist quota usage", zap.Error(err))
+	}
+	if err := a.limitStore.Close(); err != nil {
+		a.logger.Error("Failed to close rate limit store", zap.Error(err))
+	}
+	if err := a.audit.Close(); err != nil {
+		a.logger.Error("Failed to close admin audit log", zap.Error(err))
+	}
+	if a.alerts != nil {
+		a.alerts.Close()
+	}
+	if a.pusher != nil {
+		if err := a.pusher.Close(); err != nil {
+			a.logger.Error("Failed to close metrics exporter", zap.Error(err))
+		}
+	}
+	if a.accessLog != nil {
+		if err := a.accessLog.Close(); err != nil {
+			a.logger.Error("Failed to close access log", zap.Error(err))
+		}
+	}
+	a.logger.Close()
+	for _, file := range a.logFiles {
+		file.Close()
+	}
+}
+
+func newLogSinks(cfg config.LoggingConfig) ([]logger.Sink, []*logger.RotatingFile, error) {
+	sinkConfigs := cfg.Sinks
+	if len(sinkConfigs) == 0 {
+		if cfg.File.Path == "" {
+			return nil, nil, nil
+		}
+		sinkConfigs = []config.LogSinkConfig{{Type: "file", File: cfg.File}}
+	}
+
+	var files []*logger.RotatingFile
+	closeFiles := func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}
+
+	sinks := make([]logger.Sink, 0, len(sinkConfigs))
+	for _, sinkConfig := range sinkConfigs {
+		level := zapcore.DebugLevel
+		if sinkConfig.Level != "" {
+			if err := level.UnmarshalText([]byte(sinkConfig.Level)); err != nil {
+				closeFiles()
+				return nil, nil, err
+			}
+		}
+
+		sink := logger.Sink{Level: level}
+		switch sinkConfig.Type {
+		case "stdout":
+			sink.Output, sink.Terminal = logger.StreamOutput(zapcore.Lock(os.Stdout)), true
+		case "stderr":
+			sink.Output, sink.Terminal = logger.StreamOutput(zapcore.Lock(os.Stderr)), true
+		case "file":
+			file := sinkConfig.File
+			rotating, err := logger.NewRotatingFile(file.Path, file.MaxSize, file.MaxAge, file.MaxBackups, file.Compress)
+			if err != nil {
+				closeFiles()
+				return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+			}
+			files = append(files, rotating)
+			sink.Output = logger.StreamOutput(rotating)
+		case "syslog":
+			syslog := sinkConfig.Syslog
+			output, err := logger.NewSyslogOutput(syslog.Network, syslog.Address, syslog.Facility, sinkConfig.Tag)
+			if err != nil {
+				closeFiles()
+				return nil, nil, err
+			}
+			sink.Output = output
+		case "journald":
+			output, err := logger.NewJournaldOutput(sinkConfig.Tag)
+			if err != nil {
+				closeFiles()
+				return nil, nil, err
+			}
+			sink.Output = output
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, files, nil
+}
+
+func (a *App) BeginShutdown() {
+	a.router.BeginShutdown()
+}
+
+func (a *App) CloseEvents() {
+	a.events.Close()
+}
+
 func (a *App) Router() http.Handler {
 	return a.router
 }
+
+func (a *App) AdminRouter() http.Handler {
+	return a.router.AdminHandler()
+}
+
+func (a *App) TLSConfig() *tls.Config {
+	return a.serverTLS
+}
+
+func (a *App) RedirectHandler() http.Handler {
+	redirect := router.HTTPSRedirect(a.config.Server.Port)
+	if a.acme != nil {
+		return a.acme.HTTPHandler(redirect)
+	}
+	return redirect
+}
+
+func (a *App) Listen(addr string, proxyProtocol config.ProxyProtocolConfig) (net.Listener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if !proxyProtocol.Enabled {
+		return listener, nil
+	}
+
+	allowedSources := make([]netip.Prefix, 0, len(proxyProtocol.AllowedSources))
+	for _, source := range proxyProtocol.AllowedSources {
+		prefix, err := clientip.ParsePrefix(source)
+		if err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("invalid proxy protocol allowed source: %w", err)
+		}
+		allowedSources = append(allowedSources, prefix)
+	}
+
+	a.logger.Info("PROXY protocol enabled on listener",
+		zap.String("addr", addr),
+		zap.Bool("required", proxyProtocol.Required),
+		zap.Strings("allowedSources", proxyProtocol.AllowedSources),
+	)
+
+	return proxyproto.NewListener(listener, proxyProtocol.Required, proxyProtocol.HeaderTimeout, allowedSources, a.logger.Logger), nil
+}
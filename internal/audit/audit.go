@@ -0,0 +1,135 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+
+	"go.uber.org/zap"
+)
+
+type Entry struct {
+	ID         int64           `json:"id"`
+	Time       time.Time       `json:"time"`
+	Actor      string          `json:"actor"`
+	Roles      []string        `json:"roles,omitempty"`
+	RemoteAddr string          `json:"remote_addr"`
+	RequestID  string          `json:"request_id,omitempty"`
+	Method     string          `json:"method"`
+	Path       string          `json:"path"`
+	Query      string          `json:"query,omitempty"`
+	Status     int             `json:"status"`
+	Request    json.RawMessage `json:"request,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+}
+
+type Filter struct {
+	Actor      string
+	Method     string
+	PathPrefix string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+}
+
+func (f Filter) matches(e Entry) bool {
+	switch {
+	case f.Actor != "" && e.Actor != f.Actor:
+		return false
+	case f.Method != "" && !strings.EqualFold(e.Method, f.Method):
+		return false
+	case !strings.HasPrefix(e.Path, f.PathPrefix):
+		return false
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && e.Time.After(f.Until):
+		return false
+	}
+	return true
+}
+
+type Log struct {
+	entries    []Entry
+	maxEntries int
+	nextID     int64
+	file       *os.File
+	logger     *zap.Logger
+	mu         sync.Mutex
+}
+
+func NewLog(cfg config.AdminAuditConfig, logger *zap.Logger) (*Log, error) {
+	l := &Log{
+		maxEntries: cfg.MaxEntries,
+		logger:     logger,
+	}
+
+	if cfg.Path != "" {
+		file, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log %s: %w", cfg.Path, err)
+		}
+		l.file = file
+	}
+
+	return l, nil
+}
+
+func (l *Log) Record(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nextID++
+	e.ID = l.nextID
+	l.entries = append(l.entries, e)
+	if len(l.entries) > l.maxEntries {
+		l.entries = l.entries[len(l.entries)-l.maxEntries:]
+	}
+
+	if l.file != nil {
+		line, err := json.Marshal(e)
+		if err == nil {
+			_, err = l.file.Write(append(line, '\n'))
+		}
+		if err != nil {
+			l.logger.Error("Failed to write audit log entry", zap.Error(err))
+		}
+	}
+
+	l.logger.Info("Admin API mutation",
+		zap.Int64("audit_id", e.ID),
+		zap.String("actor", e.Actor),
+		zap.String("method", e.Method),
+		zap.String("path", e.Path),
+		zap.String("query", e.Query),
+		zap.Int("status_code", e.Status),
+	)
+}
+
+func (l *Log) Query(f Filter) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := make([]Entry, 0)
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		if f.Limit > 0 && len(entries) >= f.Limit {
+			break
+		}
+		if f.matches(l.entries[i]) {
+			entries = append(entries, l.entries[i])
+		}
+	}
+	return entries
+}
+
+func (l *Log) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"CloudBalancer/config"
+)
+
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrForbidden    = errors.New("insufficient role for this operation")
+)
+
+type hmacKey []byte
+
+func (k hmacKey) key(alg, kid string) (any, error) {
+	if !strings.HasPrefix(alg, "HS") {
+		return nil, ErrUnsupportedAlg
+	}
+	return []byte(k), nil
+}
+
+type keyChain []keySource
+
+func (c keyChain) key(alg, kid string) (any, error) {
+	err := ErrUnsupportedAlg
+	for _, source := range c {
+		var key any
+		if key, err = source.key(alg, kid); err == nil {
+			return key, nil
+		}
+	}
+	return nil, err
+}
+
+type Authenticator struct {
+	enabled    bool
+	keys       keyChain
+	issuer     string
+	audience   string
+	rolesClaim string
+	leeway     time.Duration
+}
+
+type Identity struct {
+	Subject string
+	Roles   []string
+}
+
+func NewAuthenticator(cfg config.AdminAuthConfig) *Authenticator {
+	a := &Authenticator{
+		enabled:    cfg.Enabled,
+		issuer:     cfg.Issuer,
+		audience:   cfg.Audience,
+		rolesClaim: cfg.RolesClaim,
+		leeway:     cfg.Leeway,
+	}
+	if cfg.HMACSecret != "" {
+		a.keys = append(a.keys, hmacKey(cfg.HMACSecret))
+	}
+	if cfg.JWKSURL != "" {
+		a.keys = append(a.keys, newJWKS(cfg.JWKSURL, cfg.JWKSRefreshInterval))
+	}
+	return a
+}
+
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+func (a *Authenticator) Authenticate(r *http.Request) (Identity, error) {
+	authorization := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return Identity{}, ErrMissingToken
+	}
+
+	claims, err := parse(strings.TrimSpace(token), a.keys)
+	if err != nil {
+		return Identity{}, err
+	}
+	if err := claims.validate(time.Now(), a.leeway, a.issuer, a.audience); err != nil {
+		return Identity{}, err
+	}
+	return Identity{Subject: claims.Subject(), Roles: claims.Strings(a.rolesClaim)}, nil
+}
+
+func (id Identity) Authorize(method string) error {
+	if slices.Contains(id.Roles, RoleOperator) {
+		return nil
+	}
+	if slices.Contains(id.Roles, RoleViewer) && IsReadOnly(method) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+func (id Identity) AuthorizeOperator() error {
+	if slices.Contains(id.Roles, RoleOperator) {
+		return nil
+	}
+	return ErrForbidden
+}
+
+func IsReadOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
@@ -0,0 +1,332 @@
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"CloudBalancer/internal/headers"
+	"CloudBalancer/internal/requestid"
+
+	"go.uber.org/zap"
+)
+
+const (
+	StatusHeader = "X-Cache"
+	StatusHit    = "HIT"
+	StatusMiss   = "MISS"
+)
+
+type Entry struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Path       string
+	Created    time.Time
+	Expires    time.Time
+
+	key  string
+	size int64
+}
+
+type Stats struct {
+	Entries   int   `json:"entries"`
+	Size      int64 `json:"size"`
+	MaxSize   int64 `json:"max_size"`
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+}
+
+type Cache struct {
+	maxEntrySize int64
+	maxMemory    int64
+	size         int64
+	entries      map[string]*list.Element
+	vary         map[string][]string
+	lru          *list.List
+	hits         int64
+	misses       int64
+	evictions    int64
+	statusHeader bool
+	logger       *zap.Logger
+	mtx          sync.Mutex
+}
+
+func NewCache(maxEntrySize, maxMemory int64, statusHeader bool, logger *zap.Logger) *Cache {
+	logger.Info("Initializing response cache",
+		zap.Int64("maxEntrySize", maxEntrySize),
+		zap.Int64("maxMemory", maxMemory),
+	)
+
+	return &Cache{
+		maxEntrySize: maxEntrySize,
+		maxMemory:    maxMemory,
+		entries:      make(map[string]*list.Element),
+		vary:         make(map[string][]string),
+		lru:          list.New(),
+		statusHeader: statusHeader,
+		logger:       logger,
+	}
+}
+
+func (c *Cache) MaxEntrySize() int64 {
+	return c.maxEntrySize
+}
+
+func (c *Cache) SetStatus(w http.ResponseWriter, status string) {
+	if c.statusHeader {
+		w.Header().Set(StatusHeader, status)
+	}
+}
+
+func (c *Cache) Get(r *http.Request) (*Entry, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	base := baseKey(r)
+	key := varyKey(base, c.vary[base], r)
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+
+	entry := elem.Value.(*Entry)
+	if time.Now().After(entry.Expires) {
+		c.removeElement(elem)
+		c.misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.hits++
+	return entry, true
+}
+
+func (c *Cache) Store(r *http.Request, rec *Recorder, ttl time.Duration) {
+	if r.Method != http.MethodGet || !rec.wroteHeader || rec.overflow || rec.statusCode != http.StatusOK {
+		return
+	}
+
+	header := rec.header
+	if header.Get("Set-Cookie") != "" {
+		return
+	}
+
+	ttl, ok := responseTTL(header, ttl)
+	if !ok {
+		return
+	}
+
+	varyHeaders := parseVary(header)
+	for _, name := range varyHeaders {
+		if name == "*" {
+			return
+		}
+	}
+	header.Del(requestid.Header)
+	header.Del(StatusHeader)
+	header.Del(headers.ServedByHeader)
+
+	now := time.Now()
+	entry := &Entry{
+		StatusCode: rec.statusCode,
+		Header:     header,
+		Body:       rec.body.Bytes(),
+		Path:       r.URL.Path,
+		Created:    now,
+		Expires:    now.Add(ttl),
+	}
+	entry.size = int64(len(entry.Body)) + headerSize(header)
+
+	if entry.size > c.maxEntrySize || entry.size > c.maxMemory {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	base := baseKey(r)
+	c.vary[base] = varyHeaders
+	entry.key = varyKey(base, varyHeaders, r)
+
+	if elem, ok := c.entries[entry.key]; ok {
+		c.removeElement(elem)
+	}
+
+	for c.size+entry.size > c.maxMemory && c.lru.Len() > 0 {
+		c.removeElement(c.lru.Back())
+		c.evictions++
+	}
+
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	c.size += entry.size
+
+	c.logger.Debug("Response cached",
+		zap.String("path", entry.Path),
+		zap.Duration("ttl", ttl),
+		zap.Int64("size", entry.size),
+	)
+}
+
+func (c *Cache) Purge(prefix string) int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	purged := 0
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if strings.HasPrefix(elem.Value.(*Entry).Path, prefix) {
+			c.removeElement(elem)
+			purged++
+		}
+		elem = next
+	}
+
+	c.logger.Info("Response cache purged",
+		zap.String("prefix", prefix),
+		zap.Int("entries", purged),
+	)
+
+	return purged
+}
+
+func (c *Cache) Stats() Stats {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return Stats{
+		Entries:   c.lru.Len(),
+		Size:      c.size,
+		MaxSize:   c.maxMemory,
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+	}
+}
+
+func (c *Cache) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*Entry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
+
+func IsCacheableRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if r.Header.Get("Authorization") != "" {
+		return false
+	}
+
+	directives := parseCacheControl(r.Header.Get("Cache-Control"))
+	_, noStore := directives["no-store"]
+	_, noCache := directives["no-cache"]
+	return !noStore && !noCache
+}
+
+func (e *Entry) WriteTo(w http.ResponseWriter, r *http.Request) {
+	for name, values := range e.Header {
+		w.Header()[name] = append([]string(nil), values...)
+	}
+	w.Header().Set("Age", strconv.Itoa(int(time.Since(e.Created).Seconds())))
+	w.WriteHeader(e.StatusCode)
+
+	if r.Method != http.MethodHead {
+		w.Write(e.Body)
+	}
+}
+
+func baseKey(r *http.Request) string {
+	method := r.Method
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	return method + " " + r.Host + r.URL.RequestURI()
+}
+
+func varyKey(base string, varyHeaders []string, r *http.Request) string {
+	if len(varyHeaders) == 0 {
+		return base
+	}
+
+	var sb strings.Builder
+	sb.WriteString(base)
+	for _, name := range varyHeaders {
+		sb.WriteString("\n")
+		sb.WriteString(name)
+		sb.WriteString(": ")
+		sb.WriteString(strings.Join(r.Header.Values(name), ","))
+	}
+	return sb.String()
+}
+
+func parseVary(header http.Header) []string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func parseCacheControl(value string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(part, "=")
+		directives[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+	}
+	return directives
+}
+
+func responseTTL(header http.Header, ttl time.Duration) (time.Duration, bool) {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+
+	for _, name := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := directives[name]; ok {
+			return 0, false
+		}
+	}
+
+	for _, name := range []string{"s-maxage", "max-age"} {
+		value, ok := directives[name]
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds <= 0 {
+			return 0, false
+		}
+		if maxAge := time.Duration(seconds) * time.Second; maxAge < ttl {
+			ttl = maxAge
+		}
+		break
+	}
+
+	return ttl, true
+}
+
+func headerSize(header http.Header) int64 {
+	var size int64
+	for name, values := range header {
+		for _, value := range values {
+			size += int64(len(name) + len(value))
+		}
+	}
+	return size
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"bytes"
+	"net/http"
+)
+
+type Recorder struct {
+	http.ResponseWriter
+	statusCode  int
+	header      http.Header
+	body        bytes.Buffer
+	maxSize     int64
+	overflow    bool
+	wroteHeader bool
+}
+
+func NewRecorder(w http.ResponseWriter, maxSize int64) *Recorder {
+	return &Recorder{
+		ResponseWriter: w,
+		statusCode:     http.StatusOK,
+		maxSize:        maxSize,
+	}
+}
+
+func (rec *Recorder) WriteHeader(code int) {
+	if !rec.wroteHeader && code >= http.StatusOK {
+		rec.wroteHeader = true
+		rec.statusCode = code
+		rec.header = rec.ResponseWriter.Header().Clone()
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *Recorder) Write(p []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+
+	if !rec.overflow {
+		if int64(rec.body.Len()+len(p)) > rec.maxSize {
+			rec.overflow = true
+			rec.body.Reset()
+		} else {
+			rec.body.Write(p)
+		}
+	}
+
+	return rec.ResponseWriter.Write(p)
+}
+
+func (rec *Recorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,230 @@
+package capture
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"CloudBalancer/config"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	ErrUnknownRule = errors.New("unknown capture rule")
+	ErrInvalidRule = errors.New("capture rule requires a client_id or route")
+)
+
+type Rule struct {
+	ID        string    `json:"id"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Route     string    `json:"route,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Captured  int64     `json:"captured"`
+}
+
+func (r *Rule) matches(clientID, route string) bool {
+	return (r.ClientID == "" || r.ClientID == clientID) && (r.Route == "" || r.Route == route)
+}
+
+type Message struct {
+	Headers      http.Header `json:"headers"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"body_encoding,omitempty"`
+	Truncated    bool        `json:"truncated,omitempty"`
+}
+
+type Capture struct {
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	RuleID     string    `json:"rule_id"`
+	RequestID  string    `json:"request_id"`
+	ClientID   string    `json:"client_id"`
+	Route      string    `json:"route"`
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	Status     int       `json:"status"`
+	DurationMs float64   `json:"duration_ms"`
+	Request    Message   `json:"request"`
+	Response   Message   `json:"response"`
+}
+
+type Store struct {
+	capacity    int
+	maxBodySize int
+	maxTTL      time.Duration
+	redact      map[string]bool
+	rules       map[string]*Rule
+	ruleCount   atomic.Int64
+	captures    []Capture
+	next        int
+	nextID      int64
+	nextRuleID  int64
+	mu          sync.Mutex
+}
+
+func NewStore(cfg config.CaptureConfig) *Store {
+	redact := make(map[string]bool, len(cfg.RedactHeaders))
+	for _, name := range cfg.RedactHeaders {
+		redact[http.CanonicalHeaderKey(name)] = true
+	}
+	return &Store{
+		capacity:    cfg.Capacity,
+		maxBodySize: cfg.MaxBodySize,
+		maxTTL:      cfg.MaxTTL,
+		redact:      redact,
+		rules:       make(map[string]*Rule),
+	}
+}
+
+func (s *Store) MaxBodySize() int {
+	return s.maxBodySize
+}
+
+func (s *Store) MaxTTL() time.Duration {
+	return s.maxTTL
+}
+
+func (s *Store) AddRule(clientID, route string, ttl time.Duration) (Rule, error) {
+	if clientID == "" && route == "" {
+		return Rule{}, ErrInvalidRule
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.nextRuleID++
+	rule := &Rule{
+		ID:        strconv.FormatInt(s.nextRuleID, 10),
+		ClientID:  clientID,
+		Route:     route,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	s.rules[rule.ID] = rule
+	s.ruleCount.Store(int64(len(s.rules)))
+	return *rule, nil
+}
+
+func (s *Store) Rules() []Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(time.Now())
+	rules := make([]Rule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		rules = append(rules, *rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].CreatedAt.Before(rules[j].CreatedAt)
+	})
+	return rules
+}
+
+func (s *Store) DeleteRule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.rules[id]; !ok {
+		return ErrUnknownRule
+	}
+	delete(s.rules, id)
+	s.ruleCount.Store(int64(len(s.rules)))
+	return nil
+}
+
+func (s *Store) Match(clientID, route string) (string, bool) {
+	if s.ruleCount.Load() == 0 {
+		return "", false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(time.Now())
+	for _, rule := range s.rules {
+		if rule.matches(clientID, route) {
+			return rule.ID, true
+		}
+	}
+	return "", false
+}
+
+func (s *Store) pruneLocked(now time.Time) {
+	for id, rule := range s.rules {
+		if now.After(rule.ExpiresAt) {
+			delete(s.rules, id)
+		}
+	}
+	s.ruleCount.Store(int64(len(s.rules)))
+}
+
+func (s *Store) Record(c Capture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	c.ID = s.nextID
+	if rule, ok := s.rules[c.RuleID]; ok {
+		rule.Captured++
+	}
+
+	if len(s.captures) < s.capacity {
+		s.captures = append(s.captures, c)
+		return
+	}
+	s.captures[s.next] = c
+	s.next = (s.next + 1) % s.capacity
+}
+
+func (s *Store) Captures(ruleID string) []Capture {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	captures := make([]Capture, 0, len(s.captures))
+	for i := len(s.captures) - 1; i >= 0; i-- {
+		c := s.captures[(s.next+i)%len(s.captures)]
+		if ruleID == "" || c.RuleID == ruleID {
+			captures = append(captures, c)
+		}
+	}
+	return captures
+}
+
+func (s *Store) Clear() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cleared := len(s.captures)
+	s.captures = nil
+	s.next = 0
+	return cleared
+}
+
+func (s *Store) Message(headers http.Header, body []byte, truncated bool) Message {
+	sanitized := make(http.Header, len(headers))
+	for name, values := range headers {
+		if s.redact[http.CanonicalHeaderKey(name)] {
+			sanitized[name] = []string{redacted}
+			continue
+		}
+		sanitized[name] = append([]string(nil), values...)
+	}
+
+	message := Message{Headers: sanitized, Truncated: truncated}
+	if utf8.Valid(body) {
+		message.Body = string(body)
+	} else {
+		message.Body = base64.StdEncoding.EncodeToString(body)
+		message.BodyEncoding = "base64"
+	}
+	return message
+}
@@ -0,0 +1,139 @@
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const DefaultHeader = "X-Forwarded-For"
+
+type Resolver struct {
+	trusted []netip.Prefix
+	header  string
+}
+
+func NewResolver(trustedProxies []string, realIPHeader string) (*Resolver, error) {
+	if realIPHeader == "" {
+		realIPHeader = DefaultHeader
+	}
+	r := &Resolver{header: http.CanonicalHeaderKey(realIPHeader)}
+
+	for _, value := range trustedProxies {
+		prefix, err := ParsePrefix(value)
+		if err != nil {
+			return nil, err
+		}
+		r.trusted = append(r.trusted, prefix)
+	}
+
+	return r, nil
+}
+
+func ParsePrefix(value string) (netip.Prefix, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.Contains(value, "/") {
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR %q: %w", value, err)
+		}
+		return prefix.Masked(), nil
+	}
+
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address %q: %w", value, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+func (r *Resolver) IsTrusted(value string) bool {
+	addr, err := netip.ParseAddr(strings.TrimSpace(value))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Resolver) IsTrustedPeer(req *http.Request) bool {
+	return r.IsTrusted(PeerIP(req))
+}
+
+func (r *Resolver) Header() string {
+	return r.header
+}
+
+func (r *Resolver) ClientIP(req *http.Request) string {
+	peer := PeerIP(req)
+	if !r.IsTrusted(peer) {
+		return peer
+	}
+
+	chain := r.chain(req)
+	for i := len(chain) - 1; i >= 0; i-- {
+		if !r.IsTrusted(chain[i]) {
+			return chain[i]
+		}
+	}
+
+	if len(chain) > 0 {
+		return chain[0]
+	}
+	return peer
+}
+
+func PeerIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+func (r *Resolver) chain(req *http.Request) []string {
+	var chain []string
+	for _, value := range req.Header.Values(r.header) {
+		for _, hop := range strings.Split(value, ",") {
+			if r.header == "Forwarded" {
+				hop = forwardedFor(hop)
+			}
+			if hop = strings.TrimSpace(hop); hop != "" {
+				chain = append(chain, hop)
+			}
+		}
+	}
+	return chain
+}
+
+func forwardedFor(element string) string {
+	for _, pair := range strings.Split(element, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !strings.EqualFold(name, "for") {
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		if host, _, err := net.SplitHostPort(value); err == nil {
+			return host
+		}
+		return strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	}
+	return ""
+}
+
+func Scheme(req *http.Request) string {
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
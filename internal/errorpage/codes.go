@@ -0,0 +1,23 @@
+package errorpage
+
+type Code string
+
+const (
+	CodeNoHealthyBackend   Code = "NO_HEALTHY_BACKEND"
+	CodeBackendBusy        Code = "BACKEND_BUSY"
+	CodeBackendRateLimited Code = "BACKEND_RATE_LIMITED"
+	CodeQueueTimeout       Code = "QUEUE_TIMEOUT"
+	CodeUpstreamTimeout    Code = "UPSTREAM_TIMEOUT"
+	CodeUpstreamError      Code = "UPSTREAM_ERROR"
+	CodeRateLimited        Code = "RATE_LIMITED"
+	CodeQuotaExceeded      Code = "QUOTA_EXCEEDED"
+	CodeClientBanned       Code = "CLIENT_BANNED"
+	CodeConcurrencyLimited Code = "CONCURRENCY_LIMITED"
+	CodeOverloaded         Code = "OVERLOADED"
+	CodeBodyTooLarge       Code = "BODY_TOO_LARGE"
+	CodeBadRequestBody     Code = "BAD_REQUEST_BODY"
+	CodeMaintenance        Code = "MAINTENANCE"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeMethodNotAllowed   Code = "METHOD_NOT_ALLOWED"
+	CodeInternalError      Code = "INTERNAL_ERROR"
+)
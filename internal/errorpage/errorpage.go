@@ -0,0 +1,143 @@
+package errorpage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/requestid"
+)
+
+type Data struct {
+	RequestID  string
+	Code       string
+	Status     int
+	StatusText string
+	Message    string
+	Method     string
+	Path       string
+	RetryAfter int
+}
+
+type renderer interface {
+	Execute(w io.Writer, data any) error
+}
+
+type page struct {
+	contentType string
+	body        []byte
+	template    renderer
+}
+
+type Pages struct {
+	pages map[int]*page
+}
+
+var templateFuncs = map[string]any{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+func NewPages(cfg map[int]config.ErrorPageConfig) (*Pages, error) {
+	p := &Pages{pages: make(map[int]*page, len(cfg))}
+
+	for status, pageConfig := range cfg {
+		pg := &page{contentType: pageConfig.ContentType}
+
+		if pageConfig.File != "" {
+			body, err := os.ReadFile(pageConfig.File)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read error page %d: %w", status, err)
+			}
+			pg.body = body
+			if pg.contentType == "" {
+				pg.contentType = mime.TypeByExtension(filepath.Ext(pageConfig.File))
+			}
+		}
+		if pg.contentType == "" {
+			pg.contentType = "application/json"
+		}
+
+		if pageConfig.Template != "" {
+			var err error
+			if strings.HasPrefix(pg.contentType, "text/html") {
+				pg.template, err = htmltemplate.New(fmt.Sprint(status)).Funcs(templateFuncs).Parse(pageConfig.Template)
+			} else {
+				pg.template, err = template.New(fmt.Sprint(status)).Funcs(templateFuncs).Parse(pageConfig.Template)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid error page template for %d: %w", status, err)
+			}
+		}
+
+		p.pages[status] = pg
+	}
+
+	return p, nil
+}
+
+func (p *Pages) Write(w http.ResponseWriter, r *http.Request, status int, code Code, message string) {
+	p.WriteRetry(w, r, status, code, message, 0)
+}
+
+func (p *Pages) WriteRetry(w http.ResponseWriter, r *http.Request, status int, code Code, message string, retryAfter int) {
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+	contentType, body := "application/json", defaultBody(code, message, requestid.FromContext(r.Context()), retryAfter)
+
+	if p != nil {
+		if pg, ok := p.pages[status]; ok {
+			if rendered, err := pg.render(r, status, code, message, retryAfter); err == nil {
+				contentType, body = pg.contentType, rendered
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (pg *page) render(r *http.Request, status int, code Code, message string, retryAfter int) ([]byte, error) {
+	if pg.template == nil {
+		return pg.body, nil
+	}
+
+	var buf bytes.Buffer
+	err := pg.template.Execute(&buf, Data{
+		RequestID:  requestid.FromContext(r.Context()),
+		Code:       string(code),
+		Status:     status,
+		StatusText: http.StatusText(status),
+		Message:    message,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RetryAfter: retryAfter,
+	})
+	return buf.Bytes(), err
+}
+
+func defaultBody(code Code, message, requestID string, retryAfter int) []byte {
+	fields := map[string]any{"error": message, "code": code}
+	if requestID != "" {
+		fields["request_id"] = requestID
+	}
+	if retryAfter > 0 {
+		fields["retry_after"] = retryAfter
+	}
+	body, _ := json.Marshal(fields)
+	return append(body, '\n')
+}
@@ -0,0 +1,141 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	BackendUp          = "backend.up"
+	BackendDown        = "backend.down"
+	StrategyChanged    = "strategy.changed"
+	ConfigReloaded     = "config.reloaded"
+	ConfigReloadFailed = "config.reload_failed"
+	ClientBanned       = "client.banned"
+)
+
+var Types = []string{BackendUp, BackendDown, StrategyChanged, ConfigReloaded, ConfigReloadFailed, ClientBanned}
+
+const (
+	historySize      = 100
+	subscriberBuffer = 64
+)
+
+type Event struct {
+	ID   int64     `json:"id"`
+	Time time.Time `json:"time"`
+	Type string    `json:"type"`
+	Data any       `json:"data,omitempty"`
+}
+
+type Subscription struct {
+	C       <-chan Event
+	events  chan Event
+	dropped bool
+}
+
+type Bus struct {
+	history     []Event
+	nextID      int64
+	subscribers map[*Subscription]struct{}
+	closed      bool
+	mu          sync.Mutex
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *Bus) Publish(eventType string, data any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.nextID++
+	event := Event{ID: b.nextID, Time: time.Now(), Type: eventType, Data: data}
+	b.history = append(b.history, event)
+	if len(b.history) > historySize {
+		b.history = b.history[len(b.history)-historySize:]
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped = true
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+func (b *Bus) Subscribe(lastID int64) (*Subscription, []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := make(chan Event, subscriberBuffer)
+	sub := &Subscription{C: events, events: events}
+	if b.closed {
+		close(events)
+		return sub, nil
+	}
+	b.subscribers[sub] = struct{}{}
+
+	var missed []Event
+	if lastID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastID {
+				missed = append(missed, event)
+			}
+		}
+	}
+	return sub, missed
+}
+
+func (b *Bus) Recent(n int) []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	start := max(len(b.history)-n, 0)
+	recent := make([]Event, 0, len(b.history)-start)
+	for i := len(b.history) - 1; i >= start; i-- {
+		recent = append(recent, b.history[i])
+	}
+	return recent
+}
+
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+func (b *Bus) Dropped(sub *Subscription) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return sub.dropped
+}
+
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,143 @@
+package headers
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/clientip"
+)
+
+type Context struct {
+	Request   *http.Request
+	Response  *http.Response
+	BackendID string
+}
+
+type Transformer interface {
+	Transform(header http.Header, ctx *Context)
+}
+
+type TransformerFunc func(header http.Header, ctx *Context)
+
+func (f TransformerFunc) Transform(header http.Header, ctx *Context) {
+	f(header, ctx)
+}
+
+type Pipeline []Transformer
+
+func (p Pipeline) Transform(header http.Header, ctx *Context) {
+	for _, t := range p {
+		t.Transform(header, ctx)
+	}
+}
+
+type header struct {
+	name  string
+	value string
+}
+
+type Rules struct {
+	remove []string
+	set    []header
+	add    []header
+}
+
+func NewRules(cfg config.HeaderActionsConfig) *Rules {
+	rules := &Rules{}
+
+	for _, name := range cfg.Remove {
+		rules.remove = append(rules.remove, http.CanonicalHeaderKey(name))
+	}
+	rules.set = sortedHeaders(cfg.Set)
+	rules.add = sortedHeaders(cfg.Add)
+
+	return rules
+}
+
+func (r *Rules) Transform(h http.Header, ctx *Context) {
+	if r == nil {
+		return
+	}
+
+	for _, name := range r.remove {
+		h.Del(name)
+	}
+	for _, hdr := range r.set {
+		h.Set(hdr.name, expand(hdr.value, ctx))
+	}
+	for _, hdr := range r.add {
+		h.Add(hdr.name, expand(hdr.value, ctx))
+	}
+}
+
+func sortedHeaders(values map[string]string) []header {
+	result := make([]header, 0, len(values))
+	for name, value := range values {
+		result = append(result, header{name: http.CanonicalHeaderKey(name), value: value})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+var variablePattern = regexp.MustCompile(`\$\{([a-z_]+)\}`)
+
+func expand(value string, ctx *Context) string {
+	if ctx == nil || ctx.Request == nil {
+		return value
+	}
+
+	return variablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		switch match[2 : len(match)-1] {
+		case "backend_id":
+			return ctx.BackendID
+		case "host":
+			return ctx.Request.Host
+		case "method":
+			return ctx.Request.Method
+		case "path":
+			return ctx.Request.URL.Path
+		case "remote_addr":
+			return ctx.Request.RemoteAddr
+		default:
+			return match
+		}
+	})
+}
+
+func ForwardedHeaders(resolver *clientip.Resolver) Transformer {
+	return TransformerFunc(func(h http.Header, ctx *Context) {
+		clientIP := resolver.ClientIP(ctx.Request)
+
+		if !resolver.IsTrustedPeer(ctx.Request) {
+			h.Del("X-Forwarded-For")
+			h.Del("X-Forwarded-Host")
+			h.Del("X-Forwarded-Proto")
+			h.Del(resolver.Header())
+		}
+
+		if h.Get("X-Forwarded-Host") == "" {
+			h.Set("X-Forwarded-Host", ctx.Request.Host)
+		}
+		if h.Get("X-Forwarded-Proto") == "" {
+			h.Set("X-Forwarded-Proto", clientip.Scheme(ctx.Request))
+		}
+		h.Set("X-Real-IP", clientIP)
+	})
+}
+
+const ServedByHeader = "X-Served-By"
+
+var ServedBy = TransformerFunc(func(h http.Header, ctx *Context) {
+	h.Set(ServedByHeader, ctx.BackendID)
+})
+
+var IdentityHeaders = NewRules(config.HeaderActionsConfig{
+	Set: map[string]string{
+		"X-Load-Balancer": "CloudBalancer",
+		"X-Backend":       "${backend_id}",
+	},
+})
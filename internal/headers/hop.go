@@ -0,0 +1,71 @@
+package headers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var hopByHopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+var HopByHopHeaders = TransformerFunc(func(h http.Header, ctx *Context) {
+	if ctx.Response != nil && ctx.Response.StatusCode == http.StatusSwitchingProtocols {
+		return
+	}
+
+	var upgrade string
+	if ctx.Response == nil && containsToken(h.Values("Connection"), "upgrade") {
+		upgrade = h.Get("Upgrade")
+	}
+
+	for _, value := range h.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				h.Del(name)
+			}
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		h.Del(name)
+	}
+
+	if upgrade != "" {
+		h.Set("Connection", "Upgrade")
+		h.Set("Upgrade", upgrade)
+	}
+})
+
+func Via(pseudonym string) Transformer {
+	return TransformerFunc(func(h http.Header, ctx *Context) {
+		if pseudonym == "" {
+			return
+		}
+
+		major, minor := ctx.Request.ProtoMajor, ctx.Request.ProtoMinor
+		if ctx.Response != nil {
+			major, minor = ctx.Response.ProtoMajor, ctx.Response.ProtoMinor
+		}
+		h.Add("Via", fmt.Sprintf("%d.%d %s", major, minor, pseudonym))
+	})
+}
+
+func containsToken(values []string, token string) bool {
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(item), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
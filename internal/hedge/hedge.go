@@ -0,0 +1,55 @@
+package hedge
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	sampleSize = 256
+	minSamples = 20
+)
+
+type Policy struct {
+	percentile float64
+	delay      time.Duration
+	samples    []time.Duration
+	next       int
+	mu         sync.Mutex
+}
+
+func NewPolicy(percentile float64, delay time.Duration) *Policy {
+	return &Policy{
+		percentile: percentile,
+		delay:      delay,
+		samples:    make([]time.Duration, 0, sampleSize),
+	}
+}
+
+func (p *Policy) Observe(latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.samples) < sampleSize {
+		p.samples = append(p.samples, latency)
+		return
+	}
+	p.samples[p.next] = latency
+	p.next = (p.next + 1) % sampleSize
+}
+
+func (p *Policy) Delay() time.Duration {
+	p.mu.Lock()
+	if len(p.samples) < minSamples {
+		p.mu.Unlock()
+		return p.delay
+	}
+	sorted := slices.Clone(p.samples)
+	p.mu.Unlock()
+
+	slices.Sort(sorted)
+	index := int(math.Ceil(p.percentile/100*float64(len(sorted)))) - 1
+	return sorted[max(index, 0)]
+}
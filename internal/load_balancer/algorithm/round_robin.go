@@ -26,15 +26,31 @@ func (s *RoundRobinStrategy) NextBackend(backends []*backend.Backend) (*backend.
 	s.mtx.Lock()
 	defer s.mtx.Unlock()
 
+	s.current %= len(backends)
 	start := s.current
+	atCapacity, rateLimited := false, false
 	for {
 		backendItem := backends[s.current]
 		s.current = (s.current + 1) % len(backends)
 
-		if backendItem.IsHealthy() {
-			return backendItem, nil
+		if backendItem.IsHealthy() && !backendItem.IsDraining() {
+			switch {
+			case !backendItem.TryAcquire():
+				atCapacity = true
+			case !backendItem.AllowRequest():
+				backendItem.CancelAcquire()
+				rateLimited = true
+			default:
+				return backendItem, nil
+			}
 		}
 		if s.current == start {
+			if atCapacity {
+				return nil, backend.ErrAtCapacity
+			}
+			if rateLimited {
+				return nil, backend.ErrRateLimited
+			}
 			return nil, fmt.Errorf("no healthy backends available")
 		}
 	}
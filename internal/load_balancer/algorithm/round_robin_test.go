@@ -0,0 +1,29 @@
+package algorithm
+
+import (
+	"testing"
+
+	"CloudBalancer/internal/load_balancer/backend"
+)
+
+func TestRoundRobinShrunkPool(t *testing.T) {
+	backends := []*backend.Backend{
+		backend.NewBackend("a", nil, nil, nil),
+		backend.NewBackend("b", nil, nil, nil),
+		backend.NewBackend("c", nil, nil, nil),
+	}
+	s := NewRoundRobinStrategy()
+	for range 2 {
+		if _, err := s.NextBackend(backends); err != nil {
+			t.Fatalf("NextBackend: %v", err)
+		}
+	}
+
+	got, err := s.NextBackend(backends[:1])
+	if err != nil {
+		t.Fatalf("NextBackend after shrink: %v", err)
+	}
+	if got.ID != "a" {
+		t.Fatalf("got backend %s, want a", got.ID)
+	}
+}
@@ -9,11 +9,37 @@ type Strategy interface {
 	Name() string
 }
 
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var registry = []struct {
+	Info
+	new func() Strategy
+}{
+	{
+		Info: Info{
+			Name:        "RoundRobin",
+			Description: "Cycles through healthy backends in order, skipping those at their connection or rate limit",
+		},
+		new: func() Strategy { return NewRoundRobinStrategy() },
+	},
+}
+
 func GetStrategy(name string) (Strategy, error) {
-	switch name {
-	case "RoundRobin":
-		return NewRoundRobinStrategy(), nil
-	default:
-		return nil, backend.ErrUnknownStrategy(name)
+	for _, entry := range registry {
+		if entry.Name == name {
+			return entry.new(), nil
+		}
+	}
+	return nil, backend.ErrUnknownStrategy(name)
+}
+
+func Strategies() []Info {
+	infos := make([]Info, 0, len(registry))
+	for _, entry := range registry {
+		infos = append(infos, entry.Info)
 	}
+	return infos
 }
@@ -0,0 +1,24 @@
+package backend
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrHedgeCanceled = errors.New("hedged request canceled after another backend responded")
+
+type Attempt struct {
+	Err error
+}
+
+type attemptKey struct{}
+
+func WithAttempt(ctx context.Context) (context.Context, *Attempt) {
+	attempt := &Attempt{}
+	return context.WithValue(ctx, attemptKey{}, attempt), attempt
+}
+
+func AttemptFromContext(ctx context.Context) *Attempt {
+	attempt, _ := ctx.Value(attemptKey{}).(*Attempt)
+	return attempt
+}
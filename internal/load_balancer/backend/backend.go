@@ -1,30 +1,62 @@
 package backend
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"sync"
 	"sync/atomic"
+	"time"
+
+	"CloudBalancer/internal/proxyproto"
+
+	"golang.org/x/time/rate"
 )
 
 type Backend struct {
 	ID                string
+	Group             string
+	Pool              string
 	URL               *url.URL
+	HealthURL         *url.URL
 	Proxy             *httputil.ReverseProxy
 	isHealthy         bool
+	isDraining        bool
+	healthSuccesses   int
+	healthFailures    int
+	healthInFlight    atomic.Bool
+	retired           atomic.Bool
 	activeConnections int64
+	activeWebSockets  int64
+	totalRequests     int64
+	failedRequests    int64
 	mtx               sync.RWMutex
+
+	WebSocketIdleTimeout time.Duration
+	SendProxyProtocol    bool
+	MaxConnections       int64
+	OnRelease            func()
+	RateLimiter          *rate.Limiter
+	HealthTransport      *http.Transport
+	Stats                *Stats
 }
 
-func NewBackend(id string, url *url.URL, proxy *httputil.ReverseProxy) *Backend {
+var (
+	ErrAtCapacity  = errors.New("all backends are at their connection limit")
+	ErrRateLimited = errors.New("all backends are at their request rate limit")
+)
+
+func NewBackend(id string, url *url.URL, healthURL *url.URL, proxy *httputil.ReverseProxy) *Backend {
 	return &Backend{
 		ID:                id,
 		URL:               url,
+		HealthURL:         healthURL,
 		Proxy:             proxy,
 		isHealthy:         true,
 		activeConnections: 0,
+		Stats:             NewStats(),
 	}
 }
 
@@ -38,27 +70,180 @@ func (b *Backend) SetHealthy(healthy bool) {
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 	b.isHealthy = healthy
+	b.healthSuccesses = 0
+	b.healthFailures = 0
+}
+
+func (b *Backend) RecordHealthCheck(success bool, rise, fall int) (healthy, changed bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if success {
+		b.healthFailures = 0
+		b.healthSuccesses++
+		if !b.isHealthy && b.healthSuccesses >= rise {
+			b.isHealthy = true
+			changed = true
+		}
+	} else {
+		b.healthSuccesses = 0
+		b.healthFailures++
+		if b.isHealthy && b.healthFailures >= fall {
+			b.isHealthy = false
+			changed = true
+		}
+	}
+	return b.isHealthy, changed
+}
+
+func (b *Backend) BeginHealthCheck() bool {
+	return b.healthInFlight.CompareAndSwap(false, true)
+}
+
+func (b *Backend) EndHealthCheck() {
+	b.healthInFlight.Store(false)
+	if b.retired.Load() && b.HealthTransport != nil {
+		b.HealthTransport.CloseIdleConnections()
+	}
+}
+
+func (b *Backend) IsDraining() bool {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	return b.isDraining
+}
+
+func (b *Backend) SetDraining(draining bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.isDraining = draining
 }
 
 func (b *Backend) ActiveConnections() int64 {
 	return atomic.LoadInt64(&b.activeConnections)
 }
 
-func (b *Backend) IncrementConnections() {
-	atomic.AddInt64(&b.activeConnections, 1)
+func (b *Backend) TryAcquire() bool {
+	for {
+		active := atomic.LoadInt64(&b.activeConnections)
+		if b.MaxConnections > 0 && active >= b.MaxConnections {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&b.activeConnections, active, active+1) {
+			return true
+		}
+	}
+}
+
+func (b *Backend) CancelAcquire() {
+	if atomic.AddInt64(&b.activeConnections, -1) == 0 && b.retired.Load() {
+		b.closeIdleConnections()
+	}
 }
 
 func (b *Backend) DecrementConnections() {
-	atomic.AddInt64(&b.activeConnections, -1)
+	b.CancelAcquire()
+	if b.OnRelease != nil {
+		b.OnRelease()
+	}
+}
+
+func (b *Backend) Retire() {
+	b.retired.Store(true)
+	if b.ActiveConnections() == 0 {
+		b.closeIdleConnections()
+	}
+}
+
+func (b *Backend) closeIdleConnections() {
+	if transport, ok := b.Proxy.Transport.(interface{ CloseIdleConnections() }); ok {
+		transport.CloseIdleConnections()
+	}
+	if b.HealthTransport != nil {
+		b.HealthTransport.CloseIdleConnections()
+	}
+}
+
+func (b *Backend) AllowRequest() bool {
+	return b.RateLimiter == nil || b.RateLimiter.Allow()
+}
+
+func (b *Backend) ActiveWebSockets() int64 {
+	return atomic.LoadInt64(&b.activeWebSockets)
+}
+
+func (b *Backend) TotalRequests() int64 {
+	return atomic.LoadInt64(&b.totalRequests)
+}
+
+func (b *Backend) FailedRequests() int64 {
+	return atomic.LoadInt64(&b.failedRequests)
+}
+
+func (b *Backend) ResetStats() {
+	atomic.StoreInt64(&b.totalRequests, 0)
+	atomic.StoreInt64(&b.failedRequests, 0)
+	b.Stats.Reset()
 }
 
 func (b *Backend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	b.IncrementConnections()
 	defer b.DecrementConnections()
 
+	start := time.Now()
+	websocket := IsWebSocketRequest(r)
+	sw := &statusWriter{ResponseWriter: w}
+	w = sw
+	defer func() {
+		atomic.AddInt64(&b.totalRequests, 1)
+		failed := sw.statusCode >= http.StatusInternalServerError
+		if attempt := AttemptFromContext(r.Context()); attempt != nil && attempt.Err != nil {
+			failed = true
+		}
+		if failed {
+			atomic.AddInt64(&b.failedRequests, 1)
+		}
+		b.Stats.Record(sw.statusCode, time.Since(start), failed, websocket)
+	}()
+
+	if websocket {
+		atomic.AddInt64(&b.activeWebSockets, 1)
+		defer atomic.AddInt64(&b.activeWebSockets, -1)
+
+		if b.WebSocketIdleTimeout > 0 {
+			w = &idleTimeoutWriter{ResponseWriter: w, timeout: b.WebSocketIdleTimeout}
+		}
+	}
+
+	if b.SendProxyProtocol {
+		r = r.WithContext(proxyproto.WithSourceAddr(r.Context(), r.RemoteAddr))
+	}
+
 	b.Proxy.ServeHTTP(w, r)
 }
 
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.statusCode == 0 && (code >= http.StatusOK || code == http.StatusSwitchingProtocols) {
+		sw.statusCode = code
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Write(p []byte) (int, error) {
+	if sw.statusCode == 0 {
+		sw.statusCode = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(p)
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
 func ErrUnknownStrategy(name string) error {
 	return fmt.Errorf("unknown balancing strategy: %s", name)
 }
@@ -0,0 +1,237 @@
+package backend
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	statsSlotDuration = 5 * time.Second
+	statsSlotCount    = 60
+)
+
+var latencyBounds = [...]time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	30 * time.Millisecond,
+	50 * time.Millisecond,
+	75 * time.Millisecond,
+	100 * time.Millisecond,
+	150 * time.Millisecond,
+	200 * time.Millisecond,
+	300 * time.Millisecond,
+	500 * time.Millisecond,
+	750 * time.Millisecond,
+	time.Second,
+	1500 * time.Millisecond,
+	2 * time.Second,
+	3 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	60 * time.Second,
+}
+
+type statsSlot struct {
+	index    int64
+	requests int64
+	status   [6]int64
+	failed   int64
+	latency  [len(latencyBounds) + 1]int64
+	duration time.Duration
+}
+
+func (s *statsSlot) record(status int, latency time.Duration, failed, websocket bool) {
+	s.requests++
+	if class := status / 100; class >= 1 && class <= 5 {
+		s.status[class]++
+	} else {
+		s.status[0]++
+	}
+	if failed {
+		s.failed++
+	}
+	if !websocket {
+		s.latency[latencyBucket(latency)]++
+		s.duration += latency
+	}
+}
+
+func (s *statsSlot) add(other *statsSlot) {
+	s.requests += other.requests
+	s.failed += other.failed
+	for i := range s.status {
+		s.status[i] += other.status[i]
+	}
+	for i := range s.latency {
+		s.latency[i] += other.latency[i]
+	}
+	s.duration += other.duration
+}
+
+type Snapshot struct {
+	Requests     int64   `json:"requests"`
+	RPS          float64 `json:"rps"`
+	Status2xx    int64   `json:"status_2xx"`
+	Status3xx    int64   `json:"status_3xx"`
+	Status4xx    int64   `json:"status_4xx"`
+	Status5xx    int64   `json:"status_5xx"`
+	Failed       int64   `json:"failed"`
+	ErrorRate    float64 `json:"error_rate"`
+	LatencyP50Ms float64 `json:"latency_p50_ms"`
+	LatencyP95Ms float64 `json:"latency_p95_ms"`
+	LatencyP99Ms float64 `json:"latency_p99_ms"`
+}
+
+type Stats struct {
+	slots   [statsSlotCount]statsSlot
+	total   statsSlot
+	started time.Time
+	mu      sync.Mutex
+}
+
+func NewStats() *Stats {
+	return &Stats{started: time.Now()}
+}
+
+func (s *Stats) Record(status int, latency time.Duration, failed, websocket bool) {
+	index := time.Now().UnixNano() / int64(statsSlotDuration)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slot := &s.slots[index%statsSlotCount]
+	if slot.index != index {
+		*slot = statsSlot{index: index}
+	}
+
+	slot.record(status, latency, failed, websocket)
+	s.total.record(status, latency, failed, websocket)
+}
+
+func (s *Stats) Total() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.total.snapshot(time.Since(s.started))
+}
+
+func LatencyBounds() []time.Duration {
+	return latencyBounds[:]
+}
+
+func (s *Stats) LatencyHistogram() (cumulative []uint64, count uint64, sum time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cumulative = make([]uint64, len(latencyBounds))
+	for i, n := range s.total.latency {
+		count += uint64(n)
+		if i < len(latencyBounds) {
+			cumulative[i] = count
+		}
+	}
+	return cumulative, count, s.total.duration
+}
+
+func (s *Stats) Since() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.started
+}
+
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.slots = [statsSlotCount]statsSlot{}
+	s.total = statsSlot{}
+	s.started = time.Now()
+}
+
+func (s *Stats) Window(d time.Duration) Snapshot {
+	now := time.Now()
+	current := now.UnixNano() / int64(statsSlotDuration)
+	slots := min(int64(math.Ceil(float64(d)/float64(statsSlotDuration))), statsSlotCount)
+
+	s.mu.Lock()
+	var sum statsSlot
+	for i := range s.slots {
+		if index := s.slots[i].index; index > current-slots && index <= current {
+			sum.add(&s.slots[i])
+		}
+	}
+	elapsed := min(d, now.Sub(s.started))
+	s.mu.Unlock()
+
+	return sum.snapshot(elapsed)
+}
+
+func (s *statsSlot) snapshot(elapsed time.Duration) Snapshot {
+	snapshot := Snapshot{
+		Requests:     s.requests,
+		Status2xx:    s.status[http.StatusOK/100],
+		Status3xx:    s.status[http.StatusMultipleChoices/100],
+		Status4xx:    s.status[http.StatusBadRequest/100],
+		Status5xx:    s.status[http.StatusInternalServerError/100],
+		Failed:       s.failed,
+		LatencyP50Ms: s.percentile(0.50),
+		LatencyP95Ms: s.percentile(0.95),
+		LatencyP99Ms: s.percentile(0.99),
+	}
+	if elapsed > 0 {
+		snapshot.RPS = float64(s.requests) / elapsed.Seconds()
+	}
+	if s.requests > 0 {
+		snapshot.ErrorRate = float64(s.failed) / float64(s.requests)
+	}
+	return snapshot
+}
+
+func (s *statsSlot) percentile(q float64) float64 {
+	var count int64
+	for _, n := range s.latency {
+		count += n
+	}
+	if count == 0 {
+		return 0
+	}
+
+	rank := q * float64(count)
+	var seen int64
+	for i, n := range s.latency {
+		if n == 0 || float64(seen+n) < rank {
+			seen += n
+			continue
+		}
+		if i == len(latencyBounds) {
+			return milliseconds(latencyBounds[i-1])
+		}
+		lower := time.Duration(0)
+		if i > 0 {
+			lower = latencyBounds[i-1]
+		}
+		fraction := (rank - float64(seen)) / float64(n)
+		return milliseconds(lower) + fraction*milliseconds(latencyBounds[i]-lower)
+	}
+	return milliseconds(latencyBounds[len(latencyBounds)-1])
+}
+
+func latencyBucket(latency time.Duration) int {
+	for i, bound := range latencyBounds {
+		if latency <= bound {
+			return i
+		}
+	}
+	return len(latencyBounds)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,269 @@
+package load_balancer
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/load_balancer/backend"
+
+	"go.uber.org/zap"
+)
+
+type BackendChanges struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+func (p *pool) addBackend(b *backend.Backend) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.backends = append(p.backends, b)
+	p.rebuildGroups()
+}
+
+func (p *pool) replaceBackend(old, b *backend.Backend) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if i := slices.Index(p.backends, old); i >= 0 {
+		p.backends[i] = b
+	}
+	p.rebuildGroups()
+}
+
+func (p *pool) removeBackend(b *backend.Backend) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.backends = slices.DeleteFunc(p.backends, func(existing *backend.Backend) bool {
+		return existing == b
+	})
+	p.rebuildGroups()
+}
+
+func (lb *loadBalancer) findBackend(id string) (int, error) {
+	i := slices.IndexFunc(lb.backends, func(b *backend.Backend) bool {
+		return b.ID == id
+	})
+	if i < 0 {
+		return -1, fmt.Errorf("%w: %s", ErrUnknownBackend, id)
+	}
+	return i, nil
+}
+
+func (lb *loadBalancer) register(b *backend.Backend, cfg config.BackendConfig) error {
+	p, err := lb.poolFor(b.Pool)
+	if err != nil {
+		return err
+	}
+	p.addBackend(b)
+
+	if lb.backendConfigs == nil {
+		lb.backendConfigs = make(map[string]config.BackendConfig)
+	}
+	lb.backends = append(lb.backends, b)
+	lb.backendConfigs[b.ID] = cfg
+	return nil
+}
+
+func (lb *loadBalancer) GetBackend(id string) (*backend.Backend, config.BackendConfig, error) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	i, err := lb.findBackend(id)
+	if err != nil {
+		return nil, config.BackendConfig{}, err
+	}
+	return lb.backends[i], lb.backendConfigs[id], nil
+}
+
+func (lb *loadBalancer) AddBackend(cfg config.BackendConfig) (*backend.Backend, error) {
+	b, err := lb.newBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if _, err := lb.findBackend(cfg.ID); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBackendExists, cfg.ID)
+	}
+
+	if err := lb.register(b, cfg); err != nil {
+		return nil, err
+	}
+
+	lb.logger.Info("Backend added",
+		zap.String("backend", b.ID),
+		zap.String("pool", b.Pool),
+		zap.String("url", b.URL.String()),
+	)
+
+	return b, nil
+}
+
+func (lb *loadBalancer) UpdateBackend(cfg config.BackendConfig) (*backend.Backend, error) {
+	b, err := lb.newBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	i, err := lb.findBackend(cfg.ID)
+	if err != nil {
+		return nil, err
+	}
+	old := lb.backends[i]
+	inheritState(old, b)
+
+	p, err := lb.poolFor(b.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if old.Pool == b.Pool {
+		p.replaceBackend(old, b)
+	} else {
+		lb.pools[old.Pool].removeBackend(old)
+		p.addBackend(b)
+	}
+
+	lb.backends[i] = b
+	lb.backendConfigs[b.ID] = cfg
+	old.Retire()
+
+	lb.logger.Info("Backend updated",
+		zap.String("backend", b.ID),
+		zap.String("pool", b.Pool),
+		zap.String("url", b.URL.String()),
+	)
+
+	return b, nil
+}
+
+func (lb *loadBalancer) RemoveBackend(id string) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	i, err := lb.findBackend(id)
+	if err != nil {
+		return err
+	}
+	if len(lb.backends) == 1 {
+		return ErrLastBackend
+	}
+
+	b := lb.backends[i]
+	lb.pools[b.Pool].removeBackend(b)
+	lb.backends = slices.Delete(lb.backends, i, i+1)
+	delete(lb.backendConfigs, id)
+	b.Retire()
+
+	lb.logger.Info("Backend removed",
+		zap.String("backend", b.ID),
+		zap.String("pool", b.Pool),
+	)
+
+	return nil
+}
+
+func (lb *loadBalancer) ReplaceBackends(cfgs []config.BackendConfig) (BackendChanges, error) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	var changes BackendChanges
+	enabled := make(map[string]config.BackendConfig)
+	built := make(map[string]*backend.Backend)
+	for _, cfg := range cfgs {
+		if !cfg.Enabled {
+			continue
+		}
+		enabled[cfg.ID] = cfg
+		existing, ok := lb.backendConfigs[cfg.ID]
+		if ok && reflect.DeepEqual(existing, cfg) {
+			continue
+		}
+		b, err := lb.newBackend(cfg)
+		if err != nil {
+			return BackendChanges{}, fmt.Errorf("backend %s: %w", cfg.ID, err)
+		}
+		built[cfg.ID] = b
+		if ok {
+			changes.Updated = append(changes.Updated, cfg.ID)
+		} else {
+			changes.Added = append(changes.Added, cfg.ID)
+		}
+	}
+	if len(enabled) == 0 {
+		return BackendChanges{}, fmt.Errorf("no enabled backends configured")
+	}
+
+	pools := make(map[string]*pool)
+	for _, b := range built {
+		if _, ok := lb.pools[b.Pool]; ok || pools[b.Pool] != nil {
+			continue
+		}
+		p, err := newPool(b.Pool, lb.config, lb.logger)
+		if err != nil {
+			return BackendChanges{}, err
+		}
+		if err := p.setStrategy(lb.strategy.Name()); err != nil {
+			return BackendChanges{}, err
+		}
+		pools[b.Pool] = p
+	}
+
+	maps.Copy(lb.pools, pools)
+	backends := make([]*backend.Backend, 0, len(enabled))
+	for _, old := range lb.backends {
+		cfg, keep := enabled[old.ID]
+		b, replaced := built[old.ID]
+		switch {
+		case !keep:
+			lb.pools[old.Pool].removeBackend(old)
+			delete(lb.backendConfigs, old.ID)
+			old.Retire()
+			changes.Removed = append(changes.Removed, old.ID)
+		case replaced:
+			inheritState(old, b)
+			if old.Pool == b.Pool {
+				lb.pools[b.Pool].replaceBackend(old, b)
+			} else {
+				lb.pools[old.Pool].removeBackend(old)
+				lb.pools[b.Pool].addBackend(b)
+			}
+			lb.backendConfigs[b.ID] = cfg
+			old.Retire()
+			backends = append(backends, b)
+		default:
+			backends = append(backends, old)
+		}
+	}
+	for _, id := range changes.Added {
+		b := built[id]
+		lb.pools[b.Pool].addBackend(b)
+		lb.backendConfigs[id] = enabled[id]
+		backends = append(backends, b)
+	}
+	lb.backends = backends
+
+	if len(changes.Added)+len(changes.Updated)+len(changes.Removed) > 0 {
+		lb.logger.Info("Backends replaced",
+			zap.Strings("added", changes.Added),
+			zap.Strings("updated", changes.Updated),
+			zap.Strings("removed", changes.Removed),
+		)
+	}
+
+	return changes, nil
+}
+
+func inheritState(old, b *backend.Backend) {
+	b.SetHealthy(old.IsHealthy())
+	b.SetDraining(old.IsDraining())
+	b.Stats = old.Stats
+}
@@ -0,0 +1,192 @@
+package load_balancer
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	BlueGroup  = "blue"
+	GreenGroup = "green"
+
+	DeploymentStable     = "stable"
+	DeploymentValidating = "validating"
+	DeploymentRolledBack = "rolled_back"
+)
+
+type DeploymentStatus struct {
+	Pool           string     `json:"pool"`
+	ActiveGroup    string     `json:"active_group"`
+	PreviousGroup  string     `json:"previous_group,omitempty"`
+	Status         string     `json:"status"`
+	SwitchedAt     *time.Time `json:"switched_at,omitempty"`
+	TotalRequests  int64      `json:"total_requests"`
+	FailedRequests int64      `json:"failed_requests"`
+	ErrorRate      float64    `json:"error_rate"`
+}
+
+type deployment struct {
+	previousGroup string
+	status        string
+	switchedAt    time.Time
+	baseline      map[string][2]int64
+}
+
+func isDeploymentGroup(group string) bool {
+	return group == BlueGroup || group == GreenGroup
+}
+
+func (p *pool) rebuildGroups() {
+	p.stableBackends = nil
+	p.canaryBackends = nil
+
+	for _, b := range p.backends {
+		if isDeploymentGroup(b.Group) && b.Group != p.activeGroup {
+			continue
+		}
+		if b.Group == CanaryGroup {
+			p.canaryBackends = append(p.canaryBackends, b)
+		} else {
+			p.stableBackends = append(p.stableBackends, b)
+		}
+	}
+}
+
+func (p *pool) getDeployment() DeploymentStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := DeploymentStatus{
+		Pool:        p.name,
+		ActiveGroup: p.activeGroup,
+		Status:      DeploymentStable,
+	}
+
+	if p.deployment != nil {
+		status.PreviousGroup = p.deployment.previousGroup
+		status.Status = p.deployment.status
+		switchedAt := p.deployment.switchedAt
+		status.SwitchedAt = &switchedAt
+		status.TotalRequests, status.FailedRequests = p.deploymentCounters(p.deployment)
+		if status.TotalRequests > 0 {
+			status.ErrorRate = float64(status.FailedRequests) / float64(status.TotalRequests)
+		}
+	}
+
+	return status
+}
+
+func (p *pool) switchDeployment(group string, autoRollback bool) error {
+	if !isDeploymentGroup(group) {
+		return fmt.Errorf("unknown deployment group: %s. Supported groups: [%s %s]", group, BlueGroup, GreenGroup)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if group == p.activeGroup {
+		return fmt.Errorf("deployment group %s is already active", group)
+	}
+
+	baseline := make(map[string][2]int64)
+	for _, b := range p.backends {
+		if b.Group == group {
+			baseline[b.ID] = [2]int64{b.TotalRequests(), b.FailedRequests()}
+		}
+	}
+	if len(baseline) == 0 {
+		return fmt.Errorf("no backends configured in deployment group %s of pool %s", group, p.name)
+	}
+
+	d := &deployment{
+		previousGroup: p.activeGroup,
+		status:        DeploymentStable,
+		switchedAt:    time.Now(),
+		baseline:      baseline,
+	}
+
+	validation := p.blueGreen
+	if autoRollback && validation.ValidationWindow > 0 {
+		d.status = DeploymentValidating
+		go p.validateDeployment(d)
+	}
+
+	p.activeGroup = group
+	p.deployment = d
+	p.rebuildGroups()
+
+	p.logger.Info("Deployment group switched",
+		zap.String("active", group),
+		zap.String("previous", d.previousGroup),
+		zap.Bool("autoRollback", d.status == DeploymentValidating),
+	)
+
+	return nil
+}
+
+func (p *pool) validateDeployment(d *deployment) {
+	validation := p.blueGreen
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	deadline := time.After(validation.ValidationWindow)
+
+	for {
+		select {
+		case <-deadline:
+			p.mu.Lock()
+			if p.deployment == d && d.status == DeploymentValidating {
+				d.status = DeploymentStable
+				p.logger.Info("Deployment validation window passed",
+					zap.String("active", p.activeGroup),
+				)
+			}
+			p.mu.Unlock()
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			if p.deployment != d {
+				p.mu.Unlock()
+				return
+			}
+
+			total, failed := p.deploymentCounters(d)
+			if total >= int64(validation.MinRequests) && total > 0 {
+				errorRate := float64(failed) / float64(total)
+				if errorRate > validation.MaxErrorRate {
+					failedGroup, restoredGroup := p.activeGroup, d.previousGroup
+					p.activeGroup, d.previousGroup = restoredGroup, failedGroup
+					d.status = DeploymentRolledBack
+					p.rebuildGroups()
+					p.mu.Unlock()
+
+					p.logger.Warn("Deployment rolled back due to error rate spike",
+						zap.String("failed", failedGroup),
+						zap.String("active", restoredGroup),
+						zap.Float64("errorRate", errorRate),
+						zap.Float64("maxErrorRate", validation.MaxErrorRate),
+						zap.Int64("requests", total),
+					)
+					return
+				}
+			}
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (p *pool) deploymentCounters(d *deployment) (int64, int64) {
+	var total, failed int64
+	for _, b := range p.backends {
+		base, ok := d.baseline[b.ID]
+		if !ok {
+			continue
+		}
+		total += b.TotalRequests() - base[0]
+		failed += b.FailedRequests() - base[1]
+	}
+	return total, failed
+}
@@ -2,89 +2,118 @@ package load_balancer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"CloudBalancer/config"
+	"CloudBalancer/internal/clientip"
+	"CloudBalancer/internal/errorpage"
+	"CloudBalancer/internal/events"
+	"CloudBalancer/internal/headers"
 	"CloudBalancer/internal/load_balancer/algorithm"
 	"CloudBalancer/internal/load_balancer/backend"
+	"CloudBalancer/internal/metrics"
+	"CloudBalancer/internal/proxyproto"
+	"CloudBalancer/internal/requestid"
+	"CloudBalancer/internal/retry"
+	"CloudBalancer/internal/routing"
+	"CloudBalancer/internal/tlsconfig"
 
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 )
 
 type LoadBalancer interface {
-	GetNextBackend() (*backend.Backend, error)
+	GetNextBackend(pool string) (*backend.Backend, error)
+	AcquireBackend(ctx context.Context, pool string) (*backend.Backend, error)
 	HealthCheck(ctx context.Context)
 	GetBackends() []*backend.Backend
+	GetPools() []string
 	GetStrategy() algorithm.Strategy
 	SetStrategy(strategy algorithm.Strategy)
+	GetCanaryWeight(pool string) (float64, error)
+	SetCanaryWeight(pool string, weight float64) error
+	GetDeployment(pool string) (DeploymentStatus, error)
+	SwitchDeployment(pool, group string, autoRollback bool) error
+	GetBackend(id string) (*backend.Backend, config.BackendConfig, error)
+	AddBackend(cfg config.BackendConfig) (*backend.Backend, error)
+	UpdateBackend(cfg config.BackendConfig) (*backend.Backend, error)
+	RemoveBackend(id string) error
+	ReplaceBackends(cfgs []config.BackendConfig) (BackendChanges, error)
 }
 
+const (
+	CanaryGroup = "canary"
+
+	healthCheckSchedulerTick = time.Second
+)
+
+var (
+	ErrUnknownPool    = errors.New("unknown backend pool")
+	ErrUnknownBackend = errors.New("unknown backend")
+	ErrBackendExists  = errors.New("backend already exists")
+	ErrLastBackend    = errors.New("cannot remove the last backend")
+)
+
 type loadBalancer struct {
-	backends    []*backend.Backend
-	strategy    algorithm.Strategy
-	mu          sync.RWMutex
-	logger      *zap.Logger
-	config      *config.Config
-	healthCheck *http.Client
+	backends       []*backend.Backend
+	backendConfigs map[string]config.BackendConfig
+	pools          map[string]*pool
+	queue          *requestQueue
+	strategy       algorithm.Strategy
+	mu             sync.RWMutex
+	logger         *zap.Logger
+	config         *config.Config
+	ipResolver     *clientip.Resolver
+	backendTLS     *tlsconfig.ClientConfig
+	errorPages     *errorpage.Pages
+	events         *events.Bus
+	metrics        lbMetrics
 }
 
-func NewLoadBalancer(config *config.Config, logger *zap.Logger) (LoadBalancer, error) {
+func NewLoadBalancer(config *config.Config, ipResolver *clientip.Resolver, backendTLS *tlsconfig.ClientConfig, errorPages *errorpage.Pages, bus *events.Bus, registry *metrics.Registry, logger *zap.Logger) (LoadBalancer, error) {
 	strategy, err := algorithm.GetStrategy(config.LoadBalancer.Method)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create balancing strategy: %w", err)
 	}
 
 	lb := &loadBalancer{
-		strategy: strategy,
-		logger:   logger,
-		config:   config,
-		healthCheck: &http.Client{
-			Timeout: 5 * time.Second,
-			Transport: &http.Transport{
-				DialContext: (&net.Dialer{
-					Timeout:   3 * time.Second,
-					KeepAlive: 30 * time.Second,
-				}).DialContext,
-				MaxIdleConns:          100,
-				IdleConnTimeout:       90 * time.Second,
-				TLSHandshakeTimeout:   10 * time.Second,
-				ExpectContinueTimeout: 1 * time.Second,
-			},
-		},
+		pools:      make(map[string]*pool),
+		queue:      newRequestQueue(config.LoadBalancer.Queue.MaxDepth, config.LoadBalancer.Queue.Timeout),
+		strategy:   strategy,
+		logger:     logger,
+		config:     config,
+		ipResolver: ipResolver,
+		backendTLS: backendTLS,
+		errorPages: errorPages,
+		events:     bus,
 	}
 
+	lb.registerMetrics(registry)
+
 	for _, backendConfig := range config.Backends {
 		if !backendConfig.Enabled {
 			continue
 		}
 
-		backendURL, err := url.Parse(fmt.Sprintf("http://%s:%d", backendConfig.Host, backendConfig.Port))
+		b, err := lb.newBackend(backendConfig)
 		if err != nil {
-			return nil, fmt.Errorf("invalid backend URL: %w", err)
+			return nil, err
 		}
 
-		transport := createTransport(backendConfig.ConnectTimeout, backendConfig.ReadTimeout)
-
-		proxy := httputil.NewSingleHostReverseProxy(backendURL)
-		proxy.Transport = transport
-
-		setupDirector(proxy, backendConfig.ID)
-
-		setupErrorHandler(proxy, backendConfig.ID, logger)
-
-		b := backend.NewBackend(
-			backendConfig.ID,
-			backendURL,
-			proxy,
-		)
-
-		lb.backends = append(lb.backends, b)
+		if err := lb.register(b, backendConfig); err != nil {
+			return nil, err
+		}
 	}
 
 	if len(lb.backends) == 0 {
@@ -96,11 +125,155 @@ func NewLoadBalancer(config *config.Config, logger *zap.Logger) (LoadBalancer, e
 	logger.Info("Load balancer initialized",
 		zap.String("strategy", strategy.Name()),
 		zap.Int("backends", len(lb.backends)),
+		zap.Strings("pools", lb.GetPools()),
+		zap.Float64("canaryWeight", config.LoadBalancer.Canary.Weight),
+		zap.String("activeGroup", config.LoadBalancer.BlueGreen.ActiveGroup),
 	)
 
 	return lb, nil
 }
 
+func (lb *loadBalancer) newBackend(backendConfig config.BackendConfig) (*backend.Backend, error) {
+	cfg := lb.config
+
+	scheme := "http"
+	if backendConfig.Protocol == "https" {
+		scheme = "https"
+	}
+
+	backendURL, err := url.Parse(scheme + "://" + net.JoinHostPort(backendConfig.Host, strconv.Itoa(backendConfig.Port)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid backend URL: %w", err)
+	}
+
+	hc := cfg.HealthCheck.ForBackend(backendConfig.HealthCheck)
+	if err := config.ValidateHealthCheckTiming(hc); err != nil {
+		return nil, fmt.Errorf("backend %s: %w", backendConfig.ID, err)
+	}
+
+	healthURL, err := buildHealthURL(backendConfig, scheme, hc.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backend health check URL: %w", err)
+	}
+
+	transport := createTransport(backendConfig.ConnectTimeout, backendConfig.ReadTimeout)
+	switch backendConfig.Protocol {
+	case "h2c":
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	case "https":
+		transport.TLSClientConfig = lb.backendTLS.ForHost(backendConfig.Host)
+	}
+	if version := proxyProtocolVersion(backendConfig.ProxyProtocol); version > 0 {
+		transport.DialContext = proxyproto.NewDialer(transport.DialContext, version)
+		transport.DisableKeepAlives = true
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(backendURL)
+	proxy.Transport = transport
+	proxy.FlushInterval = cfg.LoadBalancer.FlushInterval
+
+	requestHeaders := headers.Pipeline{
+		headers.ForwardedHeaders(lb.ipResolver),
+		headers.IdentityHeaders,
+		headers.NewRules(cfg.Headers.Request),
+		routeRequestHeaders,
+		headers.NewRules(backendConfig.Headers.Request),
+		headers.HopByHopHeaders,
+		headers.Via(cfg.Server.Via),
+	}
+	responseHeaders := headers.Pipeline{
+		dropBackendRequestID,
+		headers.NewRules(cfg.Headers.Response),
+		routeResponseHeaders,
+		headers.NewRules(backendConfig.Headers.Response),
+		headers.HopByHopHeaders,
+		headers.Via(cfg.Server.Via),
+	}
+	if cfg.Server.IdentificationHeaders {
+		responseHeaders = append(responseHeaders, headers.ServedBy)
+	}
+
+	setupDirector(proxy, backendConfig.ID, requestHeaders)
+
+	setupModifyResponse(proxy, backendConfig.ID, responseHeaders)
+
+	setupErrorHandler(proxy, backendConfig.ID, lb.errorPages, lb.logger)
+
+	b := backend.NewBackend(
+		backendConfig.ID,
+		backendURL,
+		healthURL,
+		proxy,
+	)
+	b.Group = backendConfig.Group
+	b.Pool = backendConfig.Pool
+	b.WebSocketIdleTimeout = cfg.LoadBalancer.WebSocketIdleTimeout
+	b.SendProxyProtocol = backendConfig.ProxyProtocol != ""
+	b.MaxConnections = int64(backendConfig.MaxConnection)
+	b.OnRelease = lb.queue.notify
+	b.HealthTransport = lb.newHealthCheckTransport(backendConfig, healthURL)
+	if limit := backendConfig.RateLimit; limit.Rate > 0 {
+		burst := limit.Burst
+		if burst == 0 {
+			burst = max(int(math.Ceil(limit.Rate)), 1)
+		}
+		b.RateLimiter = rate.NewLimiter(rate.Limit(limit.Rate), burst)
+	}
+
+	return b, nil
+}
+
+func (lb *loadBalancer) poolFor(name string) (*pool, error) {
+	if p, ok := lb.pools[name]; ok {
+		return p, nil
+	}
+
+	p, err := newPool(name, lb.config, lb.logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.setStrategy(lb.strategy.Name()); err != nil {
+		return nil, err
+	}
+	lb.pools[name] = p
+	return p, nil
+}
+
+func buildHealthURL(backendConfig config.BackendConfig, scheme, path string) (*url.URL, error) {
+	host := backendConfig.Host
+	if backendConfig.HealthCheck.Host != "" {
+		host = backendConfig.HealthCheck.Host
+	}
+
+	port := backendConfig.Port
+	if backendConfig.HealthCheck.Port != 0 {
+		port = backendConfig.HealthCheck.Port
+	}
+
+	return url.Parse(scheme + "://" + net.JoinHostPort(host, strconv.Itoa(port)) + path)
+}
+
+func (lb *loadBalancer) newHealthCheckTransport(backendConfig config.BackendConfig, healthURL *url.URL) *http.Transport {
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if backendConfig.Protocol == "https" {
+		transport.TLSClientConfig = lb.backendTLS.ForHost(healthURL.Hostname())
+	}
+	if version := proxyProtocolVersion(backendConfig.ProxyProtocol); version > 0 {
+		transport.DialContext = proxyproto.NewDialer(transport.DialContext, version)
+		transport.DisableKeepAlives = true
+	}
+	return transport
+}
+
 func createTransport(connectTimeout, readTimeout time.Duration) *http.Transport {
 	return &http.Transport{
 		DialContext: (&net.Dialer{
@@ -115,44 +288,130 @@ func createTransport(connectTimeout, readTimeout time.Duration) *http.Transport
 	}
 }
 
-func setupDirector(proxy *httputil.ReverseProxy, backendID string) {
+func proxyProtocolVersion(value string) int {
+	switch value {
+	case "v1":
+		return 1
+	case "v2":
+		return 2
+	default:
+		return 0
+	}
+}
+
+var routeRequestHeaders = headers.TransformerFunc(func(h http.Header, ctx *headers.Context) {
+	if route := routing.RouteFromContext(ctx.Request.Context()); route != nil {
+		route.RequestHeaders.Transform(h, ctx)
+	}
+})
+
+var routeResponseHeaders = headers.TransformerFunc(func(h http.Header, ctx *headers.Context) {
+	if route := routing.RouteFromContext(ctx.Request.Context()); route != nil {
+		route.ResponseHeaders.Transform(h, ctx)
+	}
+})
+
+var dropBackendRequestID = headers.TransformerFunc(func(h http.Header, _ *headers.Context) {
+	h.Del(requestid.Header)
+})
+
+func setupDirector(proxy *httputil.ReverseProxy, backendID string, requestHeaders headers.Pipeline) {
 	originalDirector := proxy.Director
 	proxy.Director = func(req *http.Request) {
 		originalDirector(req)
 
-		req.Header.Set("X-Forwarded-Host", req.Host)
-		req.Header.Set("X-Forwarded-For", req.RemoteAddr)
-		req.Header.Set("X-Forwarded-Proto", "http")
+		requestHeaders.Transform(req.Header, &headers.Context{Request: req, BackendID: backendID})
+	}
+}
 
-		req.Header.Set("X-Load-Balancer", "CloudBalancer")
-		req.Header.Set("X-Backend", backendID)
+func setupModifyResponse(proxy *httputil.ReverseProxy, backendID string, responseHeaders headers.Pipeline) {
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		responseHeaders.Transform(resp.Header, &headers.Context{Request: resp.Request, Response: resp, BackendID: backendID})
+		return nil
 	}
 }
 
-func setupErrorHandler(proxy *httputil.ReverseProxy, backendID string, logger *zap.Logger) {
+func setupErrorHandler(proxy *httputil.ReverseProxy, backendID string, errorPages *errorpage.Pages, logger *zap.Logger) {
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if errors.Is(context.Cause(r.Context()), backend.ErrHedgeCanceled) {
+			return
+		}
+		if cause := context.Cause(r.Context()); errors.Is(cause, retry.ErrPerTryTimeout) {
+			err = cause
+		}
+
+		if attempt := backend.AttemptFromContext(r.Context()); attempt != nil && isRetryableError(r, err) {
+			logger.Warn("Proxy error, request will be retried",
+				requestid.Field(r.Context()),
+				zap.String("backend", backendID),
+				zap.String("path", r.URL.Path),
+				zap.Error(err),
+			)
+			attempt.Err = err
+			return
+		}
+
 		logger.Error("Proxy error",
+			requestid.Field(r.Context()),
 			zap.String("backend", backendID),
 			zap.String("path", r.URL.Path),
 			zap.Error(err),
 		)
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadGateway)
-		w.Write([]byte(`{"error": "Backend server error"}`))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			errorPages.Write(w, r, http.StatusRequestEntityTooLarge, errorpage.CodeBodyTooLarge, "Request body too large")
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			errorPages.Write(w, r, http.StatusGatewayTimeout, errorpage.CodeUpstreamTimeout, "Backend request timed out")
+			return
+		}
+		errorPages.Write(w, r, http.StatusBadGateway, errorpage.CodeUpstreamError, "Backend server error")
+	}
+}
+
+func isRetryableError(r *http.Request, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return false
+	}
+	if errors.Is(context.Cause(r.Context()), retry.ErrPerTryTimeout) {
+		return true
 	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
 }
 
-func (lb *loadBalancer) GetNextBackend() (*backend.Backend, error) {
+func (lb *loadBalancer) getPool(name string) (*pool, error) {
 	lb.mu.RLock()
 	defer lb.mu.RUnlock()
 
-	b, err := lb.strategy.NextBackend(lb.backends)
+	p, ok := lb.pools[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPool, name)
+	}
+	return p, nil
+}
+
+func (lb *loadBalancer) GetNextBackend(poolName string) (*backend.Backend, error) {
+	p, err := lb.getPool(poolName)
 	if err != nil {
 		return nil, err
 	}
+	return p.nextBackend()
+}
 
-	return b, nil
+func (lb *loadBalancer) AcquireBackend(ctx context.Context, poolName string) (*backend.Backend, error) {
+	b, err := lb.GetNextBackend(poolName)
+	queueable := errors.Is(err, backend.ErrAtCapacity) ||
+		errors.Is(err, backend.ErrRateLimited) && lb.config.LoadBalancer.BackendRateLimit.OnExceeded == "queue"
+	if !queueable {
+		return b, err
+	}
+
+	return lb.queue.wait(ctx, func() (*backend.Backend, error) {
+		return lb.GetNextBackend(poolName)
+	})
 }
 
 func (lb *loadBalancer) GetBackends() []*backend.Backend {
@@ -165,6 +424,19 @@ func (lb *loadBalancer) GetBackends() []*backend.Backend {
 	return backends
 }
 
+func (lb *loadBalancer) GetPools() []string {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	names := make([]string, 0, len(lb.pools))
+	for name := range lb.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
 func (lb *loadBalancer) GetStrategy() algorithm.Strategy {
 	lb.mu.RLock()
 	defer lb.mu.RUnlock()
@@ -175,69 +447,163 @@ func (lb *loadBalancer) SetStrategy(strategy algorithm.Strategy) {
 	lb.mu.Lock()
 	defer lb.mu.Unlock()
 	lb.strategy = strategy
+	for _, p := range lb.pools {
+		if err := p.setStrategy(strategy.Name()); err != nil {
+			lb.logger.Error("Failed to change pool strategy",
+				zap.String("pool", p.name),
+				zap.Error(err),
+			)
+		}
+	}
 	lb.logger.Info("Load balancing strategy changed", zap.String("strategy", strategy.Name()))
 }
 
-func (lb *loadBalancer) startHealthCheck() {
-	ticker := time.NewTicker(lb.config.LoadBalancer.HealthCheckInterval)
-	defer ticker.Stop()
+func (lb *loadBalancer) GetCanaryWeight(poolName string) (float64, error) {
+	p, err := lb.getPool(poolName)
+	if err != nil {
+		return 0, err
+	}
+	return p.getCanaryWeight(), nil
+}
+
+func (lb *loadBalancer) SetCanaryWeight(poolName string, weight float64) error {
+	p, err := lb.getPool(poolName)
+	if err != nil {
+		return err
+	}
+	return p.setCanaryWeight(weight)
+}
+
+func (lb *loadBalancer) GetDeployment(poolName string) (DeploymentStatus, error) {
+	p, err := lb.getPool(poolName)
+	if err != nil {
+		return DeploymentStatus{}, err
+	}
+	return p.getDeployment(), nil
+}
 
-	lb.HealthCheck(context.Background())
+func (lb *loadBalancer) SwitchDeployment(poolName, group string, autoRollback bool) error {
+	p, err := lb.getPool(poolName)
+	if err != nil {
+		return err
+	}
+	return p.switchDeployment(group, autoRollback)
+}
 
-	for range ticker.C {
-		lb.HealthCheck(context.Background())
+func (lb *loadBalancer) startHealthCheck() {
+	due := make(map[string]time.Time)
+	for {
+		now := time.Now()
+		wait := healthCheckSchedulerTick
+		active := make(map[string]time.Time, len(due))
+		for _, b := range lb.GetBackends() {
+			next, ok := due[b.ID]
+			if !ok || !now.Before(next) {
+				go lb.checkBackendHealth(context.Background(), b)
+				next = now.Add(lb.healthCheckConfig(b.ID).Interval)
+			}
+			active[b.ID] = next
+			wait = min(wait, next.Sub(now))
+		}
+		due = active
+		time.Sleep(wait)
 	}
 }
 
 func (lb *loadBalancer) HealthCheck(ctx context.Context) {
-	for _, b := range lb.backends {
+	for _, b := range lb.GetBackends() {
 		go lb.checkBackendHealth(ctx, b)
 	}
 }
 
+func (lb *loadBalancer) healthCheckConfig(id string) config.HealthCheckConfig {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+	return lb.config.HealthCheck.ForBackend(lb.backendConfigs[id].HealthCheck)
+}
+
 func (lb *loadBalancer) checkBackendHealth(ctx context.Context, b *backend.Backend) {
-	healthURL := fmt.Sprintf("%s/health", b.URL.String())
-	req, err := http.NewRequestWithContext(ctx, "GET", healthURL, nil)
-	if err != nil {
-		lb.logger.Error("Failed to create health check request",
-			zap.String("backend", b.ID),
-			zap.Error(err),
-		)
+	if !b.BeginHealthCheck() {
 		return
 	}
+	defer b.EndHealthCheck()
 
-	resp, err := lb.healthCheck.Do(req)
+	hc := lb.healthCheckConfig(b.ID)
+	ctx, cancel := context.WithTimeout(ctx, hc.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	statusCode, err := lb.probeBackend(ctx, b, hc)
+	lb.metrics.healthCheckDuration.Observe(time.Since(start).Seconds(), b.Pool, b.ID)
+
+	success := err == nil
 	if err != nil {
+		lb.metrics.healthCheckFailures.Inc(b.Pool, b.ID, "connection")
 		lb.logger.Warn("Health check connection failed",
 			zap.String("backend", b.ID),
 			zap.Error(err),
 		)
-		wasHealthy := b.IsHealthy()
-		b.SetHealthy(false)
-
-		if wasHealthy {
-			lb.logger.Warn("Backend became unhealthy due to connection error",
-				zap.String("backend", b.ID),
-			)
+	} else if hc.Type == "http" {
+		expected, _ := config.ParseExpectedStatuses(hc.ExpectedStatuses)
+		if success = config.StatusExpected(expected, statusCode); !success {
+			lb.metrics.healthCheckFailures.Inc(b.Pool, b.ID, "status")
 		}
+	}
+
+	isHealthy, changed := b.RecordHealthCheck(success, hc.Rise, hc.Fall)
+	if !changed {
 		return
 	}
-	defer resp.Body.Close()
 
-	isHealthy := resp.StatusCode == http.StatusOK
-	wasHealthy := b.IsHealthy()
-	b.SetHealthy(isHealthy)
+	switch {
+	case isHealthy:
+		lb.logger.Info("Backend became healthy",
+			zap.String("backend", b.ID),
+		)
+		lb.events.Publish(events.BackendUp, map[string]any{
+			"backend": b.ID,
+			"pool":    b.Pool,
+		})
+	case err != nil:
+		lb.logger.Warn("Backend became unhealthy due to connection error",
+			zap.String("backend", b.ID),
+		)
+		lb.events.Publish(events.BackendDown, map[string]any{
+			"backend": b.ID,
+			"pool":    b.Pool,
+			"reason":  err.Error(),
+		})
+	default:
+		lb.logger.Warn("Backend became unhealthy",
+			zap.String("backend", b.ID),
+			zap.Int("status_code", statusCode),
+		)
+		lb.events.Publish(events.BackendDown, map[string]any{
+			"backend":     b.ID,
+			"pool":        b.Pool,
+			"status_code": statusCode,
+		})
+	}
+}
 
-	if wasHealthy != isHealthy {
-		if isHealthy {
-			lb.logger.Info("Backend became healthy",
-				zap.String("backend", b.ID),
-			)
-		} else {
-			lb.logger.Warn("Backend became unhealthy",
-				zap.String("backend", b.ID),
-				zap.Int("status_code", resp.StatusCode),
-			)
+func (lb *loadBalancer) probeBackend(ctx context.Context, b *backend.Backend, hc config.HealthCheckConfig) (int, error) {
+	if hc.Type == "tcp" {
+		conn, err := b.HealthTransport.DialContext(ctx, "tcp", b.HealthURL.Host)
+		if err != nil {
+			return 0, err
 		}
+		return 0, conn.Close()
 	}
+
+	req, err := http.NewRequestWithContext(ctx, hc.Method, b.HealthURL.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := (&http.Client{Transport: b.HealthTransport}).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
 }
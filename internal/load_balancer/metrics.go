@@ -0,0 +1,77 @@
+package load_balancer
+
+import (
+	"CloudBalancer/internal/load_balancer/backend"
+	"CloudBalancer/internal/metrics"
+)
+
+type lbMetrics struct {
+	healthCheckDuration *metrics.HistogramVec
+	healthCheckFailures *metrics.CounterVec
+}
+
+func (lb *loadBalancer) registerMetrics(registry *metrics.Registry) {
+	labels := []string{"pool", "backend"}
+
+	lb.metrics = lbMetrics{
+		healthCheckDuration: registry.NewHistogram("cloudbalancer_health_check_duration_seconds",
+			"Duration of backend health check probes.", metrics.DefaultBuckets, labels...),
+		healthCheckFailures: registry.NewCounter("cloudbalancer_health_check_failures_total",
+			"Failed backend health check probes by reason (connection or status).", "pool", "backend", "reason"),
+	}
+
+	registry.NewGaugeFunc("cloudbalancer_backend_up", "Whether the backend is passing health checks.", labels,
+		func(emit func(float64, ...string)) {
+			for _, b := range lb.GetBackends() {
+				emit(boolValue(b.IsHealthy()), b.Pool, b.ID)
+			}
+		})
+	registry.NewGaugeFunc("cloudbalancer_backend_draining", "Whether the backend is draining.", labels,
+		func(emit func(float64, ...string)) {
+			for _, b := range lb.GetBackends() {
+				emit(boolValue(b.IsDraining()), b.Pool, b.ID)
+			}
+		})
+	registry.NewGaugeFunc("cloudbalancer_backend_active_connections", "Requests currently in flight to the backend.", labels,
+		func(emit func(float64, ...string)) {
+			for _, b := range lb.GetBackends() {
+				emit(float64(b.ActiveConnections()), b.Pool, b.ID)
+			}
+		})
+	registry.NewCounterFunc("cloudbalancer_backend_requests_total", "Requests proxied to the backend by status class.", append(labels, "code"),
+		func(emit func(float64, ...string)) {
+			for _, b := range lb.GetBackends() {
+				total := b.Stats.Total()
+				emit(float64(total.Status2xx), b.Pool, b.ID, "2xx")
+				emit(float64(total.Status3xx), b.Pool, b.ID, "3xx")
+				emit(float64(total.Status4xx), b.Pool, b.ID, "4xx")
+				emit(float64(total.Status5xx), b.Pool, b.ID, "5xx")
+			}
+		})
+	registry.NewCounterFunc("cloudbalancer_backend_failed_requests_total", "Requests to the backend that failed at the transport level.", labels,
+		func(emit func(float64, ...string)) {
+			for _, b := range lb.GetBackends() {
+				emit(float64(b.Stats.Total().Failed), b.Pool, b.ID)
+			}
+		})
+
+	bounds := backend.LatencyBounds()
+	seconds := make([]float64, len(bounds))
+	for i, bound := range bounds {
+		seconds[i] = bound.Seconds()
+	}
+	registry.NewHistogramFunc("cloudbalancer_backend_request_duration_seconds", "Latency of proxied requests per backend, excluding WebSocket sessions.", seconds, labels,
+		func(emit func(metrics.Sample)) {
+			for _, b := range lb.GetBackends() {
+				buckets, count, sum := b.Stats.LatencyHistogram()
+				emit(metrics.Sample{LabelValues: []string{b.Pool, b.ID}, Buckets: buckets, Count: count, Sum: sum.Seconds()})
+			}
+		})
+}
+
+func boolValue(v bool) float64 {
+	if v {
+		return 1
+	}
+	return 0
+}
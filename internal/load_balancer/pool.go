@@ -0,0 +1,101 @@
+package load_balancer
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/load_balancer/algorithm"
+	"CloudBalancer/internal/load_balancer/backend"
+
+	"go.uber.org/zap"
+)
+
+type pool struct {
+	name           string
+	backends       []*backend.Backend
+	stableBackends []*backend.Backend
+	canaryBackends []*backend.Backend
+	strategy       algorithm.Strategy
+	canaryStrategy algorithm.Strategy
+	canaryWeight   float64
+	activeGroup    string
+	deployment     *deployment
+	blueGreen      config.BlueGreenConfig
+	mu             sync.RWMutex
+	logger         *zap.Logger
+}
+
+func newPool(name string, cfg *config.Config, logger *zap.Logger) (*pool, error) {
+	p := &pool{
+		name:         name,
+		canaryWeight: cfg.LoadBalancer.Canary.Weight,
+		activeGroup:  cfg.LoadBalancer.BlueGreen.ActiveGroup,
+		blueGreen:    cfg.LoadBalancer.BlueGreen,
+		logger:       logger.With(zap.String("pool", name)),
+	}
+
+	if err := p.setStrategy(cfg.LoadBalancer.Method); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *pool) setStrategy(method string) error {
+	strategy, err := algorithm.GetStrategy(method)
+	if err != nil {
+		return fmt.Errorf("failed to create balancing strategy: %w", err)
+	}
+
+	canaryStrategy, err := algorithm.GetStrategy(method)
+	if err != nil {
+		return fmt.Errorf("failed to create balancing strategy: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.strategy = strategy
+	p.canaryStrategy = canaryStrategy
+	return nil
+}
+
+func (p *pool) nextBackend() (*backend.Backend, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.canaryBackends) > 0 && rand.Float64()*100 < p.canaryWeight {
+		if b, err := p.canaryStrategy.NextBackend(p.canaryBackends); err == nil {
+			return b, nil
+		}
+	}
+
+	b, err := p.strategy.NextBackend(p.stableBackends)
+	if err != nil {
+		if len(p.canaryBackends) == 0 {
+			return nil, err
+		}
+		return p.canaryStrategy.NextBackend(p.canaryBackends)
+	}
+
+	return b, nil
+}
+
+func (p *pool) getCanaryWeight() float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.canaryWeight
+}
+
+func (p *pool) setCanaryWeight(weight float64) error {
+	if weight < 0 || weight > 100 {
+		return fmt.Errorf("canary weight must be between 0 and 100, got %g", weight)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.canaryWeight = weight
+	p.logger.Info("Canary weight changed", zap.Float64("weight", weight))
+	return nil
+}
@@ -0,0 +1,124 @@
+package load_balancer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"CloudBalancer/internal/load_balancer/backend"
+)
+
+var (
+	ErrQueueFull    = errors.New("request queue is full")
+	ErrQueueTimeout = errors.New("timed out waiting for an available backend")
+)
+
+const rateLimitPollInterval = 10 * time.Millisecond
+
+type QueueError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *QueueError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *QueueError) Unwrap() error {
+	return e.Err
+}
+
+type requestQueue struct {
+	maxDepth int
+	timeout  time.Duration
+	waiting  int
+	ready    chan struct{}
+	avgWait  time.Duration
+	mu       sync.Mutex
+}
+
+func newRequestQueue(maxDepth int, timeout time.Duration) *requestQueue {
+	return &requestQueue{
+		maxDepth: maxDepth,
+		timeout:  timeout,
+		ready:    make(chan struct{}),
+	}
+}
+
+func (q *requestQueue) notify() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.waiting > 0 {
+		close(q.ready)
+		q.ready = make(chan struct{})
+	}
+}
+
+func (q *requestQueue) wait(ctx context.Context, next func() (*backend.Backend, error)) (*backend.Backend, error) {
+	q.mu.Lock()
+	if q.waiting >= q.maxDepth {
+		q.mu.Unlock()
+		return nil, &QueueError{Err: ErrQueueFull, RetryAfter: q.retryAfter()}
+	}
+	q.waiting++
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		q.waiting--
+		q.mu.Unlock()
+	}()
+
+	start := time.Now()
+	timer := time.NewTimer(q.timeout)
+	defer timer.Stop()
+
+	for {
+		q.mu.Lock()
+		ready := q.ready
+		q.mu.Unlock()
+
+		b, err := next()
+		if !errors.Is(err, backend.ErrAtCapacity) && !errors.Is(err, backend.ErrRateLimited) {
+			q.observe(time.Since(start))
+			return b, err
+		}
+
+		var poll <-chan time.Time
+		if errors.Is(err, backend.ErrRateLimited) {
+			poll = time.After(rateLimitPollInterval)
+		}
+
+		select {
+		case <-poll:
+		case <-ready:
+		case <-timer.C:
+			return nil, &QueueError{Err: ErrQueueTimeout, RetryAfter: q.retryAfter()}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (q *requestQueue) observe(wait time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.avgWait == 0 {
+		q.avgWait = wait
+		return
+	}
+	q.avgWait = (q.avgWait*7 + wait) / 8
+}
+
+func (q *requestQueue) retryAfter() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.avgWait == 0 {
+		return q.timeout
+	}
+	return q.avgWait
+}
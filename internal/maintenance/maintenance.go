@@ -0,0 +1,90 @@
+package maintenance
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+)
+
+type State struct {
+	Enabled    bool     `json:"enabled"`
+	Pools      []string `json:"pools,omitempty"`
+	Paths      []string `json:"paths,omitempty"`
+	Message    string   `json:"message"`
+	RetryAfter int      `json:"retry_after"`
+}
+
+type Mode struct {
+	state State
+	mu    sync.RWMutex
+}
+
+func NewMode(cfg config.MaintenanceConfig) *Mode {
+	return &Mode{
+		state: State{
+			Enabled:    cfg.Enabled,
+			Pools:      cfg.Pools,
+			Paths:      cfg.Paths,
+			Message:    cfg.Message,
+			RetryAfter: int(cfg.RetryAfter / time.Second),
+		},
+	}
+}
+
+func (m *Mode) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+func (s State) Validate() error {
+	if s.RetryAfter < 0 {
+		return fmt.Errorf("retry after must not be negative, got %d", s.RetryAfter)
+	}
+	for _, path := range s.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("maintenance path must start with '/', got %q", path)
+		}
+	}
+	return nil
+}
+
+func (m *Mode) SetState(state State) error {
+	if err := state.Validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state.Message == "" {
+		state.Message = m.state.Message
+	}
+	m.state = state
+	return nil
+}
+
+func (m *Mode) Applies(pool, path string) (State, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state := m.state
+	if !state.Enabled {
+		return state, false
+	}
+	if len(state.Pools) == 0 && len(state.Paths) == 0 {
+		return state, true
+	}
+	if slices.Contains(state.Pools, pool) {
+		return state, true
+	}
+	for _, prefix := range state.Paths {
+		if strings.HasPrefix(path, prefix) {
+			return state, true
+		}
+	}
+	return state, false
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+
+	"go.uber.org/zap"
+)
+
+type Exporter interface {
+	Name() string
+	Export(ctx context.Context, families []Family) error
+	Close() error
+}
+
+func NewExporter(cfg config.MetricsConfig) (Exporter, error) {
+	switch cfg.Exporter {
+	case "none":
+		return nil, nil
+	case "otlp":
+		return NewOTLPExporter(cfg.OTLP), nil
+	case "statsd":
+		return NewStatsDExporter(cfg.StatsD)
+	default:
+		return nil, fmt.Errorf("unknown metrics exporter: %s", cfg.Exporter)
+	}
+}
+
+type Pusher struct {
+	registry *Registry
+	exporter Exporter
+	interval time.Duration
+	logger   *zap.Logger
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+}
+
+func NewPusher(registry *Registry, exporter Exporter, interval time.Duration, logger *zap.Logger) *Pusher {
+	p := &Pusher{
+		registry: registry,
+		exporter: exporter,
+		interval: interval,
+		logger:   logger,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+func (p *Pusher) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.push()
+		case <-p.stop:
+			p.push()
+			return
+		}
+	}
+}
+
+func (p *Pusher) push() {
+	ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+	defer cancel()
+
+	if err := p.exporter.Export(ctx, p.registry.Gather()); err != nil {
+		p.logger.Warn("Failed to export metrics",
+			zap.String("exporter", p.exporter.Name()),
+			zap.Error(err),
+		)
+	}
+}
+
+func (p *Pusher) Close() error {
+	p.once.Do(func() {
+		close(p.stop)
+	})
+	<-p.done
+	return p.exporter.Close()
+}
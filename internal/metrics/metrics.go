@@ -0,0 +1,245 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Kind int
+
+const (
+	KindCounter Kind = iota
+	KindGauge
+	KindHistogram
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindCounter:
+		return "counter"
+	case KindGauge:
+		return "gauge"
+	default:
+		return "histogram"
+	}
+}
+
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+type Sample struct {
+	LabelValues []string
+	Value       float64
+	Buckets     []uint64
+	Sum         float64
+	Count       uint64
+}
+
+type Family struct {
+	Name       string
+	Help       string
+	Kind       Kind
+	LabelNames []string
+	Bounds     []float64
+	Samples    []Sample
+}
+
+type collector interface {
+	collect() Family
+}
+
+type Registry struct {
+	collectors map[string]collector
+	mu         sync.Mutex
+}
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.collectors[name]; ok {
+		panic(fmt.Sprintf("metric %s registered twice", name))
+	}
+	r.collectors[name] = c
+}
+
+func (r *Registry) Gather() []Family {
+	r.mu.Lock()
+	collectors := make([]collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.Unlock()
+
+	families := make([]Family, 0, len(collectors))
+	for _, c := range collectors {
+		family := c.collect()
+		sort.Slice(family.Samples, func(i, j int) bool {
+			return strings.Join(family.Samples[i].LabelValues, "\xff") < strings.Join(family.Samples[j].LabelValues, "\xff")
+		})
+		families = append(families, family)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+	return families
+}
+
+type desc struct {
+	name   string
+	help   string
+	kind   Kind
+	labels []string
+	bounds []float64
+}
+
+func (d desc) family() Family {
+	return Family{Name: d.name, Help: d.help, Kind: d.kind, LabelNames: d.labels, Bounds: d.bounds}
+}
+
+func (d desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+type valueVec struct {
+	desc
+	values map[string]*Sample
+	mu     sync.Mutex
+}
+
+func (v *valueVec) sample(values []string) *Sample {
+	key := v.key(values)
+	s, ok := v.values[key]
+	if !ok {
+		s = &Sample{LabelValues: append([]string(nil), values...)}
+		if v.kind == KindHistogram {
+			s.Buckets = make([]uint64, len(v.bounds))
+		}
+		v.values[key] = s
+	}
+	return s
+}
+
+func (v *valueVec) collect() Family {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	family := v.family()
+	for _, s := range v.values {
+		sample := *s
+		sample.Buckets = append([]uint64(nil), s.Buckets...)
+		family.Samples = append(family.Samples, sample)
+	}
+	return family
+}
+
+type CounterVec struct {
+	vec *valueVec
+}
+
+func (r *Registry) NewCounter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec: &valueVec{desc: desc{name: name, help: help, kind: KindCounter, labels: labels}, values: make(map[string]*Sample)}}
+	r.register(name, c.vec)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.vec.mu.Lock()
+	defer c.vec.mu.Unlock()
+	c.vec.sample(labelValues).Value += delta
+}
+
+type GaugeVec struct {
+	vec *valueVec
+}
+
+func (r *Registry) NewGauge(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{vec: &valueVec{desc: desc{name: name, help: help, kind: KindGauge, labels: labels}, values: make(map[string]*Sample)}}
+	r.register(name, g.vec)
+	return g
+}
+
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.vec.mu.Lock()
+	defer g.vec.mu.Unlock()
+	g.vec.sample(labelValues).Value = value
+}
+
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	g.vec.mu.Lock()
+	defer g.vec.mu.Unlock()
+	g.vec.sample(labelValues).Value += delta
+}
+
+type HistogramVec struct {
+	vec *valueVec
+}
+
+func (r *Registry) NewHistogram(name, help string, bounds []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{vec: &valueVec{desc: desc{name: name, help: help, kind: KindHistogram, labels: labels, bounds: bounds}, values: make(map[string]*Sample)}}
+	r.register(name, h.vec)
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.vec.mu.Lock()
+	defer h.vec.mu.Unlock()
+
+	s := h.vec.sample(labelValues)
+	for i, bound := range h.vec.bounds {
+		if value <= bound {
+			s.Buckets[i]++
+		}
+	}
+	s.Sum += value
+	s.Count++
+}
+
+type funcCollector struct {
+	desc
+	fn func(emit func(Sample))
+}
+
+func (f *funcCollector) collect() Family {
+	family := f.family()
+	f.fn(func(s Sample) {
+		f.key(s.LabelValues)
+		family.Samples = append(family.Samples, s)
+	})
+	return family
+}
+
+func (r *Registry) NewCounterFunc(name, help string, labels []string, fn func(emit func(value float64, labelValues ...string))) {
+	r.register(name, &funcCollector{desc: desc{name: name, help: help, kind: KindCounter, labels: labels}, fn: valueFunc(fn)})
+}
+
+func (r *Registry) NewGaugeFunc(name, help string, labels []string, fn func(emit func(value float64, labelValues ...string))) {
+	r.register(name, &funcCollector{desc: desc{name: name, help: help, kind: KindGauge, labels: labels}, fn: valueFunc(fn)})
+}
+
+func (r *Registry) NewHistogramFunc(name, help string, bounds []float64, labels []string, fn func(emit func(Sample))) {
+	r.register(name, &funcCollector{desc: desc{name: name, help: help, kind: KindHistogram, labels: labels, bounds: bounds}, fn: fn})
+}
+
+func valueFunc(fn func(emit func(value float64, labelValues ...string))) func(emit func(Sample)) {
+	return func(emit func(Sample)) {
+		fn(func(value float64, labelValues ...string) {
+			emit(Sample{LabelValues: labelValues, Value: value})
+		})
+	}
+}
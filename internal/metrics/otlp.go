@@ -0,0 +1,169 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"CloudBalancer/config"
+)
+
+const otlpCumulative = 2
+
+type OTLPExporter struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	started     time.Time
+	client      *http.Client
+}
+
+func NewOTLPExporter(cfg config.OTLPExporterConfig) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint:    cfg.Endpoint,
+		headers:     cfg.Headers,
+		serviceName: cfg.ServiceName,
+		started:     time.Now(),
+		client:      &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+func (e *OTLPExporter) Name() string {
+	return "otlp"
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpDataPoint struct {
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	AsDouble          *float64        `json:"asDouble,omitempty"`
+	Count             string          `json:"count,omitempty"`
+	Sum               *float64        `json:"sum,omitempty"`
+	BucketCounts      []string        `json:"bucketCounts,omitempty"`
+	ExplicitBounds    []float64       `json:"explicitBounds,omitempty"`
+}
+
+type otlpData struct {
+	DataPoints             []otlpDataPoint `json:"dataPoints"`
+	AggregationTemporality int             `json:"aggregationTemporality,omitempty"`
+	IsMonotonic            bool            `json:"isMonotonic,omitempty"`
+}
+
+type otlpMetric struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Sum         *otlpData `json:"sum,omitempty"`
+	Gauge       *otlpData `json:"gauge,omitempty"`
+	Histogram   *otlpData `json:"histogram,omitempty"`
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, families []Family) error {
+	start := strconv.FormatInt(e.started.UnixNano(), 10)
+	now := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	metrics := make([]otlpMetric, 0, len(families))
+	for _, family := range families {
+		data := &otlpData{DataPoints: make([]otlpDataPoint, 0, len(family.Samples))}
+		for _, s := range family.Samples {
+			point := otlpDataPoint{
+				Attributes:        otlpAttributes(family.LabelNames, s.LabelValues),
+				StartTimeUnixNano: start,
+				TimeUnixNano:      now,
+			}
+			if family.Kind == KindHistogram {
+				sum := s.Sum
+				point.Sum = &sum
+				point.Count = strconv.FormatUint(s.Count, 10)
+				point.ExplicitBounds = family.Bounds
+				point.BucketCounts = make([]string, len(s.Buckets)+1)
+				var previous uint64
+				for i, cumulative := range s.Buckets {
+					point.BucketCounts[i] = strconv.FormatUint(cumulative-previous, 10)
+					previous = cumulative
+				}
+				point.BucketCounts[len(s.Buckets)] = strconv.FormatUint(s.Count-previous, 10)
+			} else {
+				value := s.Value
+				point.AsDouble = &value
+			}
+			data.DataPoints = append(data.DataPoints, point)
+		}
+
+		metric := otlpMetric{Name: family.Name, Description: family.Help}
+		switch family.Kind {
+		case KindCounter:
+			data.AggregationTemporality = otlpCumulative
+			data.IsMonotonic = true
+			metric.Sum = data
+		case KindGauge:
+			metric.Gauge = data
+		case KindHistogram:
+			data.AggregationTemporality = otlpCumulative
+			metric.Histogram = data
+		}
+		metrics = append(metrics, metric)
+	}
+
+	payload := map[string]any{
+		"resourceMetrics": []any{map[string]any{
+			"resource": map[string]any{
+				"attributes": otlpAttributes([]string{"service.name"}, []string{e.serviceName}),
+			},
+			"scopeMetrics": []any{map[string]any{
+				"scope":   map[string]any{"name": "CloudBalancer"},
+				"metrics": metrics,
+			}},
+		}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode OTLP metrics: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send OTLP metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("OTLP collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *OTLPExporter) Close() error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+func otlpAttributes(names, values []string) []otlpAttribute {
+	attributes := make([]otlpAttribute, len(names))
+	for i, name := range names {
+		attributes[i].Key = name
+		attributes[i].Value.StringValue = values[i]
+	}
+	return attributes
+}
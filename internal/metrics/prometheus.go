@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func WritePrometheus(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, family := range families {
+		bw.WriteString("# HELP " + family.Name + " " + helpEscaper.Replace(family.Help) + "\n")
+		bw.WriteString("# TYPE " + family.Name + " " + family.Kind.String() + "\n")
+
+		for _, s := range family.Samples {
+			labels := formatLabels(family.LabelNames, s.LabelValues)
+			if family.Kind != KindHistogram {
+				bw.WriteString(family.Name + labels + " " + formatFloat(s.Value) + "\n")
+				continue
+			}
+
+			for i, bound := range family.Bounds {
+				bw.WriteString(family.Name + "_bucket" + withLabel(family.LabelNames, s.LabelValues, "le", formatFloat(bound)) + " " + strconv.FormatUint(s.Buckets[i], 10) + "\n")
+			}
+			bw.WriteString(family.Name + "_bucket" + withLabel(family.LabelNames, s.LabelValues, "le", "+Inf") + " " + strconv.FormatUint(s.Count, 10) + "\n")
+			bw.WriteString(family.Name + "_sum" + labels + " " + formatFloat(s.Sum) + "\n")
+			bw.WriteString(family.Name + "_count" + labels + " " + strconv.FormatUint(s.Count, 10) + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + labelEscaper.Replace(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func withLabel(names, values []string, name, value string) string {
+	return formatLabels(append(append([]string(nil), names...), name), append(append([]string(nil), values...), value))
+}
+
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		w.WriteHeader(http.StatusOK)
+		WritePrometheus(w, r.Gather())
+	})
+}
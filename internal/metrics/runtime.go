@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"CloudBalancer/internal/buildinfo"
+)
+
+const (
+	memStatsMaxAge = time.Second
+	clockTicks     = 100
+)
+
+type memStatsCache struct {
+	stats runtime.MemStats
+	read  time.Time
+	mu    sync.Mutex
+}
+
+func (c *memStatsCache) get() runtime.MemStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.read) > memStatsMaxAge {
+		runtime.ReadMemStats(&c.stats)
+		c.read = time.Now()
+	}
+	return c.stats
+}
+
+func (r *Registry) RegisterRuntime(build buildinfo.Info) {
+	r.NewGaugeFunc("cloudbalancer_build_info", "Build information; always 1.", []string{"version", "commit", "go_version"},
+		func(emit func(float64, ...string)) {
+			emit(1, build.Version, build.Commit, build.GoVersion)
+		})
+
+	memStats := &memStatsCache{}
+	memGauge := func(name, help string, value func(*runtime.MemStats) float64) {
+		r.NewGaugeFunc(name, help, nil, func(emit func(float64, ...string)) {
+			stats := memStats.get()
+			emit(value(&stats))
+		})
+	}
+
+	r.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", nil,
+		func(emit func(float64, ...string)) {
+			emit(float64(runtime.NumGoroutine()))
+		})
+	memGauge("go_memstats_heap_alloc_bytes", "Bytes of allocated heap objects.", func(s *runtime.MemStats) float64 {
+		return float64(s.HeapAlloc)
+	})
+	memGauge("go_memstats_heap_inuse_bytes", "Bytes in in-use heap spans.", func(s *runtime.MemStats) float64 {
+		return float64(s.HeapInuse)
+	})
+	memGauge("go_memstats_heap_objects", "Number of allocated heap objects.", func(s *runtime.MemStats) float64 {
+		return float64(s.HeapObjects)
+	})
+	memGauge("go_memstats_sys_bytes", "Bytes of memory obtained from the OS.", func(s *runtime.MemStats) float64 {
+		return float64(s.Sys)
+	})
+	memGauge("go_memstats_next_gc_bytes", "Heap size target of the next GC cycle.", func(s *runtime.MemStats) float64 {
+		return float64(s.NextGC)
+	})
+	r.NewCounterFunc("go_gc_cycles_total", "Completed GC cycles.", nil,
+		func(emit func(float64, ...string)) {
+			stats := memStats.get()
+			emit(float64(stats.NumGC))
+		})
+	r.NewCounterFunc("go_gc_pause_seconds_total", "Total stop-the-world pause time of GC cycles.", nil,
+		func(emit func(float64, ...string)) {
+			stats := memStats.get()
+			emit(float64(stats.PauseTotalNs) / float64(time.Second))
+		})
+
+	r.NewGaugeFunc("process_start_time_seconds", "Start time of the process since the Unix epoch.", nil,
+		func(emit func(float64, ...string)) {
+			emit(float64(build.StartedAt.UnixNano()) / float64(time.Second))
+		})
+	r.NewGaugeFunc("process_open_fds", "Number of open file descriptors.", nil,
+		func(emit func(float64, ...string)) {
+			if entries, err := os.ReadDir("/proc/self/fd"); err == nil {
+				emit(float64(len(entries)))
+			}
+		})
+	r.NewGaugeFunc("process_max_fds", "Maximum number of open file descriptors.", nil,
+		func(emit func(float64, ...string)) {
+			if limit, ok := maxOpenFiles(); ok {
+				emit(limit)
+			}
+		})
+	r.NewGaugeFunc("process_resident_memory_bytes", "Resident memory size in bytes.", nil,
+		func(emit func(float64, ...string)) {
+			if stat, ok := readProcStat(); ok {
+				emit(stat.rss)
+			}
+		})
+	r.NewGaugeFunc("process_virtual_memory_bytes", "Virtual memory size in bytes.", nil,
+		func(emit func(float64, ...string)) {
+			if stat, ok := readProcStat(); ok {
+				emit(stat.vsize)
+			}
+		})
+	r.NewCounterFunc("process_cpu_seconds_total", "Total user and system CPU time spent in seconds.", nil,
+		func(emit func(float64, ...string)) {
+			if stat, ok := readProcStat(); ok {
+				emit(stat.cpuSeconds)
+			}
+		})
+}
+
+type procStat struct {
+	cpuSeconds float64
+	vsize      float64
+	rss        float64
+}
+
+func readProcStat() (procStat, bool) {
+	data, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return procStat{}, false
+	}
+
+	end := strings.LastIndexByte(string(data), ')')
+	if end < 0 {
+		return procStat{}, false
+	}
+	fields := strings.Fields(string(data[end+1:]))
+	if len(fields) < 22 {
+		return procStat{}, false
+	}
+
+	utime, _ := strconv.ParseFloat(fields[11], 64)
+	stime, _ := strconv.ParseFloat(fields[12], 64)
+	vsize, _ := strconv.ParseFloat(fields[20], 64)
+	rss, _ := strconv.ParseFloat(fields[21], 64)
+	return procStat{
+		cpuSeconds: (utime + stime) / clockTicks,
+		vsize:      vsize,
+		rss:        rss * float64(os.Getpagesize()),
+	}, true
+}
+
+func maxOpenFiles() (float64, bool) {
+	file, err := os.Open("/proc/self/limits")
+	if err != nil {
+		return 0, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Max open files") {
+			continue
+		}
+		fields := strings.Fields(strings.TrimPrefix(line, "Max open files"))
+		if len(fields) == 0 || fields[0] == "unlimited" {
+			return 0, false
+		}
+		limit, err := strconv.ParseFloat(fields[0], 64)
+		return limit, err == nil
+	}
+	return 0, false
+}
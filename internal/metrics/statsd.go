@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"CloudBalancer/config"
+)
+
+const statsDMaxPacket = 1432
+
+var statsDSanitizer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", " ", "_", "\n", "_")
+
+type StatsDExporter struct {
+	conn    net.Conn
+	prefix  string
+	datadog bool
+	tags    []string
+	last    map[string]float64
+}
+
+type statsDLine struct {
+	text  string
+	key   string
+	value float64
+}
+
+func NewStatsDExporter(cfg config.StatsDExporterConfig) (*StatsDExporter, error) {
+	conn, err := net.Dial("udp", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open StatsD connection: %w", err)
+	}
+
+	prefix := cfg.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+
+	tags := make([]string, 0, len(cfg.Tags))
+	for name, value := range cfg.Tags {
+		tags = append(tags, statsDSanitizer.Replace(name)+":"+statsDSanitizer.Replace(value))
+	}
+	sort.Strings(tags)
+
+	return &StatsDExporter{
+		conn:    conn,
+		prefix:  prefix,
+		datadog: cfg.Flavor == "datadog",
+		tags:    tags,
+		last:    make(map[string]float64),
+	}, nil
+}
+
+func (e *StatsDExporter) Name() string {
+	return "statsd"
+}
+
+func (e *StatsDExporter) Export(ctx context.Context, families []Family) error {
+	var lines []statsDLine
+	for _, family := range families {
+		for _, s := range family.Samples {
+			name, tags := e.series(family, s)
+			switch family.Kind {
+			case KindGauge:
+				lines = append(lines, statsDLine{text: e.line(name, s.Value, "g", tags)})
+			case KindCounter:
+				lines = e.appendCounter(lines, name, tags, s.Value)
+			case KindHistogram:
+				lines = e.appendCounter(lines, name+".count", tags, float64(s.Count))
+				lines = e.appendCounter(lines, name+".sum", tags, s.Sum)
+			}
+		}
+	}
+
+	var packet strings.Builder
+	var pending []statsDLine
+	for _, line := range lines {
+		if packet.Len() > 0 && packet.Len()+1+len(line.text) > statsDMaxPacket {
+			if err := e.send(ctx, packet.String(), pending); err != nil {
+				return err
+			}
+			packet.Reset()
+			pending = pending[:0]
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line.text)
+		pending = append(pending, line)
+	}
+	if packet.Len() > 0 {
+		return e.send(ctx, packet.String(), pending)
+	}
+	return nil
+}
+
+func (e *StatsDExporter) appendCounter(lines []statsDLine, name string, tags []string, value float64) []statsDLine {
+	key := name + "|" + strings.Join(tags, ",")
+	previous, ok := e.last[key]
+	delta := value
+	if ok && value >= previous {
+		delta = value - previous
+	}
+	if delta == 0 {
+		return lines
+	}
+	return append(lines, statsDLine{text: e.line(name, delta, "c", tags), key: key, value: value})
+}
+
+func (e *StatsDExporter) series(family Family, s Sample) (string, []string) {
+	name := e.prefix + family.Name
+	if !e.datadog {
+		for _, value := range s.LabelValues {
+			name += "." + statsDSanitizer.Replace(strings.ReplaceAll(value, ".", "_"))
+		}
+		return name, nil
+	}
+
+	tags := append([]string(nil), e.tags...)
+	for i, label := range family.LabelNames {
+		tags = append(tags, label+":"+statsDSanitizer.Replace(s.LabelValues[i]))
+	}
+	return name, tags
+}
+
+func (e *StatsDExporter) line(name string, value float64, kind string, tags []string) string {
+	line := name + ":" + formatFloat(value) + "|" + kind
+	if len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+	return line
+}
+
+func (e *StatsDExporter) send(ctx context.Context, packet string, lines []statsDLine) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		e.conn.SetWriteDeadline(deadline)
+	}
+	if _, err := e.conn.Write([]byte(packet)); err != nil {
+		return fmt.Errorf("failed to send StatsD packet: %w", err)
+	}
+	for _, line := range lines {
+		if line.key != "" {
+			e.last[line.key] = line.value
+		}
+	}
+	return nil
+}
+
+func (e *StatsDExporter) Close() error {
+	return e.conn.Close()
+}
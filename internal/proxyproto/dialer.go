@@ -0,0 +1,93 @@
+package proxyproto
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+type DialContextFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+type sourceAddrKey struct{}
+
+func WithSourceAddr(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, sourceAddrKey{}, addr)
+}
+
+func NewDialer(dial DialContextFunc, version int) DialContextFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		header := &Header{Version: version, Local: true}
+
+		source, _ := ctx.Value(sourceAddrKey{}).(string)
+		if tcpSource, err := net.ResolveTCPAddr("tcp", source); err == nil {
+			header.Local = false
+			header.Source = tcpSource
+			header.Destination, _ = conn.LocalAddr().(*net.TCPAddr)
+			if local, ok := ctx.Value(http.LocalAddrContextKey).(*net.TCPAddr); ok {
+				header.Destination = local
+			}
+			if header.Destination == nil || (header.Source.IP.To4() == nil) != (header.Destination.IP.To4() == nil) {
+				header.Local = true
+			}
+		}
+
+		if _, err := conn.Write(header.Format()); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to write PROXY protocol header: %w", err)
+		}
+
+		return conn, nil
+	}
+}
+
+func (h *Header) Format() []byte {
+	if h.Version == 2 {
+		return h.formatV2()
+	}
+	return h.formatV1()
+}
+
+func (h *Header) formatV1() []byte {
+	if h.Local {
+		return []byte("PROXY UNKNOWN\r\n")
+	}
+
+	protocol := "TCP4"
+	if h.Source.IP.To4() == nil {
+		protocol = "TCP6"
+	}
+
+	return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n",
+		protocol, h.Source.IP.String(), h.Destination.IP.String(), h.Source.Port, h.Destination.Port))
+}
+
+func (h *Header) formatV2() []byte {
+	buf := make([]byte, 0, 16+36)
+	buf = append(buf, v2Signature...)
+
+	if h.Local {
+		return append(buf, 0x20, 0x00, 0x00, 0x00)
+	}
+
+	var payload []byte
+	family := byte(v2FamilyTCP6)
+	if src, dst := h.Source.IP.To4(), h.Destination.IP.To4(); src != nil {
+		family = v2FamilyTCP4
+		payload = append(append(payload, src...), dst...)
+	} else {
+		payload = append(append(payload, h.Source.IP.To16()...), h.Destination.IP.To16()...)
+	}
+	payload = binary.BigEndian.AppendUint16(payload, uint16(h.Source.Port))
+	payload = binary.BigEndian.AppendUint16(payload, uint16(h.Destination.Port))
+
+	buf = append(buf, 0x20|v2CommandProxy, family)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(payload)))
+	return append(buf, payload...)
+}
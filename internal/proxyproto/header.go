@@ -0,0 +1,171 @@
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+var (
+	v1Prefix    = []byte("PROXY ")
+	v2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+	ErrNoHeader      = errors.New("proxy protocol header not present")
+	ErrInvalidHeader = errors.New("invalid proxy protocol header")
+)
+
+const (
+	v1MaxLength = 107
+
+	v2CommandLocal = 0x0
+	v2CommandProxy = 0x1
+
+	v2FamilyTCP4 = 0x11
+	v2FamilyTCP6 = 0x21
+)
+
+type Header struct {
+	Version     int
+	Local       bool
+	Source      *net.TCPAddr
+	Destination *net.TCPAddr
+}
+
+func ReadHeader(r *bufio.Reader) (*Header, error) {
+	sig, err := r.Peek(len(v1Prefix))
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, bufio.ErrBufferFull) {
+			return nil, ErrNoHeader
+		}
+		return nil, err
+	}
+
+	if bytes.Equal(sig, v1Prefix) {
+		return readV1(r)
+	}
+
+	if sig[0] == v2Signature[0] {
+		sig, err = r.Peek(len(v2Signature))
+		if err == nil && bytes.Equal(sig, v2Signature) {
+			return readV2(r)
+		}
+	}
+
+	return nil, ErrNoHeader
+}
+
+func readV1(r *bufio.Reader) (*Header, error) {
+	var line []byte
+	for len(line) < v1MaxLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, fmt.Errorf("%w: v1 header is not terminated", ErrInvalidHeader)
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("%w: v1 header is too short", ErrInvalidHeader)
+	}
+
+	header := &Header{Version: 1}
+	switch fields[1] {
+	case "UNKNOWN":
+		header.Local = true
+		return header, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, fmt.Errorf("%w: unsupported v1 protocol %q", ErrInvalidHeader, fields[1])
+	}
+
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("%w: v1 header must have 6 fields", ErrInvalidHeader)
+	}
+
+	source, err := parseV1Address(fields[2], fields[4])
+	if err != nil {
+		return nil, err
+	}
+	destination, err := parseV1Address(fields[3], fields[5])
+	if err != nil {
+		return nil, err
+	}
+
+	header.Source = source
+	header.Destination = destination
+	return header, nil
+}
+
+func parseV1Address(host, port string) (*net.TCPAddr, error) {
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidHeader, host)
+	}
+
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid port %q", ErrInvalidHeader, port)
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(p)}, nil
+}
+
+func readV2(r *bufio.Reader) (*Header, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	if fixed[12]>>4 != 2 {
+		return nil, fmt.Errorf("%w: unsupported v2 version %d", ErrInvalidHeader, fixed[12]>>4)
+	}
+
+	length := int(binary.BigEndian.Uint16(fixed[14:16]))
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	header := &Header{Version: 2}
+	switch fixed[12] & 0x0F {
+	case v2CommandLocal:
+		header.Local = true
+		return header, nil
+	case v2CommandProxy:
+	default:
+		return nil, fmt.Errorf("%w: unsupported v2 command %d", ErrInvalidHeader, fixed[12]&0x0F)
+	}
+
+	switch fixed[13] {
+	case v2FamilyTCP4:
+		if length < 12 {
+			return nil, fmt.Errorf("%w: v2 TCP4 payload is too short", ErrInvalidHeader)
+		}
+		header.Source = &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}
+		header.Destination = &net.TCPAddr{IP: net.IP(payload[4:8]), Port: int(binary.BigEndian.Uint16(payload[10:12]))}
+	case v2FamilyTCP6:
+		if length < 36 {
+			return nil, fmt.Errorf("%w: v2 TCP6 payload is too short", ErrInvalidHeader)
+		}
+		header.Source = &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}
+		header.Destination = &net.TCPAddr{IP: net.IP(payload[16:32]), Port: int(binary.BigEndian.Uint16(payload[34:36]))}
+	default:
+		header.Local = true
+	}
+
+	return header, nil
+}
@@ -0,0 +1,137 @@
+package proxyproto
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type Listener struct {
+	net.Listener
+	required       bool
+	headerTimeout  time.Duration
+	allowedSources []netip.Prefix
+	logger         *zap.Logger
+}
+
+func NewListener(inner net.Listener, required bool, headerTimeout time.Duration, allowedSources []netip.Prefix, logger *zap.Logger) *Listener {
+	return &Listener{
+		Listener:       inner,
+		required:       required,
+		headerTimeout:  headerTimeout,
+		allowedSources: allowedSources,
+		logger:         logger,
+	}
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if l.isAllowedSource(conn.RemoteAddr()) {
+			return &Conn{
+				Conn:     conn,
+				reader:   bufio.NewReader(conn),
+				listener: l,
+			}, nil
+		}
+		if !l.required {
+			return conn, nil
+		}
+
+		l.logger.Warn("Rejected connection from a source not allowed to send PROXY protocol headers",
+			zap.String("remote_addr", conn.RemoteAddr().String()),
+		)
+		conn.Close()
+	}
+}
+
+func (l *Listener) isAllowedSource(addr net.Addr) bool {
+	if len(l.allowedSources) == 0 {
+		return true
+	}
+
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	ip, ok := netip.AddrFromSlice(tcpAddr.IP)
+	if !ok {
+		return false
+	}
+	ip = ip.Unmap()
+
+	for _, prefix := range l.allowedSources {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type Conn struct {
+	net.Conn
+	reader   *bufio.Reader
+	listener *Listener
+	header   *Header
+	err      error
+	once     sync.Once
+}
+
+func (c *Conn) readHeader() {
+	c.once.Do(func() {
+		if c.listener.headerTimeout > 0 {
+			c.Conn.SetReadDeadline(time.Now().Add(c.listener.headerTimeout))
+			defer c.Conn.SetReadDeadline(time.Time{})
+		}
+
+		header, err := ReadHeader(c.reader)
+		if errors.Is(err, ErrNoHeader) && !c.listener.required {
+			return
+		}
+		if err != nil {
+			c.err = err
+			c.listener.logger.Warn("Rejected connection with invalid PROXY protocol header",
+				zap.String("remote_addr", c.Conn.RemoteAddr().String()),
+				zap.Error(err),
+			)
+			c.Conn.Close()
+			return
+		}
+
+		c.header = header
+	})
+}
+
+func (c *Conn) Read(p []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.reader.Read(p)
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.header != nil && !c.header.Local && c.header.Source != nil {
+		return c.header.Source
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *Conn) LocalAddr() net.Addr {
+	c.readHeader()
+	if c.header != nil && !c.header.Local && c.header.Destination != nil {
+		return c.header.Destination
+	}
+	return c.Conn.LocalAddr()
+}
@@ -0,0 +1,106 @@
+package rate_limiter
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"slices"
+	"sync"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/clientip"
+)
+
+type Allowlist struct {
+	cfg     config.AllowlistConfig
+	clients map[string]bool
+	cidrs   []netip.Prefix
+	headers map[string]string
+	mu      sync.RWMutex
+}
+
+func NewAllowlist(cfg config.AllowlistConfig) (*Allowlist, error) {
+	a := &Allowlist{}
+	if err := a.Set(cfg); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (a *Allowlist) Set(cfg config.AllowlistConfig) error {
+	clients := make(map[string]bool, len(cfg.Clients))
+	for _, client := range cfg.Clients {
+		if client == "" {
+			return fmt.Errorf("allowlist client ID must not be empty")
+		}
+		clients[client] = true
+	}
+
+	cidrs := make([]netip.Prefix, 0, len(cfg.CIDRs))
+	for _, value := range cfg.CIDRs {
+		prefix, err := clientip.ParsePrefix(value)
+		if err != nil {
+			return fmt.Errorf("invalid allowlist entry: %w", err)
+		}
+		cidrs = append(cidrs, prefix)
+	}
+
+	headers := make(map[string]string, len(cfg.Headers))
+	for name, value := range cfg.Headers {
+		if name == "" {
+			return fmt.Errorf("allowlist header name must not be empty")
+		}
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.cfg = config.AllowlistConfig{
+		Clients: slices.Clone(cfg.Clients),
+		CIDRs:   slices.Clone(cfg.CIDRs),
+		Headers: headers,
+	}
+	a.clients = clients
+	a.cidrs = cidrs
+	a.headers = headers
+	return nil
+}
+
+func (a *Allowlist) Config() config.AllowlistConfig {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.cfg
+}
+
+func (a *Allowlist) Allows(r *http.Request, clientID, clientIP string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if a.clients[clientID] {
+		return true
+	}
+
+	if len(a.cidrs) > 0 {
+		if addr, err := netip.ParseAddr(clientIP); err == nil {
+			addr = addr.Unmap()
+			for _, prefix := range a.cidrs {
+				if prefix.Contains(addr) {
+					return true
+				}
+			}
+		}
+	}
+
+	for name, expected := range a.headers {
+		value := r.Header.Get(name)
+		if value == "" {
+			continue
+		}
+		if expected == "" || expected == "*" || subtle.ConstantTimeCompare([]byte(value), []byte(expected)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package rate_limiter
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+)
+
+type Ban struct {
+	ClientID string    `json:"client_id"`
+	Until    time.Time `json:"until"`
+	Offenses int       `json:"offenses"`
+}
+
+type violations struct {
+	start time.Time
+	count int
+}
+
+type offender struct {
+	until    time.Time
+	offenses int
+}
+
+type BanList struct {
+	cfg        config.BanConfig
+	violations map[string]*violations
+	offenders  map[string]*offender
+	lastPrune  time.Time
+	mu         sync.Mutex
+}
+
+func NewBanList(cfg config.BanConfig) *BanList {
+	return &BanList{
+		cfg:        cfg,
+		violations: make(map[string]*violations),
+		offenders:  make(map[string]*offender),
+	}
+}
+
+func (b *BanList) Enabled() bool {
+	return b.cfg.Enabled
+}
+
+func (b *BanList) Status() int {
+	return b.cfg.Status
+}
+
+func (b *BanList) Check(clientID string) (Ban, bool) {
+	if !b.cfg.Enabled {
+		return Ban{}, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	o, ok := b.offenders[clientID]
+	if !ok || !time.Now().Before(o.until) {
+		return Ban{}, false
+	}
+	return Ban{ClientID: clientID, Until: o.until, Offenses: o.offenses}, true
+}
+
+func (b *BanList) RecordViolation(clientID string) (Ban, bool) {
+	if !b.cfg.Enabled {
+		return Ban{}, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.prune(now)
+
+	v, ok := b.violations[clientID]
+	if !ok || now.Sub(v.start) >= b.cfg.Window {
+		v = &violations{start: now}
+		b.violations[clientID] = v
+	}
+	v.count++
+	if v.count < b.cfg.Threshold {
+		return Ban{}, false
+	}
+	delete(b.violations, clientID)
+
+	o, ok := b.offenders[clientID]
+	if !ok {
+		o = &offender{}
+		b.offenders[clientID] = o
+	}
+	duration := time.Duration(float64(b.cfg.Duration) * math.Pow(b.cfg.Multiplier, float64(o.offenses)))
+	if b.cfg.MaxDuration > 0 && (duration > b.cfg.MaxDuration || duration <= 0) {
+		duration = b.cfg.MaxDuration
+	}
+	o.offenses++
+	o.until = now.Add(duration)
+
+	return Ban{ClientID: clientID, Until: o.until, Offenses: o.offenses}, true
+}
+
+func (b *BanList) List() []Ban {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	bans := make([]Ban, 0)
+	for clientID, o := range b.offenders {
+		if now.Before(o.until) {
+			bans = append(bans, Ban{ClientID: clientID, Until: o.until, Offenses: o.offenses})
+		}
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bans[i].Until.Before(bans[j].Until)
+	})
+	return bans
+}
+
+func (b *BanList) Lift(clientID string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	o, ok := b.offenders[clientID]
+	if !ok || !time.Now().Before(o.until) {
+		return false
+	}
+	delete(b.offenders, clientID)
+	delete(b.violations, clientID)
+	return true
+}
+
+func (b *BanList) Reset(clientID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.offenders, clientID)
+	delete(b.violations, clientID)
+}
+
+func (b *BanList) prune(now time.Time) {
+	if now.Sub(b.lastPrune) < b.cfg.Window {
+		return
+	}
+	b.lastPrune = now
+
+	for clientID, v := range b.violations {
+		if now.Sub(v.start) >= b.cfg.Window {
+			delete(b.violations, clientID)
+		}
+	}
+	for clientID, o := range b.offenders {
+		if now.Sub(o.until) >= b.cfg.MaxDuration {
+			delete(b.offenders, clientID)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package rate_limiter
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+type BandwidthLimiter struct {
+	bytesPerSecond int64
+	burst          int
+	limiters       *stateStore[*rate.Limiter]
+}
+
+func NewBandwidthLimiter(bytesPerSecond int64, burst int, idleTTL time.Duration, maxClients int, logger *zap.Logger) *BandwidthLimiter {
+	if burst <= 0 {
+		burst = int(bytesPerSecond)
+	}
+
+	if bytesPerSecond > 0 {
+		logger.Info("Initializing bandwidth limiter",
+			zap.Int64("bytesPerSecond", bytesPerSecond),
+			zap.Int("burst", burst),
+		)
+	}
+
+	return &BandwidthLimiter{
+		bytesPerSecond: bytesPerSecond,
+		burst:          burst,
+		limiters:       newStateStore[*rate.Limiter](idleTTL, maxClients),
+	}
+}
+
+func (bl *BandwidthLimiter) Enabled() bool {
+	return bl.bytesPerSecond > 0
+}
+
+func (bl *BandwidthLimiter) Burst() int {
+	return bl.burst
+}
+
+func (bl *BandwidthLimiter) WaitN(ctx context.Context, clientID string, n int) error {
+	limiter := bl.limiters.get(clientID, func() *rate.Limiter {
+		return rate.NewLimiter(rate.Limit(bl.bytesPerSecond), bl.burst)
+	})
+	return limiter.WaitN(ctx, n)
+}
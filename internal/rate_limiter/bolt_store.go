@@ -0,0 +1,102 @@
+package rate_limiter
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	boltLimitsBucket = []byte("limits")
+	boltTiersBucket  = []byte("tiers")
+)
+
+type BoltStore struct {
+	db *bolt.DB
+}
+
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open rate limit store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{boltLimitsBucket, boltTiersBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize rate limit store %s: %w", path, err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Load() (map[string]UserLimits, error) {
+	limits := make(map[string]UserLimits)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltLimitsBucket).ForEach(func(k, v []byte) error {
+			var l UserLimits
+			if err := json.Unmarshal(v, &l); err != nil {
+				return fmt.Errorf("failed to parse limits for %s: %w", k, err)
+			}
+			limits[string(k)] = l
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+func (s *BoltStore) Save(clientID string, limits UserLimits) error {
+	data, err := json.Marshal(limits)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltLimitsBucket).Put([]byte(clientID), data)
+	})
+}
+
+func (s *BoltStore) Delete(clientID string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltLimitsBucket).Delete([]byte(clientID))
+	})
+}
+
+func (s *BoltStore) LoadTiers() (map[string]string, error) {
+	tiers := make(map[string]string)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltTiersBucket).ForEach(func(k, v []byte) error {
+			tiers[string(k)] = string(v)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+func (s *BoltStore) SaveTier(clientID, tier string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltTiersBucket).Put([]byte(clientID), []byte(tier))
+	})
+}
+
+func (s *BoltStore) DeleteTier(clientID string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltTiersBucket).Delete([]byte(clientID))
+	})
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
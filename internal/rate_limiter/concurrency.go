@@ -0,0 +1,98 @@
+package rate_limiter
+
+import (
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrClientConcurrency = errors.New("too many concurrent requests for client")
+	ErrGlobalConcurrency = errors.New("too many concurrent requests")
+)
+
+type ConcurrencyLimiter struct {
+	perClient    int
+	global       int
+	clientLimits map[string]int
+	active       map[string]int
+	total        int
+	logger       *zap.Logger
+	mu           sync.Mutex
+}
+
+func NewConcurrencyLimiter(perClient, global int, logger *zap.Logger) *ConcurrencyLimiter {
+	logger.Info("Initializing concurrency limiter",
+		zap.Int("perClient", perClient),
+		zap.Int("global", global),
+	)
+
+	return &ConcurrencyLimiter{
+		perClient:    perClient,
+		global:       global,
+		clientLimits: make(map[string]int),
+		active:       make(map[string]int),
+		logger:       logger,
+	}
+}
+
+func (cl *ConcurrencyLimiter) Acquire(clientID string) (func(), error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	if cl.global > 0 && cl.total >= cl.global {
+		return nil, ErrGlobalConcurrency
+	}
+	perClient := cl.perClient
+	if limit, ok := cl.clientLimits[clientID]; ok {
+		perClient = limit
+	}
+	if perClient > 0 && cl.active[clientID] >= perClient {
+		return nil, ErrClientConcurrency
+	}
+
+	cl.total++
+	cl.active[clientID]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { cl.release(clientID) })
+	}, nil
+}
+
+func (cl *ConcurrencyLimiter) SetClientLimit(clientID string, limit int) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.clientLimits[clientID] = limit
+}
+
+func (cl *ConcurrencyLimiter) DeleteClientLimit(clientID string) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	delete(cl.clientLimits, clientID)
+}
+
+func (cl *ConcurrencyLimiter) Active(clientID string) int {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return cl.active[clientID]
+}
+
+func (cl *ConcurrencyLimiter) Total() int {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return cl.total
+}
+
+func (cl *ConcurrencyLimiter) release(clientID string) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.total--
+	if cl.active[clientID] <= 1 {
+		delete(cl.active, clientID)
+		return
+	}
+	cl.active[clientID]--
+}
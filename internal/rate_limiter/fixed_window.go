@@ -0,0 +1,248 @@
+package rate_limiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type windowCounter struct {
+	start time.Time
+	count int
+	next  int
+	mu    sync.Mutex
+}
+
+type FixedWindow struct {
+	window   time.Duration
+	limits   limitStore
+	counters *stateStore[*windowCounter]
+	logger   *zap.Logger
+	mtx      sync.Mutex
+}
+
+func NewFixedWindow(defaultRate float64, defaultBurst int, window, idleTTL time.Duration, maxClients int, logger *zap.Logger) *FixedWindow {
+	logger.Info("Initializing fixed window rate limiter",
+		zap.Float64("defaultRate", defaultRate),
+		zap.Int("defaultBurst", defaultBurst),
+		zap.Duration("window", window),
+		zap.Duration("idleTTL", idleTTL),
+		zap.Int("maxClients", maxClients),
+	)
+
+	return &FixedWindow{
+		window:   window,
+		limits:   limitStore{defaultRate: defaultRate, defaultBurst: defaultBurst},
+		counters: newStateStore[*windowCounter](max(idleTTL, window), maxClients),
+		logger:   logger,
+	}
+}
+
+func (fw *FixedWindow) Allow(clientID string) bool {
+	return fw.AllowN(clientID, 1)
+}
+
+func (fw *FixedWindow) AllowN(clientID string, n int) bool {
+	allowed, _ := fw.take(clientID, time.Now(), n)
+
+	if !allowed {
+		fw.logger.Debug("Rate limit exceeded",
+			zap.String("clientID", clientID),
+			zap.Int("cost", n),
+			zap.Float64("rate", fw.GetRate(clientID)),
+			zap.Int("limit", fw.limit(clientID)),
+			zap.Duration("window", fw.window),
+		)
+	}
+
+	return allowed
+}
+
+func (fw *FixedWindow) Wait(ctx context.Context, clientID string) (time.Duration, error) {
+	if fw.limit(clientID) < 1 {
+		return 0, fmt.Errorf("rate limit for client %s allows no requests", clientID)
+	}
+
+	start := time.Now()
+	for {
+		allowed, reset := fw.take(clientID, time.Now(), 1)
+		if allowed {
+			return time.Since(start), nil
+		}
+		if err := sleepContext(ctx, time.Until(reset)); err != nil {
+			return time.Since(start), err
+		}
+	}
+}
+
+func (fw *FixedWindow) ReserveN(clientID string, n int, maxWait time.Duration) (time.Duration, bool) {
+	now := time.Now()
+	if allowed, _ := fw.take(clientID, now, n); allowed {
+		return 0, true
+	}
+
+	counter := fw.counter(clientID)
+	windowStart := now.Truncate(fw.window)
+	wait := windowStart.Add(fw.window).Sub(now)
+	limit := fw.limit(clientID)
+
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+
+	if wait > maxWait || counter.next+n > limit {
+		return wait, false
+	}
+	counter.next += n
+	return wait, true
+}
+
+func (fw *FixedWindow) Reserve(clientID string) time.Duration {
+	now := time.Now()
+	allowed, reset := fw.take(clientID, now, 1)
+	if allowed {
+		return 0
+	}
+	return reset.Sub(now)
+}
+
+func (fw *FixedWindow) GetTokens(clientID string) float64 {
+	counter := fw.counter(clientID)
+	windowStart := time.Now().Truncate(fw.window)
+
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+
+	counter.roll(windowStart, fw.window)
+	return float64(max(fw.limit(clientID)-counter.count, 0))
+}
+
+func (fw *FixedWindow) Status(clientID string) Status {
+	now := time.Now()
+	status := Status{
+		Limit:     fw.limit(clientID),
+		Remaining: int(fw.GetTokens(clientID)),
+		Reset:     now.Truncate(fw.window).Add(fw.window).Sub(now),
+	}
+	status.RetryAfter = fw.RetryAfterN(clientID, 1)
+	return status
+}
+
+func (fw *FixedWindow) RetryAfterN(clientID string, n int) time.Duration {
+	if int(fw.GetTokens(clientID)) >= n {
+		return 0
+	}
+	now := time.Now()
+	return now.Truncate(fw.window).Add(fw.window).Sub(now)
+}
+
+func (fw *FixedWindow) GetBurst(clientID string) int {
+	return fw.limits.get(clientID).Burst
+}
+
+func (fw *FixedWindow) GetRate(clientID string) float64 {
+	return fw.limits.get(clientID).Rate
+}
+
+func (fw *FixedWindow) SetClientLimits(clientID string, rate float64, burst int) error {
+	fw.mtx.Lock()
+	defer fw.mtx.Unlock()
+
+	fw.limits.set(clientID, &UserLimits{
+		Rate:  rate,
+		Burst: burst,
+	})
+
+	fw.logger.Info("Client rate limits set",
+		zap.String("clientID", clientID),
+		zap.Float64("rate", rate),
+		zap.Int("burst", burst),
+	)
+	return nil
+}
+
+func (fw *FixedWindow) GetClientLimits(clientID string) *UserLimits {
+	return fw.limits.get(clientID)
+}
+
+func (fw *FixedWindow) ListClientLimits() map[string]UserLimits {
+	return fw.limits.list()
+}
+
+func (fw *FixedWindow) DeleteClientLimits(clientID string) error {
+	fw.mtx.Lock()
+	defer fw.mtx.Unlock()
+
+	fw.limits.delete(clientID)
+	fw.counters.delete(clientID)
+
+	fw.logger.Info("Client rate limits deleted", zap.String("clientID", clientID))
+	return nil
+}
+
+func (fw *FixedWindow) Reset(clientID string) {
+	fw.counters.delete(clientID)
+	fw.logger.Info("Client rate limit state reset", zap.String("clientID", clientID))
+}
+
+func (fw *FixedWindow) UpdateClientLimits(clientID string, updateFn func(*UserLimits)) error {
+	fw.mtx.Lock()
+	defer fw.mtx.Unlock()
+
+	limits := *fw.limits.get(clientID)
+	updateFn(&limits)
+	fw.limits.set(clientID, &limits)
+
+	fw.logger.Info("Client rate limits updated",
+		zap.String("clientID", clientID),
+		zap.Float64("rate", limits.Rate),
+		zap.Int("burst", limits.Burst),
+	)
+	return nil
+}
+
+func (fw *FixedWindow) take(clientID string, now time.Time, n int) (bool, time.Time) {
+	counter := fw.counter(clientID)
+	windowStart := now.Truncate(fw.window)
+	limit := fw.limit(clientID)
+
+	counter.mu.Lock()
+	defer counter.mu.Unlock()
+
+	counter.roll(windowStart, fw.window)
+	if counter.count+n > limit {
+		return false, windowStart.Add(fw.window)
+	}
+	counter.count += n
+	return true, windowStart.Add(fw.window)
+}
+
+func (c *windowCounter) roll(windowStart time.Time, window time.Duration) {
+	if c.start.Equal(windowStart) {
+		return
+	}
+	if c.start.Add(window).Equal(windowStart) {
+		c.count = c.next
+	} else {
+		c.count = 0
+	}
+	c.start = windowStart
+	c.next = 0
+}
+
+func (fw *FixedWindow) counter(clientID string) *windowCounter {
+	return fw.counters.get(clientID, func() *windowCounter {
+		return &windowCounter{}
+	})
+}
+
+func (fw *FixedWindow) StoreStats() StoreStats {
+	return fw.counters.stats()
+}
+
+func (fw *FixedWindow) limit(clientID string) int {
+	return int(math.Ceil(fw.limits.get(clientID).Rate * fw.window.Seconds()))
+}
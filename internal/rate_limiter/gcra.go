@@ -0,0 +1,206 @@
+package rate_limiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type GCRA struct {
+	limits limitStore
+	tats   *stateStore[*atomic.Int64]
+	logger *zap.Logger
+	mtx    sync.Mutex
+}
+
+func NewGCRA(defaultRate float64, defaultBurst int, idleTTL time.Duration, maxClients int, logger *zap.Logger) *GCRA {
+	logger.Info("Initializing GCRA rate limiter",
+		zap.Float64("defaultRate", defaultRate),
+		zap.Int("defaultBurst", defaultBurst),
+		zap.Duration("idleTTL", idleTTL),
+		zap.Int("maxClients", maxClients),
+	)
+
+	return &GCRA{
+		limits: limitStore{defaultRate: defaultRate, defaultBurst: defaultBurst},
+		tats:   newStateStore[*atomic.Int64](idleTTL, maxClients),
+		logger: logger,
+	}
+}
+
+func (g *GCRA) Allow(clientID string) bool {
+	return g.AllowN(clientID, 1)
+}
+
+func (g *GCRA) AllowN(clientID string, n int) bool {
+	_, allowed := g.update(clientID, time.Now(), 0, n)
+
+	if !allowed {
+		g.logger.Debug("Rate limit exceeded",
+			zap.String("clientID", clientID),
+			zap.Int("cost", n),
+			zap.Float64("rate", g.GetRate(clientID)),
+			zap.Int("burst", g.GetBurst(clientID)),
+		)
+	}
+
+	return allowed
+}
+
+func (g *GCRA) Wait(ctx context.Context, clientID string) (time.Duration, error) {
+	delay, allowed := g.update(clientID, time.Now(), math.MaxInt64, 1)
+	if !allowed {
+		return 0, fmt.Errorf("rate limit for client %s is zero", clientID)
+	}
+	return delay, sleepContext(ctx, delay)
+}
+
+func (g *GCRA) Reserve(clientID string) time.Duration {
+	delay, _ := g.update(clientID, time.Now(), math.MaxInt64, 1)
+	return delay
+}
+
+func (g *GCRA) ReserveN(clientID string, n int, maxWait time.Duration) (time.Duration, bool) {
+	return g.update(clientID, time.Now(), maxWait, n)
+}
+
+func (g *GCRA) GetTokens(clientID string) float64 {
+	interval, tolerance, ok := g.params(clientID)
+	if !ok {
+		return 0
+	}
+
+	now := time.Now().UnixNano()
+	tat := max(g.tat(clientID).Load(), now)
+	return max(float64(tolerance-(tat-now))/float64(interval), 0)
+}
+
+func (g *GCRA) Status(clientID string) Status {
+	reset := time.Duration(max(g.tat(clientID).Load()-time.Now().UnixNano(), 0))
+	status := Status{
+		Limit:     g.GetBurst(clientID),
+		Remaining: int(g.GetTokens(clientID)),
+		Reset:     reset,
+	}
+	status.RetryAfter = g.RetryAfterN(clientID, 1)
+	return status
+}
+
+func (g *GCRA) RetryAfterN(clientID string, n int) time.Duration {
+	interval, tolerance, ok := g.params(clientID)
+	if !ok {
+		return 0
+	}
+	reset := max(g.tat(clientID).Load()-time.Now().UnixNano(), 0)
+	return time.Duration(max(reset+interval*int64(n)-tolerance, 0))
+}
+
+func (g *GCRA) GetBurst(clientID string) int {
+	return g.limits.get(clientID).Burst
+}
+
+func (g *GCRA) GetRate(clientID string) float64 {
+	return g.limits.get(clientID).Rate
+}
+
+func (g *GCRA) SetClientLimits(clientID string, rate float64, burst int) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	g.limits.set(clientID, &UserLimits{
+		Rate:  rate,
+		Burst: burst,
+	})
+
+	g.logger.Info("Client rate limits set",
+		zap.String("clientID", clientID),
+		zap.Float64("rate", rate),
+		zap.Int("burst", burst),
+	)
+	return nil
+}
+
+func (g *GCRA) GetClientLimits(clientID string) *UserLimits {
+	return g.limits.get(clientID)
+}
+
+func (g *GCRA) ListClientLimits() map[string]UserLimits {
+	return g.limits.list()
+}
+
+func (g *GCRA) DeleteClientLimits(clientID string) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	g.limits.delete(clientID)
+	g.tats.delete(clientID)
+
+	g.logger.Info("Client rate limits deleted", zap.String("clientID", clientID))
+	return nil
+}
+
+func (g *GCRA) Reset(clientID string) {
+	g.tats.delete(clientID)
+	g.logger.Info("Client rate limit state reset", zap.String("clientID", clientID))
+}
+
+func (g *GCRA) UpdateClientLimits(clientID string, updateFn func(*UserLimits)) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	limits := *g.limits.get(clientID)
+	updateFn(&limits)
+	g.limits.set(clientID, &limits)
+
+	g.logger.Info("Client rate limits updated",
+		zap.String("clientID", clientID),
+		zap.Float64("rate", limits.Rate),
+		zap.Int("burst", limits.Burst),
+	)
+	return nil
+}
+
+func (g *GCRA) update(clientID string, at time.Time, maxDelay time.Duration, n int) (time.Duration, bool) {
+	interval, tolerance, ok := g.params(clientID)
+	if !ok {
+		return 0, false
+	}
+
+	now := at.UnixNano()
+	tat := g.tat(clientID)
+	for {
+		current := tat.Load()
+		newTAT := max(current, now) + interval*int64(n)
+		delay := max(newTAT-now-tolerance, 0)
+		if delay > int64(maxDelay) {
+			return time.Duration(delay), false
+		}
+		if tat.CompareAndSwap(current, newTAT) {
+			return time.Duration(delay), true
+		}
+	}
+}
+
+func (g *GCRA) params(clientID string) (int64, int64, bool) {
+	limits := g.limits.get(clientID)
+	if limits.Rate <= 0 || limits.Burst <= 0 {
+		return 0, 0, false
+	}
+	interval := int64(float64(time.Second) / limits.Rate)
+	return interval, interval * int64(limits.Burst), true
+}
+
+func (g *GCRA) tat(clientID string) *atomic.Int64 {
+	return g.tats.get(clientID, func() *atomic.Int64 {
+		return new(atomic.Int64)
+	})
+}
+
+func (g *GCRA) StoreStats() StoreStats {
+	return g.tats.stats()
+}
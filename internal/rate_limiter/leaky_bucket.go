@@ -0,0 +1,248 @@
+package rate_limiter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type bucketState struct {
+	next time.Time
+	mu   sync.Mutex
+}
+
+type LeakyBucket struct {
+	delay   bool
+	limits  limitStore
+	buckets *stateStore[*bucketState]
+	logger  *zap.Logger
+	mtx     sync.Mutex
+}
+
+func NewLeakyBucket(defaultRate float64, defaultBurst int, delay bool, idleTTL time.Duration, maxClients int, logger *zap.Logger) *LeakyBucket {
+	logger.Info("Initializing leaky bucket rate limiter",
+		zap.Float64("defaultRate", defaultRate),
+		zap.Int("defaultBurst", defaultBurst),
+		zap.Bool("delay", delay),
+		zap.Duration("idleTTL", idleTTL),
+		zap.Int("maxClients", maxClients),
+	)
+
+	return &LeakyBucket{
+		delay:   delay,
+		limits:  limitStore{defaultRate: defaultRate, defaultBurst: defaultBurst},
+		buckets: newStateStore[*bucketState](idleTTL, maxClients),
+		logger:  logger,
+	}
+}
+
+func (lb *LeakyBucket) Allow(clientID string) bool {
+	return lb.AllowN(clientID, 1)
+}
+
+func (lb *LeakyBucket) AllowN(clientID string, n int) bool {
+	capacity := 0
+	if !lb.delay {
+		capacity = lb.capacity(clientID, n)
+	}
+
+	allowed := false
+	if capacity >= 0 {
+		_, allowed = lb.reserve(clientID, time.Now(), capacity, 0, n)
+	}
+	if !allowed {
+		lb.logger.Debug("Rate limit exceeded",
+			zap.String("clientID", clientID),
+			zap.Int("cost", n),
+			zap.Float64("rate", lb.GetRate(clientID)),
+			zap.Int("burst", lb.GetBurst(clientID)),
+		)
+		return false
+	}
+	return true
+}
+
+func (lb *LeakyBucket) Wait(ctx context.Context, clientID string) (time.Duration, error) {
+	wait, allowed := lb.reserve(clientID, time.Now(), -1, 0, 1)
+	if !allowed {
+		return 0, fmt.Errorf("rate limit for client %s is zero", clientID)
+	}
+	return wait, sleepContext(ctx, wait)
+}
+
+func (lb *LeakyBucket) Reserve(clientID string) time.Duration {
+	wait, _ := lb.reserve(clientID, time.Now(), -1, 0, 1)
+	return wait
+}
+
+func (lb *LeakyBucket) ReserveN(clientID string, n int, maxWait time.Duration) (time.Duration, bool) {
+	capacity := lb.capacity(clientID, n)
+	interval, ok := lb.interval(clientID)
+	if capacity < 0 || !ok {
+		return 0, false
+	}
+
+	wait, allowed := lb.reserve(clientID, time.Now(), capacity, maxWait, n)
+	if lb.delay {
+		return wait, allowed
+	}
+	return max(wait-time.Duration(capacity)*interval, 0), allowed
+}
+
+func (lb *LeakyBucket) GetTokens(clientID string) float64 {
+	interval, ok := lb.interval(clientID)
+	if !ok {
+		return 0
+	}
+	limit := lb.capacity(clientID, 1) + 1
+
+	bucket := lb.bucket(clientID)
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	queued := float64(max(time.Until(bucket.next), 0)) / float64(interval)
+	return max(float64(limit)-queued, 0)
+}
+
+func (lb *LeakyBucket) Status(clientID string) Status {
+	bucket := lb.bucket(clientID)
+	bucket.mu.Lock()
+	reset := max(time.Until(bucket.next), 0)
+	bucket.mu.Unlock()
+
+	status := Status{
+		Limit:     lb.capacity(clientID, 1) + 1,
+		Remaining: int(lb.GetTokens(clientID)),
+		Reset:     reset,
+	}
+	status.RetryAfter = lb.RetryAfterN(clientID, 1)
+	return status
+}
+
+func (lb *LeakyBucket) RetryAfterN(clientID string, n int) time.Duration {
+	interval, ok := lb.interval(clientID)
+	if !ok {
+		return 0
+	}
+	capacity := lb.capacity(clientID, n)
+
+	bucket := lb.bucket(clientID)
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	return max(time.Until(bucket.next)-time.Duration(capacity)*interval, 0)
+}
+
+func (lb *LeakyBucket) capacity(clientID string, n int) int {
+	if lb.delay {
+		return lb.GetBurst(clientID)
+	}
+	return lb.GetBurst(clientID) - n
+}
+
+func (lb *LeakyBucket) GetBurst(clientID string) int {
+	return lb.limits.get(clientID).Burst
+}
+
+func (lb *LeakyBucket) GetRate(clientID string) float64 {
+	return lb.limits.get(clientID).Rate
+}
+
+func (lb *LeakyBucket) SetClientLimits(clientID string, rate float64, burst int) error {
+	lb.mtx.Lock()
+	defer lb.mtx.Unlock()
+
+	lb.limits.set(clientID, &UserLimits{
+		Rate:  rate,
+		Burst: burst,
+	})
+
+	lb.logger.Info("Client rate limits set",
+		zap.String("clientID", clientID),
+		zap.Float64("rate", rate),
+		zap.Int("burst", burst),
+	)
+	return nil
+}
+
+func (lb *LeakyBucket) GetClientLimits(clientID string) *UserLimits {
+	return lb.limits.get(clientID)
+}
+
+func (lb *LeakyBucket) ListClientLimits() map[string]UserLimits {
+	return lb.limits.list()
+}
+
+func (lb *LeakyBucket) DeleteClientLimits(clientID string) error {
+	lb.mtx.Lock()
+	defer lb.mtx.Unlock()
+
+	lb.limits.delete(clientID)
+	lb.buckets.delete(clientID)
+
+	lb.logger.Info("Client rate limits deleted", zap.String("clientID", clientID))
+	return nil
+}
+
+func (lb *LeakyBucket) Reset(clientID string) {
+	lb.buckets.delete(clientID)
+	lb.logger.Info("Client rate limit state reset", zap.String("clientID", clientID))
+}
+
+func (lb *LeakyBucket) UpdateClientLimits(clientID string, updateFn func(*UserLimits)) error {
+	lb.mtx.Lock()
+	defer lb.mtx.Unlock()
+
+	limits := *lb.limits.get(clientID)
+	updateFn(&limits)
+	lb.limits.set(clientID, &limits)
+
+	lb.logger.Info("Client rate limits updated",
+		zap.String("clientID", clientID),
+		zap.Float64("rate", limits.Rate),
+		zap.Int("burst", limits.Burst),
+	)
+	return nil
+}
+
+func (lb *LeakyBucket) reserve(clientID string, now time.Time, capacity int, slack time.Duration, n int) (time.Duration, bool) {
+	interval, ok := lb.interval(clientID)
+	if !ok {
+		return 0, false
+	}
+
+	bucket := lb.bucket(clientID)
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+
+	next := bucket.next
+	if next.Before(now) {
+		next = now
+	}
+	wait := next.Sub(now)
+	if capacity >= 0 && wait > time.Duration(capacity)*interval+slack {
+		return wait, false
+	}
+	bucket.next = next.Add(time.Duration(n) * interval)
+	return wait, true
+}
+
+func (lb *LeakyBucket) interval(clientID string) (time.Duration, bool) {
+	rate := lb.GetRate(clientID)
+	if rate <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(time.Second) / rate), true
+}
+
+func (lb *LeakyBucket) bucket(clientID string) *bucketState {
+	return lb.buckets.get(clientID, func() *bucketState {
+		return &bucketState{}
+	})
+}
+
+func (lb *LeakyBucket) StoreStats() StoreStats {
+	return lb.buckets.stats()
+}
@@ -0,0 +1,58 @@
+package rate_limiter
+
+import (
+	"fmt"
+	"sync"
+
+	"CloudBalancer/config"
+
+	"go.uber.org/zap"
+)
+
+func New(cfg config.RateLimitConfig, logger *zap.Logger) (RateLimiter, error) {
+	switch cfg.Algorithm {
+	case "TokenBucket":
+		return NewTokenBucket(cfg.DefaultRate, cfg.DefaultBurst, cfg.IdleTTL, cfg.MaxClients, logger), nil
+	case "FixedWindow":
+		return NewFixedWindow(cfg.DefaultRate, cfg.DefaultBurst, cfg.Window, cfg.IdleTTL, cfg.MaxClients, logger), nil
+	case "LeakyBucket":
+		return NewLeakyBucket(cfg.DefaultRate, cfg.DefaultBurst, cfg.Delay, cfg.IdleTTL, cfg.MaxClients, logger), nil
+	case "GCRA":
+		return NewGCRA(cfg.DefaultRate, cfg.DefaultBurst, cfg.IdleTTL, cfg.MaxClients, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit algorithm: %s", cfg.Algorithm)
+	}
+}
+
+type limitStore struct {
+	defaultRate  float64
+	defaultBurst int
+	limits       sync.Map
+}
+
+func (s *limitStore) get(clientID string) *UserLimits {
+	if limits, ok := s.limits.Load(clientID); ok {
+		return limits.(*UserLimits)
+	}
+	return &UserLimits{
+		Rate:  s.defaultRate,
+		Burst: s.defaultBurst,
+	}
+}
+
+func (s *limitStore) set(clientID string, limits *UserLimits) {
+	s.limits.Store(clientID, limits)
+}
+
+func (s *limitStore) delete(clientID string) {
+	s.limits.Delete(clientID)
+}
+
+func (s *limitStore) list() map[string]UserLimits {
+	limits := make(map[string]UserLimits)
+	s.limits.Range(func(key, value any) bool {
+		limits[key.(string)] = *value.(*UserLimits)
+		return true
+	})
+	return limits
+}
@@ -0,0 +1,164 @@
+package rate_limiter
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"CloudBalancer/internal/metrics"
+	"CloudBalancer/internal/topk"
+)
+
+type Outcome int
+
+const (
+	OutcomeAllowed Outcome = iota
+	OutcomeRateLimited
+	OutcomeQuotaExceeded
+	OutcomeBanned
+	OutcomeConcurrency
+)
+
+var outcomeNames = [...]string{
+	OutcomeAllowed:       "allowed",
+	OutcomeRateLimited:   "rate_limited",
+	OutcomeQuotaExceeded: "quota_exceeded",
+	OutcomeBanned:        "banned",
+	OutcomeConcurrency:   "concurrency",
+}
+
+func (o Outcome) String() string {
+	return outcomeNames[o]
+}
+
+type Counters struct {
+	Allowed       int64 `json:"allowed"`
+	RateLimited   int64 `json:"rate_limited"`
+	QuotaExceeded int64 `json:"quota_exceeded"`
+	Banned        int64 `json:"banned"`
+	Concurrency   int64 `json:"concurrency"`
+}
+
+func (c Counters) Rejected() int64 {
+	return c.RateLimited + c.QuotaExceeded + c.Banned + c.Concurrency
+}
+
+type counters struct {
+	outcomes [OutcomeConcurrency + 1]atomic.Int64
+}
+
+func (c *counters) record(outcome Outcome) {
+	c.outcomes[outcome].Add(1)
+}
+
+func (c *counters) snapshot() Counters {
+	return Counters{
+		Allowed:       c.outcomes[OutcomeAllowed].Load(),
+		RateLimited:   c.outcomes[OutcomeRateLimited].Load(),
+		QuotaExceeded: c.outcomes[OutcomeQuotaExceeded].Load(),
+		Banned:        c.outcomes[OutcomeBanned].Load(),
+		Concurrency:   c.outcomes[OutcomeConcurrency].Load(),
+	}
+}
+
+type classKey struct {
+	route string
+	class string
+}
+
+type Metrics struct {
+	total    counters
+	clients  *stateStore[*counters]
+	routes   map[string]*counters
+	classes  map[classKey]*counters
+	classify func(clientID string) string
+	top      *topk.Tracker
+	mu       sync.Mutex
+}
+
+func NewMetrics(idleTTL time.Duration, maxClients int, classify func(clientID string) string, registry *metrics.Registry, top *topk.Tracker) *Metrics {
+	m := &Metrics{
+		clients:  newStateStore[*counters](idleTTL, maxClients),
+		routes:   make(map[string]*counters),
+		classes:  make(map[classKey]*counters),
+		classify: classify,
+		top:      top,
+	}
+
+	registry.NewCounterFunc("cloudbalancer_ratelimit_decisions_total",
+		"Rate limiting decisions by route, client class (tier name or default) and outcome.",
+		[]string{"route", "client_class", "outcome"},
+		func(emit func(float64, ...string)) {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			for key, c := range m.classes {
+				for outcome := range c.outcomes {
+					emit(float64(c.outcomes[outcome].Load()), key.route, key.class, Outcome(outcome).String())
+				}
+			}
+		},
+	)
+
+	return m
+}
+
+func (m *Metrics) Record(clientID, route string, outcome Outcome) {
+	m.total.record(outcome)
+	m.clients.get(clientID, func() *counters { return &counters{} }).record(outcome)
+	m.route(route).record(outcome)
+	m.top.ObserveClient(clientID, outcome != OutcomeAllowed)
+
+	class := m.classify(clientID)
+	if class == "" {
+		class = "default"
+	}
+	m.class(route, class).record(outcome)
+}
+
+func (m *Metrics) Total() Counters {
+	return m.total.snapshot()
+}
+
+func (m *Metrics) Routes() map[string]Counters {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	routes := make(map[string]Counters, len(m.routes))
+	for route, c := range m.routes {
+		routes[route] = c.snapshot()
+	}
+	return routes
+}
+
+func (m *Metrics) Clients() map[string]Counters {
+	clients := make(map[string]Counters)
+	m.clients.each(func(clientID string, c *counters) {
+		clients[clientID] = c.snapshot()
+	})
+	return clients
+}
+
+func (m *Metrics) class(route, class string) *counters {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := classKey{route: route, class: class}
+	c, ok := m.classes[key]
+	if !ok {
+		c = &counters{}
+		m.classes[key] = c
+	}
+	return c
+}
+
+func (m *Metrics) route(route string) *counters {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.routes[route]
+	if !ok {
+		c = &counters{}
+		m.routes[route] = c
+	}
+	return c
+}
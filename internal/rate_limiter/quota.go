@@ -0,0 +1,191 @@
+package rate_limiter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+
+	"go.uber.org/zap"
+)
+
+type QuotaStatus struct {
+	Limit     int64
+	Remaining int64
+	Reset     time.Time
+}
+
+type quotaUsage struct {
+	PeriodStart time.Time `json:"period_start"`
+	Used        int64     `json:"used"`
+}
+
+type Quota struct {
+	limit    int64
+	period   string
+	location *time.Location
+	path     string
+	usage    map[string]*quotaUsage
+	dirty    bool
+	logger   *zap.Logger
+	mu       sync.Mutex
+}
+
+func NewQuota(cfg config.QuotaConfig, logger *zap.Logger) (*Quota, error) {
+	q := &Quota{
+		period: cfg.Period,
+		path:   cfg.Path,
+		usage:  make(map[string]*quotaUsage),
+		logger: logger,
+	}
+	if !cfg.Enabled {
+		return q, nil
+	}
+
+	location, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quota timezone: %w", err)
+	}
+	q.limit = cfg.Limit
+	q.location = location
+
+	if q.path != "" {
+		if err := q.load(); err != nil {
+			return nil, err
+		}
+	}
+	go q.startFlush(cfg.FlushInterval)
+
+	logger.Info("Initializing request quota",
+		zap.Int64("limit", cfg.Limit),
+		zap.String("period", cfg.Period),
+		zap.String("timezone", cfg.Timezone),
+		zap.Int("clients", len(q.usage)),
+	)
+
+	return q, nil
+}
+
+func (q *Quota) Enabled() bool {
+	return q.limit > 0
+}
+
+func (q *Quota) Consume(clientID string) (QuotaStatus, bool) {
+	if !q.Enabled() {
+		return QuotaStatus{}, true
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	start := q.periodStart(now)
+	usage, ok := q.usage[clientID]
+	if !ok || !usage.PeriodStart.Equal(start) {
+		usage = &quotaUsage{PeriodStart: start}
+		q.usage[clientID] = usage
+	}
+
+	status := QuotaStatus{Limit: q.limit, Reset: q.periodEnd(start)}
+	if usage.Used >= q.limit {
+		return status, false
+	}
+	usage.Used++
+	q.dirty = true
+
+	status.Remaining = q.limit - usage.Used
+	return status, true
+}
+
+func (q *Quota) Flush() error {
+	if !q.Enabled() || q.path == "" {
+		return nil
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !q.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(q.usage)
+	if err != nil {
+		return err
+	}
+	tmp := q.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write quota usage: %w", err)
+	}
+	if err := os.Rename(tmp, q.path); err != nil {
+		return fmt.Errorf("failed to write quota usage: %w", err)
+	}
+	q.dirty = false
+	return nil
+}
+
+func (q *Quota) prune() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	start := q.periodStart(time.Now())
+	for clientID, usage := range q.usage {
+		if usage.PeriodStart.Before(start) {
+			delete(q.usage, clientID)
+			q.dirty = true
+		}
+	}
+}
+
+func (q *Quota) load() error {
+	data, err := os.ReadFile(q.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read quota usage: %w", err)
+	}
+	if err := json.Unmarshal(data, &q.usage); err != nil {
+		return fmt.Errorf("failed to parse quota usage %s: %w", q.path, err)
+	}
+	return nil
+}
+
+func (q *Quota) startFlush(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		q.prune()
+		if err := q.Flush(); err != nil {
+			q.logger.Error("Failed to persist quota usage", zap.Error(err))
+		}
+	}
+}
+
+func (q *Quota) periodStart(now time.Time) time.Time {
+	now = now.In(q.location)
+	switch q.period {
+	case "month":
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, q.location)
+	case "hour":
+		return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), 0, 0, 0, q.location)
+	default:
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, q.location)
+	}
+}
+
+func (q *Quota) periodEnd(start time.Time) time.Time {
+	switch q.period {
+	case "month":
+		return start.AddDate(0, 1, 0)
+	case "hour":
+		return start.Add(time.Hour)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
@@ -1,6 +1,8 @@
 package rate_limiter
 
 import (
+	"context"
+	"math"
 	"sync"
 	"time"
 
@@ -9,52 +11,74 @@ import (
 )
 
 type UserLimits struct {
-	Rate  float64
-	Burst int
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type Status struct {
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
 }
 
 type RateLimiter interface {
 	Allow(clientID string) bool
-	Wait(clientID string) time.Duration
+	AllowN(clientID string, n int) bool
+	Wait(ctx context.Context, clientID string) (time.Duration, error)
 	Reserve(clientID string) time.Duration
+	ReserveN(clientID string, n int, maxWait time.Duration) (time.Duration, bool)
 	GetTokens(clientID string) float64
 	GetBurst(clientID string) int
 	GetRate(clientID string) float64
-	SetClientLimits(clientID string, rate float64, burst int)
+	Status(clientID string) Status
+	RetryAfterN(clientID string, n int) time.Duration
+	StoreStats() StoreStats
+	SetClientLimits(clientID string, rate float64, burst int) error
 	GetClientLimits(clientID string) *UserLimits
-	DeleteClientLimits(clientID string)
-	UpdateClientLimits(clientID string, updateFn func(*UserLimits))
+	ListClientLimits() map[string]UserLimits
+	DeleteClientLimits(clientID string) error
+	UpdateClientLimits(clientID string, updateFn func(*UserLimits)) error
+	Reset(clientID string)
 }
 
 type TokenBucket struct {
 	defaultRate  float64
 	defaultBurst int
-	limiters     sync.Map
+	limiters     *stateStore[*rate.Limiter]
 	clientLimits sync.Map
 	logger       *zap.Logger
 	mtx          sync.RWMutex
 }
 
-func NewTokenBucket(defaultRate float64, defaultBurst int, logger *zap.Logger) *TokenBucket {
+func NewTokenBucket(defaultRate float64, defaultBurst int, idleTTL time.Duration, maxClients int, logger *zap.Logger) *TokenBucket {
 	logger.Info("Initializing token bucket rate limiter",
 		zap.Float64("defaultRate", defaultRate),
 		zap.Int("defaultBurst", defaultBurst),
+		zap.Duration("idleTTL", idleTTL),
+		zap.Int("maxClients", maxClients),
 	)
 
 	return &TokenBucket{
 		defaultRate:  defaultRate,
 		defaultBurst: defaultBurst,
+		limiters:     newStateStore[*rate.Limiter](idleTTL, maxClients),
 		logger:       logger,
 	}
 }
 
 func (tb *TokenBucket) Allow(clientID string) bool {
+	return tb.AllowN(clientID, 1)
+}
+
+func (tb *TokenBucket) AllowN(clientID string, n int) bool {
 	limiter := tb.getLimiter(clientID)
-	allowed := limiter.Allow()
+	allowed := limiter.AllowN(time.Now(), n)
 
 	if !allowed {
 		tb.logger.Debug("Rate limit exceeded",
 			zap.String("clientID", clientID),
+			zap.Int("cost", n),
 			zap.Float64("rate", tb.GetRate(clientID)),
 			zap.Int("burst", tb.GetBurst(clientID)),
 		)
@@ -63,7 +87,7 @@ func (tb *TokenBucket) Allow(clientID string) bool {
 	return allowed
 }
 
-func (tb *TokenBucket) SetClientLimits(clientID string, myrate float64, burst int) {
+func (tb *TokenBucket) SetClientLimits(clientID string, myrate float64, burst int) error {
 	tb.mtx.Lock()
 	defer tb.mtx.Unlock()
 
@@ -72,14 +96,14 @@ func (tb *TokenBucket) SetClientLimits(clientID string, myrate float64, burst in
 		Burst: burst,
 	})
 
-	limiter := rate.NewLimiter(rate.Limit(myrate), burst)
-	tb.limiters.Store(clientID, limiter)
+	tb.limiters.set(clientID, rate.NewLimiter(rate.Limit(myrate), burst))
 
 	tb.logger.Info("Client rate limits set",
 		zap.String("clientID", clientID),
 		zap.Float64("rate", myrate),
 		zap.Int("burst", burst),
 	)
+	return nil
 }
 
 func (tb *TokenBucket) GetClientLimits(clientID string) *UserLimits {
@@ -92,17 +116,32 @@ func (tb *TokenBucket) GetClientLimits(clientID string) *UserLimits {
 	}
 }
 
-func (tb *TokenBucket) DeleteClientLimits(clientID string) {
+func (tb *TokenBucket) ListClientLimits() map[string]UserLimits {
+	limits := make(map[string]UserLimits)
+	tb.clientLimits.Range(func(key, value any) bool {
+		limits[key.(string)] = *value.(*UserLimits)
+		return true
+	})
+	return limits
+}
+
+func (tb *TokenBucket) DeleteClientLimits(clientID string) error {
 	tb.mtx.Lock()
 	defer tb.mtx.Unlock()
 
 	tb.clientLimits.Delete(clientID)
-	tb.limiters.Delete(clientID)
+	tb.limiters.delete(clientID)
 
 	tb.logger.Info("Client rate limits deleted", zap.String("clientID", clientID))
+	return nil
 }
 
-func (tb *TokenBucket) UpdateClientLimits(clientID string, updateFn func(*UserLimits)) {
+func (tb *TokenBucket) Reset(clientID string) {
+	tb.limiters.delete(clientID)
+	tb.logger.Info("Client rate limit state reset", zap.String("clientID", clientID))
+}
+
+func (tb *TokenBucket) UpdateClientLimits(clientID string, updateFn func(*UserLimits)) error {
 	tb.mtx.Lock()
 	defer tb.mtx.Unlock()
 
@@ -110,22 +149,21 @@ func (tb *TokenBucket) UpdateClientLimits(clientID string, updateFn func(*UserLi
 	updateFn(limits)
 
 	tb.clientLimits.Store(clientID, limits)
-
-	limiter := rate.NewLimiter(rate.Limit(limits.Rate), limits.Burst)
-	tb.limiters.Store(clientID, limiter)
+	tb.limiters.set(clientID, rate.NewLimiter(rate.Limit(limits.Rate), limits.Burst))
 
 	tb.logger.Info("Client rate limits updated",
 		zap.String("clientID", clientID),
 		zap.Float64("rate", limits.Rate),
 		zap.Int("burst", limits.Burst),
 	)
+	return nil
 }
 
-func (tb *TokenBucket) Wait(clientID string) time.Duration {
+func (tb *TokenBucket) Wait(ctx context.Context, clientID string) (time.Duration, error) {
 	limiter := tb.getLimiter(clientID)
 	now := time.Now()
-	limiter.Wait(nil)
-	return time.Since(now)
+	err := limiter.Wait(ctx)
+	return time.Since(now), err
 }
 
 func (tb *TokenBucket) Reserve(clientID string) time.Duration {
@@ -133,23 +171,37 @@ func (tb *TokenBucket) Reserve(clientID string) time.Duration {
 	return limiter.Reserve().Delay()
 }
 
-func (tb *TokenBucket) getLimiter(clientID string) *rate.Limiter {
-	if limiter, ok := tb.limiters.Load(clientID); ok {
-		return limiter.(*rate.Limiter)
+func (tb *TokenBucket) ReserveN(clientID string, n int, maxWait time.Duration) (time.Duration, bool) {
+	now := time.Now()
+	reservation := tb.getLimiter(clientID).ReserveN(now, n)
+	if !reservation.OK() {
+		return 0, false
 	}
 
-	limits := tb.GetClientLimits(clientID)
+	delay := reservation.DelayFrom(now)
+	if delay > maxWait {
+		reservation.CancelAt(now)
+		return delay, false
+	}
+	return delay, true
+}
 
-	limiter := rate.NewLimiter(rate.Limit(limits.Rate), limits.Burst)
-	tb.limiters.Store(clientID, limiter)
+func (tb *TokenBucket) getLimiter(clientID string) *rate.Limiter {
+	return tb.limiters.get(clientID, func() *rate.Limiter {
+		limits := tb.GetClientLimits(clientID)
 
-	tb.logger.Debug("Created new rate limiter for client",
-		zap.String("clientID", clientID),
-		zap.Float64("rate", limits.Rate),
-		zap.Int("burst", limits.Burst),
-	)
+		tb.logger.Debug("Created new rate limiter for client",
+			zap.String("clientID", clientID),
+			zap.Float64("rate", limits.Rate),
+			zap.Int("burst", limits.Burst),
+		)
+
+		return rate.NewLimiter(rate.Limit(limits.Rate), limits.Burst)
+	})
+}
 
-	return limiter
+func (tb *TokenBucket) StoreStats() StoreStats {
+	return tb.limiters.stats()
 }
 
 func (tb *TokenBucket) GetTokens(clientID string) float64 {
@@ -157,6 +209,27 @@ func (tb *TokenBucket) GetTokens(clientID string) float64 {
 	return float64(limiter.Tokens())
 }
 
+func (tb *TokenBucket) Status(clientID string) Status {
+	limits := tb.GetClientLimits(clientID)
+	tokens := math.Max(tb.GetTokens(clientID), 0)
+
+	status := Status{Limit: limits.Burst, Remaining: int(tokens)}
+	if limits.Rate > 0 && tokens < float64(limits.Burst) {
+		status.Reset = time.Duration((float64(limits.Burst) - tokens) / limits.Rate * float64(time.Second))
+	}
+	status.RetryAfter = tb.RetryAfterN(clientID, 1)
+	return status
+}
+
+func (tb *TokenBucket) RetryAfterN(clientID string, n int) time.Duration {
+	limits := tb.GetClientLimits(clientID)
+	tokens := math.Max(tb.GetTokens(clientID), 0)
+	if limits.Rate <= 0 || tokens >= float64(n) {
+		return 0
+	}
+	return time.Duration((float64(n) - tokens) / limits.Rate * float64(time.Second))
+}
+
 func (tb *TokenBucket) GetBurst(clientID string) int {
 	limits := tb.GetClientLimits(clientID)
 	return limits.Burst
@@ -166,3 +239,19 @@ func (tb *TokenBucket) GetRate(clientID string) float64 {
 	limits := tb.GetClientLimits(clientID)
 	return limits.Rate
 }
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
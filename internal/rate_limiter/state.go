@@ -0,0 +1,176 @@
+package rate_limiter
+
+import (
+	"container/list"
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+const stateShards = 32
+
+type StoreStats struct {
+	Clients   int   `json:"clients"`
+	Evictions int64 `json:"evictions"`
+}
+
+type stateEntry[T any] struct {
+	key      string
+	value    T
+	lastSeen time.Time
+}
+
+type stateStore[T any] struct {
+	seed   maphash.Seed
+	shards []*stateShard[T]
+}
+
+type stateShard[T any] struct {
+	idleTTL    time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+	evictions  int64
+	mu         sync.Mutex
+}
+
+func newStateStore[T any](idleTTL time.Duration, maxEntries int) *stateStore[T] {
+	count := stateShards
+	if maxEntries > 0 {
+		count = min(count, maxEntries)
+	}
+
+	s := &stateStore[T]{seed: maphash.MakeSeed(), shards: make([]*stateShard[T], count)}
+	for i := range s.shards {
+		limit := 0
+		if maxEntries > 0 {
+			limit = maxEntries / count
+			if i < maxEntries%count {
+				limit++
+			}
+		}
+		s.shards[i] = &stateShard[T]{
+			idleTTL:    idleTTL,
+			maxEntries: limit,
+			entries:    make(map[string]*list.Element),
+			lru:        list.New(),
+		}
+	}
+	return s
+}
+
+func (s *stateStore[T]) shard(key string) *stateShard[T] {
+	return s.shards[maphash.String(s.seed, key)%uint64(len(s.shards))]
+}
+
+func (s *stateStore[T]) get(key string, create func() T) T {
+	return s.shard(key).get(key, create)
+}
+
+func (s *stateStore[T]) set(key string, value T) {
+	s.shard(key).set(key, value)
+}
+
+func (s *stateStore[T]) delete(key string) {
+	s.shard(key).delete(key)
+}
+
+func (s *stateStore[T]) stats() StoreStats {
+	var stats StoreStats
+	for _, shard := range s.shards {
+		shardStats := shard.stats()
+		stats.Clients += shardStats.Clients
+		stats.Evictions += shardStats.Evictions
+	}
+	return stats
+}
+
+func (s *stateStore[T]) each(fn func(key string, value T)) {
+	for _, shard := range s.shards {
+		shard.each(fn)
+	}
+}
+
+func (s *stateShard[T]) get(key string, create func() T) T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if elem, ok := s.entries[key]; ok {
+		entry := elem.Value.(*stateEntry[T])
+		entry.lastSeen = now
+		s.lru.MoveToFront(elem)
+		return entry.value
+	}
+
+	s.evictIdle(now)
+	if s.maxEntries > 0 {
+		for s.lru.Len() >= s.maxEntries {
+			s.remove(s.lru.Back())
+			s.evictions++
+		}
+	}
+
+	entry := &stateEntry[T]{key: key, value: create(), lastSeen: now}
+	s.entries[key] = s.lru.PushFront(entry)
+	return entry.value
+}
+
+func (s *stateShard[T]) set(key string, value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+	}
+	entry := &stateEntry[T]{key: key, value: value, lastSeen: time.Now()}
+	s.entries[key] = s.lru.PushFront(entry)
+}
+
+func (s *stateShard[T]) delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+	}
+}
+
+func (s *stateShard[T]) stats() StoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictIdle(time.Now())
+	return StoreStats{
+		Clients:   s.lru.Len(),
+		Evictions: s.evictions,
+	}
+}
+
+func (s *stateShard[T]) evictIdle(now time.Time) {
+	if s.idleTTL <= 0 {
+		return
+	}
+	for elem := s.lru.Back(); elem != nil; elem = s.lru.Back() {
+		if now.Sub(elem.Value.(*stateEntry[T]).lastSeen) < s.idleTTL {
+			return
+		}
+		s.remove(elem)
+		s.evictions++
+	}
+}
+
+func (s *stateShard[T]) remove(elem *list.Element) {
+	delete(s.entries, elem.Value.(*stateEntry[T]).key)
+	s.lru.Remove(elem)
+}
+
+func (s *stateShard[T]) each(fn func(key string, value T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for elem := s.lru.Front(); elem != nil; elem = elem.Next() {
+		entry := elem.Value.(*stateEntry[T])
+		fn(entry.key, entry.value)
+	}
+}
@@ -0,0 +1,297 @@
+package rate_limiter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"CloudBalancer/config"
+
+	"go.uber.org/zap"
+)
+
+var ErrStore = errors.New("rate limit store error")
+
+type OverrideStore interface {
+	Load() (map[string]UserLimits, error)
+	Save(clientID string, limits UserLimits) error
+	Delete(clientID string) error
+	LoadTiers() (map[string]string, error)
+	SaveTier(clientID, tier string) error
+	DeleteTier(clientID string) error
+	Close() error
+}
+
+func NewStore(cfg config.RateLimitStoreConfig) (OverrideStore, error) {
+	switch cfg.Type {
+	case "memory":
+		return memoryStore{}, nil
+	case "file":
+		return NewFileStore(cfg.Path)
+	case "bolt":
+		return NewBoltStore(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unknown rate limit store type: %s", cfg.Type)
+	}
+}
+
+type memoryStore struct{}
+
+func (memoryStore) Load() (map[string]UserLimits, error) {
+	return nil, nil
+}
+
+func (memoryStore) Save(string, UserLimits) error {
+	return nil
+}
+
+func (memoryStore) Delete(string) error {
+	return nil
+}
+
+func (memoryStore) LoadTiers() (map[string]string, error) {
+	return nil, nil
+}
+
+func (memoryStore) SaveTier(string, string) error {
+	return nil
+}
+
+func (memoryStore) DeleteTier(string) error {
+	return nil
+}
+
+func (memoryStore) Close() error {
+	return nil
+}
+
+type storeEntry struct {
+	*UserLimits
+	Tier string `json:"tier,omitempty"`
+}
+
+type FileStore struct {
+	path   string
+	limits map[string]UserLimits
+	tiers  map[string]string
+	mu     sync.Mutex
+}
+
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, limits: make(map[string]UserLimits), tiers: make(map[string]string)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit store: %w", err)
+	}
+	var entries map[string]storeEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit store %s: %w", path, err)
+	}
+	for clientID, entry := range entries {
+		if entry.UserLimits != nil {
+			s.limits[clientID] = *entry.UserLimits
+		}
+		if entry.Tier != "" {
+			s.tiers[clientID] = entry.Tier
+		}
+	}
+	return s, nil
+}
+
+func (s *FileStore) Load() (map[string]UserLimits, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limits := make(map[string]UserLimits, len(s.limits))
+	for clientID, l := range s.limits {
+		limits[clientID] = l
+	}
+	return limits, nil
+}
+
+func (s *FileStore) Save(clientID string, limits UserLimits) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.limits[clientID]
+	s.limits[clientID] = limits
+	if err := s.flush(); err != nil {
+		if existed {
+			s.limits[clientID] = previous
+		} else {
+			delete(s.limits, clientID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) Delete(clientID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.limits[clientID]
+	if !ok {
+		return nil
+	}
+	delete(s.limits, clientID)
+	if err := s.flush(); err != nil {
+		s.limits[clientID] = previous
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) LoadTiers() (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tiers := make(map[string]string, len(s.tiers))
+	for clientID, tier := range s.tiers {
+		tiers[clientID] = tier
+	}
+	return tiers, nil
+}
+
+func (s *FileStore) SaveTier(clientID, tier string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.tiers[clientID]
+	s.tiers[clientID] = tier
+	if err := s.flush(); err != nil {
+		if existed {
+			s.tiers[clientID] = previous
+		} else {
+			delete(s.tiers, clientID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) DeleteTier(clientID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.tiers[clientID]
+	if !ok {
+		return nil
+	}
+	delete(s.tiers, clientID)
+	if err := s.flush(); err != nil {
+		s.tiers[clientID] = previous
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) Close() error {
+	return nil
+}
+
+func (s *FileStore) flush() error {
+	entries := make(map[string]storeEntry, len(s.limits)+len(s.tiers))
+	for clientID, limits := range s.limits {
+		entries[clientID] = storeEntry{UserLimits: &limits}
+	}
+	for clientID, tier := range s.tiers {
+		entry := entries[clientID]
+		entry.Tier = tier
+		entries[clientID] = entry
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write rate limit store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write rate limit store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write rate limit store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write rate limit store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write rate limit store: %w", err)
+	}
+	return nil
+}
+
+type persistentLimiter struct {
+	RateLimiter
+	store  OverrideStore
+	static map[string]UserLimits
+	logger *zap.Logger
+}
+
+func NewPersistent(rl RateLimiter, store OverrideStore, clients []config.ClientLimitConfig, logger *zap.Logger) (RateLimiter, error) {
+	static := make(map[string]UserLimits)
+	for _, client := range clients {
+		if client.Tier == "" {
+			static[client.ID] = UserLimits{Rate: client.Rate, Burst: client.Burst}
+			rl.SetClientLimits(client.ID, client.Rate, client.Burst)
+		}
+	}
+	if len(static) > 0 {
+		logger.Info("Applied configured client rate limits", zap.Int("clients", len(static)))
+	}
+
+	overrides, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client rate limits: %w", err)
+	}
+
+	for clientID, limits := range overrides {
+		rl.SetClientLimits(clientID, limits.Rate, limits.Burst)
+	}
+	if len(overrides) > 0 {
+		logger.Info("Restored client rate limits", zap.Int("clients", len(overrides)))
+	}
+
+	return &persistentLimiter{RateLimiter: rl, store: store, static: static, logger: logger}, nil
+}
+
+func (p *persistentLimiter) SetClientLimits(clientID string, rate float64, burst int) error {
+	if err := p.store.Save(clientID, UserLimits{Rate: rate, Burst: burst}); err != nil {
+		return fmt.Errorf("%w: cannot save limits for %s: %w", ErrStore, clientID, err)
+	}
+	return p.RateLimiter.SetClientLimits(clientID, rate, burst)
+}
+
+func (p *persistentLimiter) UpdateClientLimits(clientID string, updateFn func(*UserLimits)) error {
+	limits := *p.RateLimiter.GetClientLimits(clientID)
+	updateFn(&limits)
+	if err := p.store.Save(clientID, limits); err != nil {
+		return fmt.Errorf("%w: cannot save limits for %s: %w", ErrStore, clientID, err)
+	}
+	return p.RateLimiter.SetClientLimits(clientID, limits.Rate, limits.Burst)
+}
+
+func (p *persistentLimiter) DeleteClientLimits(clientID string) error {
+	if err := p.store.Delete(clientID); err != nil {
+		return fmt.Errorf("%w: cannot delete limits for %s: %w", ErrStore, clientID, err)
+	}
+	if limits, ok := p.static[clientID]; ok {
+		return p.RateLimiter.SetClientLimits(clientID, limits.Rate, limits.Burst)
+	}
+	return p.RateLimiter.DeleteClientLimits(clientID)
+}
@@ -0,0 +1,277 @@
+package rate_limiter
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+
+	"CloudBalancer/config"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnknownTier = errors.New("unknown rate limit tier")
+	ErrTierInUse   = errors.New("rate limit tier has assigned clients")
+)
+
+type Tier struct {
+	Rate        float64 `json:"rate"`
+	Burst       int     `json:"burst"`
+	Concurrency int     `json:"concurrency"`
+}
+
+type TierInfo struct {
+	Name string `json:"name"`
+	Tier
+	Members int `json:"members"`
+}
+
+type Tiers struct {
+	tiers       map[string]Tier
+	members     map[string]string
+	rateLimiter RateLimiter
+	concurrency *ConcurrencyLimiter
+	store       OverrideStore
+	logger      *zap.Logger
+	mu          sync.Mutex
+}
+
+func NewTiers(cfg config.RateLimitConfig, rl RateLimiter, concurrency *ConcurrencyLimiter, store OverrideStore, logger *zap.Logger) (*Tiers, error) {
+	t := &Tiers{
+		tiers:       make(map[string]Tier, len(cfg.Tiers)),
+		members:     make(map[string]string),
+		rateLimiter: rl,
+		concurrency: concurrency,
+		store:       store,
+		logger:      logger,
+	}
+
+	for name, tier := range cfg.Tiers {
+		t.tiers[name] = Tier{Rate: tier.Rate, Burst: tier.Burst, Concurrency: tier.Concurrency}
+	}
+	for name, tier := range cfg.Tiers {
+		for _, clientID := range tier.Clients {
+			t.members[clientID] = name
+			if err := t.apply(clientID, t.tiers[name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, client := range cfg.Clients {
+		if client.Tier != "" {
+			t.members[client.ID] = client.Tier
+			if err := t.apply(client.ID, t.tiers[client.Tier]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	assigned, err := store.LoadTiers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rate limit tier assignments: %w", err)
+	}
+	restored := 0
+	for clientID, name := range assigned {
+		tier, ok := t.tiers[name]
+		if !ok {
+			logger.Warn("Skipping persisted assignment to unknown rate limit tier",
+				zap.String("clientID", clientID),
+				zap.String("tier", name),
+			)
+			continue
+		}
+		t.members[clientID] = name
+		if err := t.apply(clientID, tier); err != nil {
+			return nil, err
+		}
+		restored++
+	}
+	if restored > 0 {
+		logger.Info("Restored rate limit tier assignments", zap.Int("clients", restored))
+	}
+
+	return t, nil
+}
+
+func (t *Tiers) List() []TierInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := make(map[string]int, len(t.tiers))
+	for _, name := range t.members {
+		counts[name]++
+	}
+
+	infos := make([]TierInfo, 0, len(t.tiers))
+	for name, tier := range t.tiers {
+		infos = append(infos, TierInfo{Name: name, Tier: tier, Members: counts[name]})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+func (t *Tiers) Set(name string, tier Tier) error {
+	if name == "" {
+		return fmt.Errorf("tier name is required")
+	}
+	if tier.Rate <= 0 || tier.Burst <= 0 {
+		return fmt.Errorf("tier rate and burst must be positive")
+	}
+	if tier.Concurrency < 0 {
+		return fmt.Errorf("tier concurrency must be non-negative")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tiers[name] = tier
+	members := 0
+	var errs []error
+	for clientID, member := range t.members {
+		if member == name {
+			errs = append(errs, t.apply(clientID, tier))
+			members++
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	t.logger.Info("Rate limit tier updated",
+		zap.String("tier", name),
+		zap.Float64("rate", tier.Rate),
+		zap.Int("burst", tier.Burst),
+		zap.Int("concurrency", tier.Concurrency),
+		zap.Int("members", members),
+	)
+	return nil
+}
+
+func (t *Tiers) Delete(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.tiers[name]; !ok {
+		return ErrUnknownTier
+	}
+	for _, member := range t.members {
+		if member == name {
+			return ErrTierInUse
+		}
+	}
+	delete(t.tiers, name)
+	return nil
+}
+
+func (t *Tiers) Assign(clientID, name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tier, ok := t.tiers[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownTier, name)
+	}
+	if err := t.store.SaveTier(clientID, name); err != nil {
+		return fmt.Errorf("%w: cannot save tier assignment for %s: %w", ErrStore, clientID, err)
+	}
+	t.members[clientID] = name
+	return t.apply(clientID, tier)
+}
+
+func (t *Tiers) Unassign(clientID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.members[clientID]; !ok {
+		return nil
+	}
+	if err := t.store.DeleteTier(clientID); err != nil {
+		return fmt.Errorf("%w: cannot delete tier assignment for %s: %w", ErrStore, clientID, err)
+	}
+	delete(t.members, clientID)
+	t.concurrency.DeleteClientLimit(clientID)
+	return nil
+}
+
+func (t *Tiers) TierOf(clientID string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.members[clientID]
+}
+
+func (t *Tiers) CheckReload(old, new map[string]config.TierConfig) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.checkReload(old, new)
+}
+
+func (t *Tiers) checkReload(old, new map[string]config.TierConfig) error {
+	for clientID, name := range t.members {
+		if _, ok := new[name]; !ok && !slices.Contains(old[name].Clients, clientID) {
+			return fmt.Errorf("%w: %s", ErrTierInUse, name)
+		}
+	}
+	return nil
+}
+
+func (t *Tiers) Reload(old, new map[string]config.TierConfig) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.checkReload(old, new); err != nil {
+		return err
+	}
+
+	var errs []error
+	for name, tier := range old {
+		for _, clientID := range tier.Clients {
+			if t.members[clientID] == name && !slices.Contains(new[name].Clients, clientID) {
+				delete(t.members, clientID)
+				t.concurrency.DeleteClientLimit(clientID)
+				if err := t.rateLimiter.DeleteClientLimits(clientID); err != nil {
+					errs = append(errs, err)
+				}
+				if err := t.store.DeleteTier(clientID); err != nil {
+					errs = append(errs, fmt.Errorf("%w: cannot delete tier assignment for %s: %w", ErrStore, clientID, err))
+				}
+			}
+		}
+		if _, ok := new[name]; !ok {
+			delete(t.tiers, name)
+		}
+	}
+
+	for name, cfg := range new {
+		if previous, ok := old[name]; ok && reflect.DeepEqual(previous, cfg) {
+			continue
+		}
+		tier := Tier{Rate: cfg.Rate, Burst: cfg.Burst, Concurrency: cfg.Concurrency}
+		t.tiers[name] = tier
+		for _, clientID := range cfg.Clients {
+			t.members[clientID] = name
+		}
+		for clientID, member := range t.members {
+			if member == name {
+				errs = append(errs, t.apply(clientID, tier))
+			}
+		}
+	}
+
+	t.logger.Info("Rate limit tiers reloaded", zap.Int("tiers", len(t.tiers)), zap.Int("members", len(t.members)))
+	return errors.Join(errs...)
+}
+
+func (t *Tiers) apply(clientID string, tier Tier) error {
+	if tier.Concurrency > 0 {
+		t.concurrency.SetClientLimit(clientID, tier.Concurrency)
+	} else {
+		t.concurrency.DeleteClientLimit(clientID)
+	}
+	return t.rateLimiter.SetClientLimits(clientID, tier.Rate, tier.Burst)
+}
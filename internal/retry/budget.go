@@ -0,0 +1,63 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+const budgetWindow = 10
+
+type budgetBucket struct {
+	second   int64
+	requests int64
+	retries  int64
+}
+
+type Budget struct {
+	ratio        float64
+	minPerSecond int
+	buckets      [budgetWindow]budgetBucket
+	mu           sync.Mutex
+}
+
+func NewBudget(ratio float64, minPerSecond int) *Budget {
+	return &Budget{
+		ratio:        ratio,
+		minPerSecond: minPerSecond,
+	}
+}
+
+func (b *Budget) RecordRequest() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket(time.Now().Unix()).requests++
+}
+
+func (b *Budget) TryRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now().Unix()
+	var requests, retries int64
+	for _, bucket := range b.buckets {
+		if now-bucket.second < budgetWindow {
+			requests += bucket.requests
+			retries += bucket.retries
+		}
+	}
+
+	allowed := float64(b.minPerSecond*budgetWindow) + b.ratio*float64(requests)
+	if float64(retries) >= allowed {
+		return false
+	}
+	b.bucket(now).retries++
+	return true
+}
+
+func (b *Budget) bucket(second int64) *budgetBucket {
+	bucket := &b.buckets[second%budgetWindow]
+	if bucket.second != second {
+		*bucket = budgetBucket{second: second}
+	}
+	return bucket
+}
@@ -0,0 +1,39 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+)
+
+var ErrPerTryTimeout = fmt.Errorf("backend attempt exceeded per-try timeout: %w", context.DeadlineExceeded)
+
+type Policy struct {
+	Attempts          int
+	Methods           []string
+	IdempotencyHeader string
+	PerTryTimeout     time.Duration
+	Budget            *Budget
+}
+
+func (p *Policy) Allows(r *http.Request) bool {
+	if p.Attempts <= 0 {
+		return false
+	}
+	if slices.Contains(p.Methods, r.Method) {
+		return true
+	}
+	return p.IdempotencyHeader != "" && r.Header.Get(p.IdempotencyHeader) != ""
+}
+
+func (p *Policy) RecordRequest() {
+	if p.Budget != nil {
+		p.Budget.RecordRequest()
+	}
+}
+
+func (p *Policy) TryRetry() bool {
+	return p.Budget == nil || p.Budget.TryRetry()
+}
@@ -0,0 +1,100 @@
+package rewrite
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"CloudBalancer/config"
+)
+
+type Rule struct {
+	Name        string
+	target      string
+	prefix      string
+	regex       *regexp.Regexp
+	replacement string
+}
+
+type Engine struct {
+	rules       []*Rule
+	debugHeader string
+}
+
+func NewEngine(cfg config.RewriteConfig) (*Engine, error) {
+	e := &Engine{debugHeader: cfg.DebugHeader}
+
+	for _, ruleConfig := range cfg.Rules {
+		rule := &Rule{
+			Name:        ruleConfig.Name,
+			target:      ruleConfig.Target,
+			prefix:      ruleConfig.Prefix,
+			replacement: ruleConfig.Replacement,
+		}
+		if ruleConfig.Regex != "" {
+			regex, err := regexp.Compile(ruleConfig.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex in rewrite rule %s: %w", ruleConfig.Name, err)
+			}
+			rule.regex = regex
+		}
+		e.rules = append(e.rules, rule)
+	}
+
+	return e, nil
+}
+
+func (e *Engine) DebugHeader() string {
+	return e.debugHeader
+}
+
+func (e *Engine) Apply(u *url.URL) []string {
+	var applied []string
+	for _, rule := range e.rules {
+		if rule.apply(u) {
+			applied = append(applied, rule.Name)
+		}
+	}
+	return applied
+}
+
+func (e *Engine) Request(r *http.Request) (*http.Request, []string) {
+	rewritten := *r.URL
+	applied := e.Apply(&rewritten)
+	if len(applied) == 0 {
+		return r, nil
+	}
+	r = r.WithContext(r.Context())
+	r.URL = &rewritten
+	return r, applied
+}
+
+func (r *Rule) apply(u *url.URL) bool {
+	value := u.Path
+	if r.target == "query" {
+		value = u.RawQuery
+	}
+
+	var rewritten string
+	switch {
+	case r.regex != nil:
+		if !r.regex.MatchString(value) {
+			return false
+		}
+		rewritten = r.regex.ReplaceAllString(value, r.replacement)
+	case strings.HasPrefix(value, r.prefix):
+		rewritten = r.replacement + strings.TrimPrefix(value, r.prefix)
+	default:
+		return false
+	}
+
+	if r.target == "query" {
+		u.RawQuery = rewritten
+	} else {
+		u.Path = rewritten
+		u.RawPath = ""
+	}
+	return true
+}
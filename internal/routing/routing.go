@@ -0,0 +1,311 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/accesslog"
+	"CloudBalancer/internal/headers"
+	"CloudBalancer/internal/hedge"
+	"CloudBalancer/internal/retry"
+	"CloudBalancer/internal/static"
+)
+
+const (
+	defaultHedgePercentile = 95
+	defaultHedgeDelay      = 100 * time.Millisecond
+)
+
+type Route struct {
+	Host          string
+	Path          string
+	PathRegex     *regexp.Regexp
+	Rewrite       string
+	Pool          string
+	Methods       []string
+	MatchHeaders  map[string]string
+	Timeout       time.Duration
+	Streaming     bool
+	MaxBodySize   int64
+	Retry         *retry.Policy
+	BufferMaxSize int64
+	CacheTTL      time.Duration
+	Hedge         *hedge.Policy
+	Static        *static.Server
+	Cost          int
+
+	RequestHeaders  *headers.Rules
+	ResponseHeaders *headers.Rules
+	AccessLog       *accesslog.RoutePolicy
+}
+
+type Table struct {
+	cfg          *config.Config
+	configs      []config.RouteConfig
+	routes       []*Route
+	defaultRoute *Route
+	mu           sync.RWMutex
+}
+
+func NewTable(cfg *config.Config) (*Table, error) {
+	t := &Table{cfg: cfg}
+
+	var err error
+	t.defaultRoute, err = t.buildRoute(config.RouteConfig{Path: "/", Pool: config.DefaultPool})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.SetRoutes(cfg.Routes); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *Table) buildRoute(routeConfig config.RouteConfig) (*Route, error) {
+	route := &Route{
+		Host:        strings.ToLower(routeConfig.Host),
+		Path:        routeConfig.Path,
+		Rewrite:     routeConfig.Rewrite,
+		Pool:        routeConfig.Pool,
+		Timeout:     t.cfg.LoadBalancer.RequestTimeout,
+		Streaming:   routeConfig.Streaming,
+		MaxBodySize: t.cfg.LoadBalancer.MaxBodySize,
+		Retry:       t.buildRetryPolicy(routeConfig.Retry),
+		Cost:        max(routeConfig.Cost, 1),
+	}
+	if t.cfg.LoadBalancer.RequestBuffering.Enabled {
+		route.BufferMaxSize = t.cfg.LoadBalancer.RequestBuffering.MaxSize
+	}
+	if t.cfg.Cache.Enabled {
+		route.CacheTTL = t.cfg.Cache.DefaultTTL
+	}
+
+	for _, method := range routeConfig.Methods {
+		route.Methods = append(route.Methods, strings.ToUpper(method))
+	}
+	route.MatchHeaders = routeConfig.MatchHeaders
+
+	if routeConfig.PathRegex != "" {
+		pathRegex, err := regexp.Compile(routeConfig.PathRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path regex %q: %w", routeConfig.PathRegex, err)
+		}
+		route.PathRegex = pathRegex
+	}
+
+	if routeConfig.Timeout > 0 {
+		route.Timeout = routeConfig.Timeout
+	}
+	if routeConfig.MaxBodySize > 0 {
+		route.MaxBodySize = routeConfig.MaxBodySize
+	}
+	if routeConfig.CacheTTL > 0 {
+		route.CacheTTL = routeConfig.CacheTTL
+	}
+	if routeConfig.Hedge.Enabled {
+		percentile, delay := routeConfig.Hedge.Percentile, routeConfig.Hedge.Delay
+		if percentile == 0 {
+			percentile = defaultHedgePercentile
+		}
+		if delay == 0 {
+			delay = defaultHedgeDelay
+		}
+		route.Hedge = hedge.NewPolicy(percentile, delay)
+	}
+	if routeConfig.Static.Root != "" {
+		staticServer, err := static.NewServer(routeConfig.Static, routeConfig.Path)
+		if err != nil {
+			return nil, err
+		}
+		route.Static = staticServer
+	}
+	route.RequestHeaders = headers.NewRules(routeConfig.Headers.Request)
+	route.ResponseHeaders = headers.NewRules(routeConfig.Headers.Response)
+	route.AccessLog = accesslog.NewRoutePolicy(routeConfig.AccessLog)
+
+	return route, nil
+}
+
+func (t *Table) buildRetryPolicy(routeRetry config.RouteRetryConfig) *retry.Policy {
+	retryConfig := t.cfg.LoadBalancer.Retry
+	policy := &retry.Policy{
+		Attempts:          retryConfig.Attempts,
+		Methods:           retryConfig.Methods,
+		IdempotencyHeader: retryConfig.IdempotencyHeader,
+		PerTryTimeout:     retryConfig.PerTryTimeout,
+	}
+	if routeRetry.Attempts != nil {
+		policy.Attempts = *routeRetry.Attempts
+	}
+	if len(routeRetry.Methods) > 0 {
+		policy.Methods = routeRetry.Methods
+	}
+	if routeRetry.PerTryTimeout > 0 {
+		policy.PerTryTimeout = routeRetry.PerTryTimeout
+	}
+	if retryConfig.Budget.Enabled {
+		policy.Budget = retry.NewBudget(retryConfig.Budget.Ratio, retryConfig.Budget.MinPerSecond)
+	}
+	return policy
+}
+
+func (t *Table) SetRoutes(routeConfigs []config.RouteConfig) error {
+	routes := make([]*Route, 0, len(routeConfigs))
+	for _, routeConfig := range routeConfigs {
+		route, err := t.buildRoute(routeConfig)
+		if err != nil {
+			return err
+		}
+		routes = append(routes, route)
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if hi, hj := hostSpecificity(routes[i].Host), hostSpecificity(routes[j].Host); hi != hj {
+			return hi > hj
+		}
+		if ci, cj := routes[i].conditions(), routes[j].conditions(); ci != cj {
+			return ci > cj
+		}
+		if (routes[i].PathRegex != nil) != (routes[j].PathRegex != nil) {
+			return routes[i].PathRegex != nil
+		}
+		return len(routes[i].Path) > len(routes[j].Path)
+	})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.configs = append([]config.RouteConfig(nil), routeConfigs...)
+	t.routes = routes
+	return nil
+}
+
+func (t *Table) Routes() []config.RouteConfig {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]config.RouteConfig(nil), t.configs...)
+}
+
+func (t *Table) Match(r *http.Request) *Route {
+	host := requestHost(r)
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, route := range t.routes {
+		if route.matches(r, host) {
+			return route
+		}
+	}
+	return t.defaultRoute
+}
+
+func (route *Route) matches(r *http.Request, host string) bool {
+	if !matchHost(route.Host, host) {
+		return false
+	}
+	if route.PathRegex != nil {
+		if !route.PathRegex.MatchString(r.URL.Path) {
+			return false
+		}
+	} else if !strings.HasPrefix(r.URL.Path, route.Path) {
+		return false
+	}
+	if len(route.Methods) > 0 && !slices.Contains(route.Methods, r.Method) {
+		return false
+	}
+	for name, value := range route.MatchHeaders {
+		values := r.Header.Values(name)
+		if len(values) == 0 {
+			return false
+		}
+		if value != "" && value != "*" && !slices.Contains(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func (route *Route) RewritePath(r *http.Request) bool {
+	if route.PathRegex == nil || route.Rewrite == "" {
+		return false
+	}
+
+	match := route.PathRegex.FindStringSubmatchIndex(r.URL.Path)
+	if match == nil {
+		return false
+	}
+
+	rewritten := string(route.PathRegex.ExpandString(nil, route.Rewrite, r.URL.Path, match))
+	path, query, hasQuery := strings.Cut(rewritten, "?")
+
+	u := *r.URL
+	if hasQuery {
+		if u.RawQuery != "" {
+			query += "&" + u.RawQuery
+		}
+		u.RawQuery = query
+	}
+	u.Path = path
+	u.RawPath = ""
+	r.URL = &u
+
+	return true
+}
+
+func (route *Route) conditions() int {
+	n := len(route.MatchHeaders)
+	if len(route.Methods) > 0 {
+		n++
+	}
+	return n
+}
+
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+func matchHost(pattern, host string) bool {
+	if pattern == "" {
+		return true
+	}
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(host, suffix) && len(host) > len(suffix)
+	}
+	return pattern == host
+}
+
+func hostSpecificity(pattern string) int {
+	switch {
+	case pattern == "":
+		return 0
+	case strings.HasPrefix(pattern, "*"):
+		return len(pattern)
+	default:
+		return 1 << 16
+	}
+}
+
+type routeKey struct{}
+
+func WithRoute(ctx context.Context, route *Route) context.Context {
+	return context.WithValue(ctx, routeKey{}, route)
+}
+
+func RouteFromContext(ctx context.Context) *Route {
+	route, _ := ctx.Value(routeKey{}).(*Route)
+	return route
+}
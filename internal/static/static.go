@@ -0,0 +1,128 @@
+package static
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"CloudBalancer/config"
+)
+
+const (
+	defaultIndex        = "index.html"
+	defaultCacheControl = "public, max-age=300"
+	indexCacheControl   = "no-cache"
+)
+
+var ErrHidden = errors.New("hidden files are not served")
+
+type Server struct {
+	root         string
+	index        string
+	spa          bool
+	cacheControl string
+	stripPrefix  string
+}
+
+type File struct {
+	*os.File
+	Info         fs.FileInfo
+	CacheControl string
+}
+
+func NewServer(cfg config.StaticConfig, routePath string) (*Server, error) {
+	info, err := os.Stat(cfg.Root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid static root %q: %w", cfg.Root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("invalid static root %q: not a directory", cfg.Root)
+	}
+
+	s := &Server{
+		root:         cfg.Root,
+		index:        cfg.Index,
+		spa:          cfg.SPA,
+		cacheControl: cfg.CacheControl,
+	}
+	if s.index == "" {
+		s.index = defaultIndex
+	}
+	if s.cacheControl == "" {
+		s.cacheControl = defaultCacheControl
+	}
+	if cfg.StripPrefix {
+		s.stripPrefix = strings.TrimSuffix(routePath, "/")
+	}
+	return s, nil
+}
+
+func (s *Server) Open(urlPath string) (*File, error) {
+	name := path.Clean("/" + strings.TrimPrefix(urlPath, s.stripPrefix))
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return nil, ErrHidden
+		}
+	}
+
+	root, err := os.OpenRoot(s.root)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		name = "."
+	}
+
+	file, err := s.open(root, name)
+	if errors.Is(err, fs.ErrNotExist) && s.spa && path.Ext(name) == "" {
+		file, err = s.open(root, s.index)
+	}
+	return file, err
+}
+
+func (s *Server) open(root *os.Root, name string) (*File, error) {
+	f, err := root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && info.IsDir() {
+		f.Close()
+		name = path.Join(name, s.index)
+		if f, err = root.Open(name); err != nil {
+			return nil, err
+		}
+		info, err = f.Stat()
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil, fs.ErrNotExist
+	}
+
+	cacheControl := s.cacheControl
+	if path.Base(name) == s.index {
+		cacheControl = indexCacheControl
+	}
+	return &File{File: f, Info: info, CacheControl: cacheControl}, nil
+}
+
+func (f *File) Serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", f.CacheControl)
+	w.Header().Set("ETag", fmt.Sprintf(`W/"%s-%s"`,
+		strconv.FormatInt(f.Info.ModTime().UnixNano(), 36),
+		strconv.FormatInt(f.Info.Size(), 36),
+	))
+	http.ServeContent(w, r, f.Info.Name(), f.Info.ModTime(), f.File)
+}
@@ -0,0 +1,18 @@
+package tlsconfig
+
+import (
+	"CloudBalancer/config"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func NewACMEManager(cfg config.ACMEConfig) *autocert.Manager {
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Email:      cfg.Email,
+		Client:     &acme.Client{DirectoryURL: cfg.DirectoryURL},
+	}
+}
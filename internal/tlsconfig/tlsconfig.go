@@ -0,0 +1,256 @@
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+const reloadCheckInterval = 5 * time.Second
+
+type Store struct {
+	certFile  string
+	keyFile   string
+	caFile    string
+	cert      *tls.Certificate
+	pool      *x509.CertPool
+	modTimes  map[string]time.Time
+	checkedAt time.Time
+	logger    *zap.Logger
+	mu        sync.Mutex
+}
+
+func NewStore(certFile, keyFile, caFile string, logger *zap.Logger) (*Store, error) {
+	s := &Store{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		logger:   logger,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) files() []string {
+	var files []string
+	for _, file := range []string{s.certFile, s.keyFile, s.caFile} {
+		if file != "" && !config.IsInlinePEM(file) {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+func (s *Store) load() error {
+	modTimes := make(map[string]time.Time)
+	for _, file := range s.files() {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		modTimes[file] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if s.certFile != "" {
+		pair, err := config.LoadX509KeyPair(s.certFile, s.keyFile)
+		if err != nil {
+			return fmt.Errorf("cannot load certificate: %w", err)
+		}
+		cert = &pair
+	}
+	var pool *x509.CertPool
+	if s.caFile != "" {
+		var err error
+		if pool, err = config.LoadCertPool(s.caFile); err != nil {
+			return err
+		}
+	}
+
+	s.cert, s.pool, s.modTimes = cert, pool, modTimes
+	return nil
+}
+
+func (s *Store) refresh() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.checkedAt) < reloadCheckInterval {
+		return
+	}
+	s.checkedAt = time.Now()
+
+	changed := false
+	for _, file := range s.files() {
+		if info, err := os.Stat(file); err == nil && !info.ModTime().Equal(s.modTimes[file]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	if err := s.load(); err != nil {
+		s.logger.Warn("Failed to reload TLS files, keeping the previous ones",
+			zap.Strings("files", s.files()),
+			zap.Error(err),
+		)
+		return
+	}
+	s.logger.Info("Reloaded TLS files", zap.Strings("files", s.files()))
+}
+
+func (s *Store) Certificate() *tls.Certificate {
+	s.refresh()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cert
+}
+
+func (s *Store) CertPool() *x509.CertPool {
+	s.refresh()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pool
+}
+
+func NewServerConfig(cfg config.TLSConfig, manager *autocert.Manager, logger *zap.Logger) (*tls.Config, error) {
+	minVersion, err := config.ParseTLSVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := config.ParseCipherSuites(cfg.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	store, err := NewStore(cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			static := store.Certificate()
+			if manager != nil {
+				if cert, err := manager.GetCertificate(hello); err == nil || static == nil {
+					return cert, err
+				}
+			}
+			return static, nil
+		},
+	}
+	if manager != nil {
+		tlsConfig.NextProtos = []string{acme.ALPNProto}
+	}
+	if cfg.ClientCAFile == "" || cfg.ClientAuth == "none" {
+		return tlsConfig, nil
+	}
+
+	tlsConfig.ClientAuth = tls.RequireAnyClientCert
+	if cfg.ClientAuth == "optional" {
+		tlsConfig.ClientAuth = tls.RequestClientCert
+	}
+	tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			return nil
+		}
+		return verifyChain(state.PeerCertificates, x509.VerifyOptions{
+			Roots:     store.CertPool(),
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+	}
+	if manager != nil {
+		challenge := tlsConfig.Clone()
+		challenge.ClientAuth = tls.NoClientCert
+		challenge.VerifyConnection = nil
+		tlsConfig.GetConfigForClient = func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			if slices.Equal(hello.SupportedProtos, []string{acme.ALPNProto}) {
+				return challenge, nil
+			}
+			return nil, nil
+		}
+	}
+	return tlsConfig, nil
+}
+
+type ClientConfig struct {
+	base   *tls.Config
+	store  *Store
+	verify bool
+}
+
+func NewClientConfig(cfg config.BackendTLSConfig, logger *zap.Logger) (*ClientConfig, error) {
+	minVersion, err := config.ParseTLSVersion(cfg.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	store, err := NewStore(cfg.CertFile, cfg.KeyFile, cfg.CAFile, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	base := &tls.Config{
+		MinVersion:         minVersion,
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CertFile != "" {
+		base.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return store.Certificate(), nil
+		}
+	}
+	return &ClientConfig{
+		base:   base,
+		store:  store,
+		verify: cfg.CAFile != "" && !cfg.InsecureSkipVerify,
+	}, nil
+}
+
+func (c *ClientConfig) ForHost(host string) *tls.Config {
+	tlsConfig := c.base.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = host
+	}
+	if !c.verify {
+		return tlsConfig
+	}
+
+	name := tlsConfig.ServerName
+	tlsConfig.InsecureSkipVerify = true
+	tlsConfig.VerifyConnection = func(state tls.ConnectionState) error {
+		if name == "" {
+			return fmt.Errorf("no server name to verify the backend certificate against")
+		}
+		if len(state.PeerCertificates) == 0 {
+			return fmt.Errorf("backend presented no certificate")
+		}
+		return verifyChain(state.PeerCertificates, x509.VerifyOptions{
+			DNSName: name,
+			Roots:   c.store.CertPool(),
+		})
+	}
+	return tlsConfig
+}
+
+func verifyChain(certs []*x509.Certificate, opts x509.VerifyOptions) error {
+	opts.Intermediates = x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(opts)
+	return err
+}
@@ -0,0 +1,102 @@
+package topk
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+)
+
+type Entry struct {
+	Key   string  `json:"key"`
+	Value float64 `json:"value"`
+	Count uint64  `json:"count"`
+	Error float64 `json:"error"`
+}
+
+type item struct {
+	Entry
+	index int
+}
+
+type minHeap []*item
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].Value < h[j].Value }
+func (h minHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *minHeap) Push(x any) {
+	it := x.(*item)
+	it.index = len(*h)
+	*h = append(*h, it)
+}
+func (h *minHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
+
+type Sketch struct {
+	capacity int
+	items    map[string]*item
+	heap     minHeap
+	mu       sync.Mutex
+}
+
+func NewSketch(capacity int) *Sketch {
+	return &Sketch{
+		capacity: capacity,
+		items:    make(map[string]*item, capacity),
+	}
+}
+
+func (s *Sketch) Add(key string, weight float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if it, ok := s.items[key]; ok {
+		it.Value += weight
+		it.Count++
+		heap.Fix(&s.heap, it.index)
+		return
+	}
+
+	if len(s.heap) < s.capacity {
+		it := &item{Entry: Entry{Key: key, Value: weight, Count: 1}}
+		s.items[key] = it
+		heap.Push(&s.heap, it)
+		return
+	}
+
+	evicted := s.heap[0]
+	delete(s.items, evicted.Key)
+	evicted.Error = evicted.Value
+	evicted.Key = key
+	evicted.Value += weight
+	evicted.Count = 1
+	s.items[key] = evicted
+	heap.Fix(&s.heap, 0)
+}
+
+func (s *Sketch) Top(n int) []Entry {
+	s.mu.Lock()
+	entries := make([]Entry, len(s.heap))
+	for i, it := range s.heap {
+		entries[i] = it.Entry
+	}
+	s.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Value != entries[j].Value {
+			return entries[i].Value > entries[j].Value
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
@@ -0,0 +1,115 @@
+package topk
+
+import (
+	"sync"
+	"time"
+)
+
+type window struct {
+	start            time.Time
+	end              time.Time
+	clientRequests   *Sketch
+	clientRejections *Sketch
+	pathLatency      *Sketch
+	pathErrors       *Sketch
+}
+
+func newWindow(capacity int, start time.Time) *window {
+	return &window{
+		start:            start,
+		clientRequests:   NewSketch(capacity),
+		clientRejections: NewSketch(capacity),
+		pathLatency:      NewSketch(capacity),
+		pathErrors:       NewSketch(capacity),
+	}
+}
+
+type Report struct {
+	Start               time.Time `json:"start"`
+	End                 time.Time `json:"end"`
+	ClientsByRequests   []Entry   `json:"clients_by_requests"`
+	ClientsByRejections []Entry   `json:"clients_by_rejections"`
+	PathsByLatency      []Entry   `json:"paths_by_latency"`
+	PathsByErrors       []Entry   `json:"paths_by_errors"`
+}
+
+type Tracker struct {
+	capacity int
+	window   time.Duration
+	current  *window
+	previous *window
+	mu       sync.Mutex
+}
+
+func NewTracker(capacity int, window time.Duration) *Tracker {
+	return &Tracker{
+		capacity: capacity,
+		window:   window,
+		current:  newWindow(capacity, time.Now().Truncate(window)),
+	}
+}
+
+func (t *Tracker) active() *window {
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.current.start) >= t.window {
+		t.current.end = t.current.start.Add(t.window)
+		t.previous = t.current
+		if now.Sub(t.previous.end) >= t.window {
+			t.previous = nil
+		}
+		t.current = newWindow(t.capacity, now.Truncate(t.window))
+	}
+	return t.current
+}
+
+func (t *Tracker) ObserveClient(clientID string, rejected bool) {
+	w := t.active()
+	w.clientRequests.Add(clientID, 1)
+	if rejected {
+		w.clientRejections.Add(clientID, 1)
+	}
+}
+
+func (t *Tracker) ObservePath(path string, latency time.Duration, failed bool) {
+	w := t.active()
+	w.pathLatency.Add(path, float64(latency)/float64(time.Millisecond))
+	if failed {
+		w.pathErrors.Add(path, 1)
+	}
+}
+
+func (t *Tracker) Window() time.Duration {
+	return t.window
+}
+
+func (t *Tracker) Report(n int, previous bool) (Report, bool) {
+	current := t.active()
+
+	t.mu.Lock()
+	w := current
+	if previous {
+		w = t.previous
+	}
+	t.mu.Unlock()
+
+	if w == nil {
+		return Report{}, false
+	}
+
+	end := w.end
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return Report{
+		Start:               w.start,
+		End:                 end,
+		ClientsByRequests:   w.clientRequests.Top(n),
+		ClientsByRejections: w.clientRejections.Top(n),
+		PathsByLatency:      w.pathLatency.Top(n),
+		PathsByErrors:       w.pathErrors.Top(n),
+	}, true
+}
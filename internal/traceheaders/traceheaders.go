@@ -0,0 +1,141 @@
+package traceheaders
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/requestid"
+)
+
+const (
+	PolicyTrust    = "trust"
+	PolicyGenerate = "generate"
+	PolicyStrip    = "strip"
+)
+
+const (
+	traceparentHeader = "Traceparent"
+	tracestateHeader  = "Tracestate"
+	b3Header          = "B3"
+	b3TraceIDHeader   = "X-B3-Traceid"
+	b3SpanIDHeader    = "X-B3-Spanid"
+)
+
+var b3Headers = []string{b3Header, b3TraceIDHeader, b3SpanIDHeader, "X-B3-Parentspanid", "X-B3-Sampled", "X-B3-Flags"}
+
+type Policy struct {
+	requestID   string
+	traceparent string
+	b3          string
+}
+
+func NewPolicy(cfg config.TraceHeadersConfig) *Policy {
+	return &Policy{
+		requestID:   cfg.RequestID,
+		traceparent: cfg.Traceparent,
+		b3:          cfg.B3,
+	}
+}
+
+func (p *Policy) RequestID(r *http.Request) string {
+	if p.requestID == PolicyGenerate {
+		return requestid.New()
+	}
+	return requestid.FromRequest(r)
+}
+
+func (p *Policy) Apply(h http.Header) {
+	switch p.traceparent {
+	case PolicyTrust:
+		if !validTraceparent(h.Get(traceparentHeader)) {
+			h.Del(traceparentHeader)
+			h.Del(tracestateHeader)
+		}
+	case PolicyGenerate:
+		h.Del(tracestateHeader)
+		h.Set(traceparentHeader, "00-"+randomHex(16)+"-"+randomHex(8)+"-01")
+	case PolicyStrip:
+		h.Del(traceparentHeader)
+		h.Del(tracestateHeader)
+	}
+
+	switch p.b3 {
+	case PolicyTrust:
+		if !validB3(h) {
+			deleteAll(h, b3Headers)
+		}
+	case PolicyGenerate:
+		deleteAll(h, b3Headers)
+		h.Set(b3TraceIDHeader, randomHex(16))
+		h.Set(b3SpanIDHeader, randomHex(8))
+	case PolicyStrip:
+		deleteAll(h, b3Headers)
+	}
+}
+
+func validTraceparent(value string) bool {
+	parts := strings.Split(value, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return false
+	}
+	return isHex(parts[0]) && isHex(parts[1]) && len(parts[1]) == 32 && !isZero(parts[1]) &&
+		isHex(parts[2]) && len(parts[2]) == 16 && !isZero(parts[2]) &&
+		isHex(parts[3]) && len(parts[3]) == 2
+}
+
+func validB3(h http.Header) bool {
+	if single := h.Get(b3Header); single != "" {
+		parts := strings.Split(single, "-")
+		if len(parts) == 1 {
+			return parts[0] == "0" || parts[0] == "1" || parts[0] == "d"
+		}
+		if !validB3TraceID(parts[0]) || !validB3SpanID(parts[1]) {
+			return false
+		}
+	}
+	traceID, spanID := h.Get(b3TraceIDHeader), h.Get(b3SpanIDHeader)
+	if traceID == "" && spanID == "" {
+		return true
+	}
+	return validB3TraceID(traceID) && validB3SpanID(spanID)
+}
+
+func validB3TraceID(id string) bool {
+	return (len(id) == 16 || len(id) == 32) && isHex(id) && !isZero(id)
+}
+
+func validB3SpanID(id string) bool {
+	return len(id) == 16 && isHex(id) && !isZero(id)
+}
+
+func isHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func deleteAll(h http.Header, names []string) {
+	for _, name := range names {
+		h.Del(name)
+	}
+}
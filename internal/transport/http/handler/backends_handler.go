@@ -0,0 +1,371 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/load_balancer"
+	"CloudBalancer/internal/load_balancer/backend"
+
+	"go.uber.org/zap"
+)
+
+type backendSpec struct {
+	ID             string                   `json:"id"`
+	Host           string                   `json:"host"`
+	Port           int                      `json:"port"`
+	Protocol       string                   `json:"protocol,omitempty"`
+	Group          string                   `json:"group,omitempty"`
+	Pool           string                   `json:"pool,omitempty"`
+	ConnectTimeout string                   `json:"connect_timeout,omitempty"`
+	ReadTimeout    string                   `json:"read_timeout,omitempty"`
+	MaxConnections int                      `json:"max_connections,omitempty"`
+	ProxyProtocol  string                   `json:"proxy_protocol,omitempty"`
+	HealthCheck    *backendHealthCheckSpec  `json:"health_check,omitempty"`
+	RateLimit      *backendRateLimitSpec    `json:"rate_limit,omitempty"`
+	Headers        config.HeaderRulesConfig `json:"headers"`
+}
+
+type backendHealthCheckSpec struct {
+	Host             string   `json:"host,omitempty"`
+	Port             int      `json:"port,omitempty"`
+	Type             string   `json:"type,omitempty"`
+	Path             string   `json:"path,omitempty"`
+	Method           string   `json:"method,omitempty"`
+	Interval         string   `json:"interval,omitempty"`
+	Timeout          string   `json:"timeout,omitempty"`
+	Rise             int      `json:"rise,omitempty"`
+	Fall             int      `json:"fall,omitempty"`
+	ExpectedStatuses []string `json:"expected_statuses,omitempty"`
+}
+
+type backendRateLimitSpec struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst,omitempty"`
+}
+
+type backendView struct {
+	backendSpec
+	URL               string `json:"url"`
+	Healthy           bool   `json:"healthy"`
+	Draining          bool   `json:"draining"`
+	ActiveConnections int64  `json:"active_connections"`
+	ActiveWebSockets  int64  `json:"active_websockets"`
+	TotalRequests     int64  `json:"total_requests"`
+	FailedRequests    int64  `json:"failed_requests"`
+}
+
+func newBackendSpec(cfg config.BackendConfig) backendSpec {
+	spec := backendSpec{
+		ID:             cfg.ID,
+		Host:           cfg.Host,
+		Port:           cfg.Port,
+		Protocol:       cfg.Protocol,
+		Group:          cfg.Group,
+		Pool:           cfg.Pool,
+		MaxConnections: cfg.MaxConnection,
+		ProxyProtocol:  cfg.ProxyProtocol,
+		Headers:        cfg.Headers,
+	}
+	if cfg.ConnectTimeout > 0 {
+		spec.ConnectTimeout = cfg.ConnectTimeout.String()
+	}
+	if cfg.ReadTimeout > 0 {
+		spec.ReadTimeout = cfg.ReadTimeout.String()
+	}
+	if hc := cfg.HealthCheck; !reflect.DeepEqual(hc, config.BackendHealthCheckConfig{}) {
+		spec.HealthCheck = &backendHealthCheckSpec{
+			Host:             hc.Host,
+			Port:             hc.Port,
+			Type:             hc.Type,
+			Path:             hc.Path,
+			Method:           hc.Method,
+			Rise:             hc.Rise,
+			Fall:             hc.Fall,
+			ExpectedStatuses: hc.ExpectedStatuses,
+		}
+		if hc.Interval > 0 {
+			spec.HealthCheck.Interval = hc.Interval.String()
+		}
+		if hc.Timeout > 0 {
+			spec.HealthCheck.Timeout = hc.Timeout.String()
+		}
+	}
+	if cfg.RateLimit.Rate > 0 {
+		spec.RateLimit = &backendRateLimitSpec{Rate: cfg.RateLimit.Rate, Burst: cfg.RateLimit.Burst}
+	}
+	return spec
+}
+
+func newBackendView(b *backend.Backend, cfg config.BackendConfig) backendView {
+	return backendView{
+		backendSpec:       newBackendSpec(cfg),
+		URL:               b.URL.String(),
+		Healthy:           b.IsHealthy(),
+		Draining:          b.IsDraining(),
+		ActiveConnections: b.ActiveConnections(),
+		ActiveWebSockets:  b.ActiveWebSockets(),
+		TotalRequests:     b.TotalRequests(),
+		FailedRequests:    b.FailedRequests(),
+	}
+}
+
+func (s backendSpec) toConfig() (config.BackendConfig, []config.DefaultedField, error) {
+	cfg := config.BackendConfig{
+		ID:            s.ID,
+		Host:          s.Host,
+		Port:          s.Port,
+		MaxConnection: s.MaxConnections,
+		Enabled:       true,
+		Protocol:      s.Protocol,
+		Group:         s.Group,
+		Pool:          s.Pool,
+		Headers:       s.Headers,
+		ProxyProtocol: s.ProxyProtocol,
+	}
+	if cfg.Protocol == "" {
+		cfg.Protocol = "http"
+	}
+	if cfg.Group == "" {
+		cfg.Group = "stable"
+	}
+	if cfg.Pool == "" {
+		cfg.Pool = config.DefaultPool
+	}
+	if s.HealthCheck != nil {
+		cfg.HealthCheck = config.BackendHealthCheckConfig{
+			Host:             s.HealthCheck.Host,
+			Port:             s.HealthCheck.Port,
+			Type:             s.HealthCheck.Type,
+			Path:             s.HealthCheck.Path,
+			Method:           s.HealthCheck.Method,
+			Rise:             s.HealthCheck.Rise,
+			Fall:             s.HealthCheck.Fall,
+			ExpectedStatuses: s.HealthCheck.ExpectedStatuses,
+		}
+	}
+	if s.RateLimit != nil {
+		cfg.RateLimit = config.BackendRateLimitConfig{Rate: s.RateLimit.Rate, Burst: s.RateLimit.Burst}
+	}
+
+	if strings.TrimSpace(cfg.Host) == "" {
+		return cfg, nil, fmt.Errorf("host is required")
+	}
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		return cfg, nil, fmt.Errorf("invalid port: %d", cfg.Port)
+	}
+
+	var err error
+	if s.ConnectTimeout != "" {
+		if cfg.ConnectTimeout, err = time.ParseDuration(s.ConnectTimeout); err != nil {
+			return cfg, nil, fmt.Errorf("invalid connect timeout %q: %w", s.ConnectTimeout, err)
+		}
+	}
+	if s.ReadTimeout != "" {
+		if cfg.ReadTimeout, err = time.ParseDuration(s.ReadTimeout); err != nil {
+			return cfg, nil, fmt.Errorf("invalid read timeout %q: %w", s.ReadTimeout, err)
+		}
+	}
+	if s.HealthCheck != nil && s.HealthCheck.Interval != "" {
+		if cfg.HealthCheck.Interval, err = time.ParseDuration(s.HealthCheck.Interval); err != nil {
+			return cfg, nil, fmt.Errorf("invalid health check interval %q: %w", s.HealthCheck.Interval, err)
+		}
+	}
+	if s.HealthCheck != nil && s.HealthCheck.Timeout != "" {
+		if cfg.HealthCheck.Timeout, err = time.ParseDuration(s.HealthCheck.Timeout); err != nil {
+			return cfg, nil, fmt.Errorf("invalid health check timeout %q: %w", s.HealthCheck.Timeout, err)
+		}
+	}
+	defaulted := config.ApplyBackendTimeoutDefaults(&cfg)
+
+	return cfg, defaulted, config.ValidateBackend(cfg)
+}
+
+func (h *Handler) logDefaulted(fields []config.DefaultedField) {
+	for _, field := range fields {
+		h.logger.Info("Backend timeout not set, using default",
+			zap.String("backend", field.Backend),
+			zap.String("field", field.Name),
+			zap.Duration("value", field.Value),
+		)
+	}
+}
+
+func backendErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, load_balancer.ErrUnknownBackend):
+		return http.StatusNotFound
+	case errors.Is(err, load_balancer.ErrBackendExists), errors.Is(err, load_balancer.ErrLastBackend):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+func (h *Handler) AdminListBackends(w http.ResponseWriter, r *http.Request) {
+	pool := r.URL.Query().Get("pool")
+
+	views := make([]backendView, 0)
+	for _, b := range h.loadBalancer.GetBackends() {
+		if pool != "" && b.Pool != pool {
+			continue
+		}
+		_, cfg, err := h.loadBalancer.GetBackend(b.ID)
+		if err != nil {
+			continue
+		}
+		views = append(views, newBackendView(b, cfg))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"backends": views,
+	})
+}
+
+func (h *Handler) AdminGetBackend(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	b, cfg, err := h.loadBalancer.GetBackend(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(backendErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newBackendView(b, cfg))
+}
+
+func (h *Handler) AdminCreateBackend(w http.ResponseWriter, r *http.Request) {
+	var spec backendSpec
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	cfg, defaulted, err := spec.toConfig()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	b, err := h.loadBalancer.AddBackend(cfg)
+	if err != nil {
+		w.WriteHeader(backendErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Backend created via admin API",
+		zap.String("backend", b.ID),
+		zap.String("pool", b.Pool),
+	)
+	h.logDefaulted(defaulted)
+
+	w.Header().Set("Location", "/admin/backends/"+b.ID)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newBackendView(b, cfg))
+}
+
+func (h *Handler) AdminUpdateBackend(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_, current, err := h.loadBalancer.GetBackend(id)
+	if err != nil {
+		w.WriteHeader(backendErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	spec := newBackendSpec(current)
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+	if spec.ID != id {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Backend ID in body does not match the URL"})
+		return
+	}
+
+	cfg, defaulted, err := spec.toConfig()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	b, err := h.loadBalancer.UpdateBackend(cfg)
+	if err != nil {
+		w.WriteHeader(backendErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Backend updated via admin API",
+		zap.String("backend", b.ID),
+		zap.String("pool", b.Pool),
+	)
+	h.logDefaulted(defaulted)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newBackendView(b, cfg))
+}
+
+func (h *Handler) AdminDeleteBackend(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.loadBalancer.RemoveBackend(id); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(backendErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Backend deleted via admin API", zap.String("backend", id))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) AdminDrainBackend(w http.ResponseWriter, r *http.Request) {
+	h.setDraining(w, r.PathValue("id"), true)
+}
+
+func (h *Handler) AdminUndrainBackend(w http.ResponseWriter, r *http.Request) {
+	h.setDraining(w, r.PathValue("id"), false)
+}
+
+func (h *Handler) setDraining(w http.ResponseWriter, id string, draining bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	b, cfg, err := h.loadBalancer.GetBackend(id)
+	if err != nil {
+		w.WriteHeader(backendErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	b.SetDraining(draining)
+
+	h.logger.Info("Backend drain state changed via admin API",
+		zap.String("backend", id),
+		zap.Bool("draining", draining),
+	)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(newBackendView(b, cfg))
+}
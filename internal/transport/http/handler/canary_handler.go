@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/load_balancer"
+
+	"go.uber.org/zap"
+)
+
+type groupStat struct {
+	Backends       int     `json:"backends"`
+	HealthyCount   int     `json:"healthy_backends"`
+	TotalRequests  int64   `json:"total_requests"`
+	FailedRequests int64   `json:"failed_requests"`
+	ErrorRate      float64 `json:"error_rate"`
+}
+
+func poolParam(r *http.Request) string {
+	if pool := r.URL.Query().Get("pool"); pool != "" {
+		return pool
+	}
+	return config.DefaultPool
+}
+
+func adminErrorStatus(err error) int {
+	if errors.Is(err, load_balancer.ErrUnknownPool) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
+
+func (h *Handler) AdminGetCanary(w http.ResponseWriter, r *http.Request) {
+	pool := poolParam(r)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	weight, err := h.loadBalancer.GetCanaryWeight(pool)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	groups := map[string]*groupStat{
+		"stable":                  {},
+		load_balancer.CanaryGroup: {},
+	}
+
+	for _, backend := range h.loadBalancer.GetBackends() {
+		if backend.Pool != pool {
+			continue
+		}
+
+		name := "stable"
+		if backend.Group == load_balancer.CanaryGroup {
+			name = load_balancer.CanaryGroup
+		}
+
+		stat := groups[name]
+		stat.Backends++
+		if backend.IsHealthy() {
+			stat.HealthyCount++
+		}
+		stat.TotalRequests += backend.TotalRequests()
+		stat.FailedRequests += backend.FailedRequests()
+	}
+
+	for _, stat := range groups {
+		if stat.TotalRequests > 0 {
+			stat.ErrorRate = float64(stat.FailedRequests) / float64(stat.TotalRequests)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pool":   pool,
+		"weight": weight,
+		"groups": groups,
+	})
+}
+
+func (h *Handler) AdminSetCanary(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Weight *float64 `json:"weight"`
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Weight == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	pool := poolParam(r)
+	if err := h.loadBalancer.SetCanaryWeight(pool, *request.Weight); err != nil {
+		w.WriteHeader(adminErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Canary weight updated via admin API",
+		zap.String("pool", pool),
+		zap.Float64("weight", *request.Weight),
+	)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Canary weight changed successfully",
+		"pool":    pool,
+		"weight":  *request.Weight,
+	})
+}
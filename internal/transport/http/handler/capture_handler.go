@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"CloudBalancer/internal/capture"
+
+	"go.uber.org/zap"
+)
+
+const defaultCaptureTTL = 10 * time.Minute
+
+func (h *Handler) AdminListCaptureRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"rules": h.captures.Rules(),
+	})
+}
+
+func (h *Handler) AdminCreateCaptureRule(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var request struct {
+		ClientID string `json:"client_id"`
+		Route    string `json:"route"`
+		TTL      string `json:"ttl"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	ttl := min(defaultCaptureTTL, h.captures.MaxTTL())
+	if request.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(request.TTL); err != nil || ttl <= 0 || ttl > h.captures.MaxTTL() {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("ttl must be a duration between 0 and %s", h.captures.MaxTTL())})
+			return
+		}
+	}
+
+	rule, err := h.captures.AddRule(request.ClientID, request.Route, ttl)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Warn("Debug capture enabled via admin API",
+		zap.String("rule", rule.ID),
+		zap.String("client_id", rule.ClientID),
+		zap.String("route", rule.Route),
+		zap.Time("expires_at", rule.ExpiresAt),
+	)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *Handler) AdminDeleteCaptureRule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.captures.DeleteRule(id); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		status := http.StatusBadRequest
+		if errors.Is(err, capture.ErrUnknownRule) {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Debug capture disabled via admin API", zap.String("rule", id))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) AdminListCaptures(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"captures": h.captures.Captures(r.URL.Query().Get("rule")),
+	})
+}
+
+func (h *Handler) AdminClearCaptures(w http.ResponseWriter, r *http.Request) {
+	cleared := h.captures.Clear()
+	h.logger.Info("Debug captures cleared via admin API", zap.Int("cleared", cleared))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]int{"cleared": cleared})
+}
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/events"
+	"CloudBalancer/internal/load_balancer/algorithm"
+	"CloudBalancer/internal/maintenance"
+	"CloudBalancer/internal/rate_limiter"
+	"CloudBalancer/internal/routing"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+func wantsYAML(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "yaml" || format == "yml"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "yaml")
+}
+
+func (h *Handler) AdminConfig(w http.ResponseWriter, r *http.Request) {
+	h.configMu.RLock()
+	dump := config.Redacted(h.config)
+	h.configMu.RUnlock()
+
+	if wantsYAML(r) {
+		body, err := yaml.Marshal(dump)
+		if err != nil {
+			h.logger.Error("Failed to encode config as YAML", zap.Error(err))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to encode config"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(dump)
+}
+
+type reloadChanges struct {
+	Added   []string `json:"added,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+type reloadSummary struct {
+	Changed         []string       `json:"changed"`
+	Backends        *reloadChanges `json:"backends,omitempty"`
+	Routes          bool           `json:"routes,omitempty"`
+	Strategy        string         `json:"strategy,omitempty"`
+	Maintenance     bool           `json:"maintenance,omitempty"`
+	Allowlist       bool           `json:"allowlist,omitempty"`
+	Tiers           *reloadChanges `json:"tiers,omitempty"`
+	RestartRequired []string       `json:"restart_required,omitempty"`
+	Skipped         []string       `json:"skipped,omitempty"`
+}
+
+var reloadableSections = []string{
+	"backends",
+	"routes",
+	"maintenance",
+	"loadBalancer.method",
+	"rateLimit.tiers",
+	"rateLimit.allowlist",
+}
+
+func isReloadable(path string) bool {
+	for _, section := range reloadableSections {
+		if path == section || strings.HasPrefix(path, section+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) AdminReloadConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	summary, err := h.reloadConfig()
+	if err != nil {
+		h.logger.Warn("Config reload rejected", zap.Error(err))
+		h.reloads.Inc("failure")
+		h.events.Publish(events.ConfigReloadFailed, map[string]string{"error": err.Error()})
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		var problems config.ValidationErrors
+		if errors.As(err, &problems) {
+			json.NewEncoder(w).Encode(map[string]any{"error": err.Error(), "problems": problems})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.history.record("reload", h.effectiveConfig())
+	h.logger.Info("Config reloaded via admin API",
+		zap.Strings("changed", summary.Changed),
+		zap.Strings("restartRequired", summary.RestartRequired),
+	)
+	h.events.Publish(events.ConfigReloaded, summary)
+	h.reloads.Inc("success")
+	h.lastReload.Set(float64(time.Now().Unix()))
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(summary)
+}
+
+func (h *Handler) ReloadRemoteConfig() {
+	summary, err := h.reloadConfig()
+	if err != nil {
+		h.logger.Warn("Remote config change rejected", zap.Error(err))
+		h.reloads.Inc("failure")
+		h.events.Publish(events.ConfigReloadFailed, map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.history.record("remote", h.effectiveConfig())
+	h.logger.Info("Config reloaded from remote source",
+		zap.Strings("changed", summary.Changed),
+		zap.Strings("restartRequired", summary.RestartRequired),
+	)
+	h.events.Publish(events.ConfigReloaded, summary)
+	h.reloads.Inc("success")
+	h.lastReload.Set(float64(time.Now().Unix()))
+}
+
+func (h *Handler) reloadConfig() (reloadSummary, error) {
+	next, err := config.LoadConfig()
+	if err != nil {
+		return reloadSummary{}, err
+	}
+
+	h.configMu.Lock()
+	defer h.configMu.Unlock()
+	summary, err := h.applyConfig(h.config, next)
+	if err != nil {
+		return reloadSummary{}, err
+	}
+	h.logDefaulted(next.Defaulted())
+	return summary, nil
+}
+
+func (h *Handler) applyConfig(current, next *config.Config) (reloadSummary, error) {
+	summary := reloadSummary{Changed: config.Diff(current, next)}
+	if summary.Changed == nil {
+		summary.Changed = []string{}
+	}
+	for _, path := range summary.Changed {
+		if !isReloadable(path) {
+			summary.RestartRequired = append(summary.RestartRequired, path)
+		}
+	}
+
+	if _, err := routing.NewTable(next); err != nil {
+		return reloadSummary{}, fmt.Errorf("invalid routes: %w", err)
+	}
+	if _, err := rate_limiter.NewAllowlist(next.RateLimit.Allowlist); err != nil {
+		return reloadSummary{}, fmt.Errorf("invalid rate limit allowlist: %w", err)
+	}
+	maintenanceState := maintenance.NewMode(next.Maintenance).State()
+	if err := maintenanceState.Validate(); err != nil {
+		return reloadSummary{}, fmt.Errorf("invalid maintenance settings: %w", err)
+	}
+	strategy, err := algorithm.GetStrategy(next.LoadBalancer.Method)
+	if err != nil {
+		return reloadSummary{}, err
+	}
+	tiersChanged := !reflect.DeepEqual(current.RateLimit.Tiers, next.RateLimit.Tiers)
+	if tiersChanged {
+		if err := h.rateHandler.tiers.CheckReload(current.RateLimit.Tiers, next.RateLimit.Tiers); err != nil {
+			return reloadSummary{}, err
+		}
+	}
+
+	changes, err := h.loadBalancer.ReplaceBackends(next.Backends)
+	if err != nil {
+		return reloadSummary{}, err
+	}
+	if len(changes.Added)+len(changes.Updated)+len(changes.Removed) > 0 {
+		summary.Backends = &reloadChanges{
+			Added:   changes.Added,
+			Updated: changes.Updated,
+			Removed: changes.Removed,
+		}
+	}
+
+	if tiersChanged {
+		if err := h.rateHandler.tiers.Reload(current.RateLimit.Tiers, next.RateLimit.Tiers); err != nil {
+			h.logger.Error("Failed to reload rate limit tiers after validation", zap.Error(err))
+		}
+		summary.Tiers = &reloadChanges{}
+		for name, tier := range next.RateLimit.Tiers {
+			if previous, ok := current.RateLimit.Tiers[name]; !ok {
+				summary.Tiers.Added = append(summary.Tiers.Added, name)
+			} else if !reflect.DeepEqual(previous, tier) {
+				summary.Tiers.Updated = append(summary.Tiers.Updated, name)
+			}
+		}
+		for name := range current.RateLimit.Tiers {
+			if _, ok := next.RateLimit.Tiers[name]; !ok {
+				summary.Tiers.Removed = append(summary.Tiers.Removed, name)
+			}
+		}
+	}
+
+	h.routesMu.Lock()
+	if !reflect.DeepEqual(h.routes.Routes(), next.Routes) {
+		if err := h.routes.SetRoutes(next.Routes); err != nil {
+			h.logger.Error("Failed to reload routes after validation", zap.Error(err))
+		}
+		summary.Routes = true
+	}
+	h.routesMu.Unlock()
+
+	if !reflect.DeepEqual(current.RateLimit.Allowlist, next.RateLimit.Allowlist) {
+		if err := h.rateHandler.allowlist.Set(next.RateLimit.Allowlist); err != nil {
+			h.logger.Error("Failed to reload rate limit allowlist after validation", zap.Error(err))
+		}
+		summary.Allowlist = true
+	}
+
+	if !reflect.DeepEqual(current.Maintenance, next.Maintenance) {
+		h.maintenance.SetState(maintenanceState)
+		summary.Maintenance = true
+	}
+
+	if previous := h.loadBalancer.GetStrategy().Name(); previous != strategy.Name() {
+		h.loadBalancer.SetStrategy(strategy)
+		summary.Strategy = strategy.Name()
+		h.events.Publish(events.StrategyChanged, map[string]string{
+			"strategy": strategy.Name(),
+			"previous": previous,
+		})
+	}
+
+	h.config = next
+	return summary, nil
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"CloudBalancer/internal/load_balancer"
+
+	"go.uber.org/zap"
+)
+
+func (h *Handler) AdminGetDeployment(w http.ResponseWriter, r *http.Request) {
+	pool := poolParam(r)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	deployment, err := h.loadBalancer.GetDeployment(pool)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	groups := map[string]*groupStat{
+		load_balancer.BlueGroup:  {},
+		load_balancer.GreenGroup: {},
+	}
+
+	for _, backend := range h.loadBalancer.GetBackends() {
+		stat, ok := groups[backend.Group]
+		if !ok || backend.Pool != pool {
+			continue
+		}
+		stat.Backends++
+		if backend.IsHealthy() {
+			stat.HealthyCount++
+		}
+		stat.TotalRequests += backend.TotalRequests()
+		stat.FailedRequests += backend.FailedRequests()
+	}
+
+	for _, stat := range groups {
+		if stat.TotalRequests > 0 {
+			stat.ErrorRate = float64(stat.FailedRequests) / float64(stat.TotalRequests)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"deployment": deployment,
+		"groups":     groups,
+	})
+}
+
+func (h *Handler) AdminSwitchDeployment(w http.ResponseWriter, r *http.Request) {
+	request := struct {
+		Group        string `json:"group"`
+		AutoRollback *bool  `json:"auto_rollback"`
+	}{}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	autoRollback := true
+	if request.AutoRollback != nil {
+		autoRollback = *request.AutoRollback
+	}
+
+	pool := poolParam(r)
+	if err := h.loadBalancer.SwitchDeployment(pool, request.Group, autoRollback); err != nil {
+		w.WriteHeader(adminErrorStatus(err))
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	deployment, _ := h.loadBalancer.GetDeployment(pool)
+
+	h.logger.Info("Deployment group switched via admin API",
+		zap.String("pool", pool),
+		zap.String("group", request.Group),
+		zap.Bool("autoRollback", autoRollback),
+	)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":    "Deployment group switched successfully",
+		"deployment": deployment,
+	})
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"CloudBalancer/internal/events"
+	lbbackend "CloudBalancer/internal/load_balancer/backend"
+
+	"go.uber.org/zap"
+)
+
+const eventsKeepAlive = 15 * time.Second
+
+func (h *Handler) AdminEvents(w http.ResponseWriter, r *http.Request) {
+	var types []string
+	if raw := r.URL.Query().Get("types"); raw != "" {
+		for _, eventType := range strings.Split(raw, ",") {
+			eventType = strings.TrimSpace(eventType)
+			if !slices.Contains(events.Types, eventType) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("unknown event type %q", eventType)})
+				return
+			}
+			types = append(types, eventType)
+		}
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	var since int64
+	if lastID != "" {
+		var err error
+		if since, err = strconv.ParseInt(lastID, 10, 64); err != nil || since < 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid last event ID"})
+			return
+		}
+	}
+
+	wanted := func(event events.Event) bool {
+		return len(types) == 0 || slices.Contains(types, event.Type)
+	}
+
+	if lbbackend.IsWebSocketRequest(r) {
+		h.streamEventsWebSocket(w, r, since, wanted)
+		return
+	}
+	h.streamEventsSSE(w, r, since, wanted)
+}
+
+func (h *Handler) streamEventsSSE(w http.ResponseWriter, r *http.Request, since int64, wanted func(events.Event) bool) {
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	sub, missed := h.events.Subscribe(since)
+	defer h.events.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(event events.Event) error {
+		if !wanted(event) {
+			return nil
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		h.logger.Warn("Event stream does not support flushing", zap.Error(err))
+		return
+	}
+	for _, event := range missed {
+		if err := write(event); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(eventsKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if err := write(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func (h *Handler) streamEventsWebSocket(w http.ResponseWriter, r *http.Request, since int64, wanted func(events.Event) bool) {
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		h.logger.Debug("WebSocket upgrade for event stream failed", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	sub, missed := h.events.Subscribe(since)
+	defer h.events.Unsubscribe(sub)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.readLoop()
+	}()
+
+	write := func(event events.Event) error {
+		if !wanted(event) {
+			return nil
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		return conn.WriteText(data)
+	}
+
+	for _, event := range missed {
+		if err := write(event); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(eventsKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-sub.C:
+			if !ok {
+				status := wsCloseGoingAway
+				if h.events.Dropped(sub) {
+					status = wsCloseTryAgainLater
+				}
+				conn.WriteClose(status)
+				return
+			}
+			if err := write(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WritePing(); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
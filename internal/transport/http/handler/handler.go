@@ -1,33 +1,98 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"CloudBalancer/config"
+	"CloudBalancer/internal/audit"
+	"CloudBalancer/internal/cache"
+	"CloudBalancer/internal/capture"
+	"CloudBalancer/internal/clientip"
+	"CloudBalancer/internal/errorpage"
+	"CloudBalancer/internal/events"
 	"CloudBalancer/internal/load_balancer"
 	"CloudBalancer/internal/load_balancer/algorithm"
+	lbbackend "CloudBalancer/internal/load_balancer/backend"
+	"CloudBalancer/internal/maintenance"
+	"CloudBalancer/internal/metrics"
 	"CloudBalancer/internal/rate_limiter"
+	"CloudBalancer/internal/requestid"
+	"CloudBalancer/internal/retry"
+	"CloudBalancer/internal/rewrite"
+	"CloudBalancer/internal/routing"
+	"CloudBalancer/internal/topk"
+	applog "CloudBalancer/pkg/logger"
 
 	"go.uber.org/zap"
 )
 
 type Handler struct {
+	config       *config.Config
+	configMu     sync.RWMutex
 	loadBalancer load_balancer.LoadBalancer
 	rateLimiter  rate_limiter.RateLimiter
+	routes       *routing.Table
+	routesMu     sync.Mutex
+	rewrites     *rewrite.Engine
+	errorPages   *errorpage.Pages
+	maintenance  *maintenance.Mode
+	cache        *cache.Cache
+	audit        *audit.Log
+	events       *events.Bus
 	logger       *zap.Logger
 	rateHandler  *RateLimitHandler
+	shuttingDown atomic.Bool
+	registry     *metrics.Registry
+	reloads      *metrics.CounterVec
+	lastReload   *metrics.GaugeVec
+	logLevel     zap.AtomicLevel
+	top          *topk.Tracker
+	captures     *capture.Store
+	ipResolver   *clientip.Resolver
+	history      *configHistory
 }
 
-func NewHandler(lb load_balancer.LoadBalancer, rl rate_limiter.RateLimiter, logger *zap.Logger) *Handler {
-	rateHandler := NewRateLimitHandler(rl, logger)
+func NewHandler(cfg *config.Config, lb load_balancer.LoadBalancer, rl rate_limiter.RateLimiter, allowlist *rate_limiter.Allowlist, bans *rate_limiter.BanList, tiers *rate_limiter.Tiers, rateLimitMetrics *rate_limiter.Metrics, routes *routing.Table, rewrites *rewrite.Engine, responseCache *cache.Cache, errorPages *errorpage.Pages, maintenanceMode *maintenance.Mode, auditLog *audit.Log, bus *events.Bus, registry *metrics.Registry, top *topk.Tracker, captures *capture.Store, ipResolver *clientip.Resolver, logLevel zap.AtomicLevel, logger *zap.Logger) *Handler {
+	rateHandler := NewRateLimitHandler(rl, allowlist, bans, tiers, rateLimitMetrics, logger)
 
-	return &Handler{
+	h := &Handler{
+		config:       cfg,
 		loadBalancer: lb,
 		rateLimiter:  rl,
+		routes:       routes,
+		rewrites:     rewrites,
+		errorPages:   errorPages,
+		maintenance:  maintenanceMode,
+		cache:        responseCache,
+		audit:        auditLog,
+		events:       bus,
 		logger:       logger,
 		rateHandler:  rateHandler,
+		registry:     registry,
+		logLevel:     logLevel,
+		top:          top,
+		captures:     captures,
+		ipResolver:   ipResolver,
+		reloads: registry.NewCounter("cloudbalancer_config_reloads_total",
+			"Configuration reload attempts by result (success or failure).", "result"),
+		lastReload: registry.NewGauge("cloudbalancer_config_last_reload_success_timestamp_seconds",
+			"Unix time of the last successful configuration reload."),
+		history: newConfigHistory(cfg.Admin.History.MaxEntries),
 	}
+	h.history.record("startup", h.effectiveConfig())
+	return h
 }
 
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
@@ -38,41 +103,289 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *Handler) ReadinessCheck(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]string{
+		"backends": "ok",
+		"shutdown": "ok",
+	}
+	ready := true
+
+	healthy := 0
+	backends := h.loadBalancer.GetBackends()
+	for _, backend := range backends {
+		if backend.IsHealthy() && !backend.IsDraining() {
+			healthy++
+		}
+	}
+	if healthy == 0 {
+		checks["backends"] = fmt.Sprintf("no healthy backends (0/%d)", len(backends))
+		ready = false
+	}
+
+	if h.shuttingDown.Load() {
+		checks["shutdown"] = "shutting down"
+		ready = false
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not ready", http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+func (h *Handler) BeginShutdown() {
+	h.shuttingDown.Store(true)
+}
+
 func (h *Handler) LoadBalancer(w http.ResponseWriter, r *http.Request) {
+	logger := applog.ForRequest(r.Context(), h.logger).With(requestid.Field(r.Context()))
 	startTime := time.Now()
 
-	backend, err := h.loadBalancer.GetNextBackend()
+	originalURI := r.URL.RequestURI()
+	r, applied := h.rewrites.Request(r)
+
+	route := h.routes.Match(r)
+	r = r.WithContext(routing.WithRoute(r.Context(), route))
+	if route.RewritePath(r) {
+		applied = append(applied, "route")
+	}
+
+	if len(applied) > 0 {
+		logger.Debug("Request URL rewritten",
+			zap.String("from", originalURI),
+			zap.String("to", r.URL.RequestURI()),
+			zap.Strings("rules", applied),
+		)
+		if header := h.rewrites.DebugHeader(); header != "" {
+			w.Header().Set(header, strings.Join(applied, ", "))
+		}
+	}
+	if state, ok := h.maintenance.Applies(route.Pool, r.URL.Path); ok {
+		h.errorPages.WriteRetry(w, r, http.StatusServiceUnavailable, errorpage.CodeMaintenance, state.Message, state.RetryAfter)
+		return
+	}
+
+	if route.Static != nil {
+		h.serveStatic(w, r, route)
+		return
+	}
+
+	if route.Timeout > 0 && !lbbackend.IsWebSocketRequest(r) {
+		ctx, cancel := context.WithTimeout(r.Context(), route.Timeout)
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	if route.MaxBodySize > 0 {
+		if r.ContentLength > route.MaxBodySize {
+			logger.Debug("Request body too large",
+				zap.String("path", r.URL.Path),
+				zap.String("client_ip", h.ipResolver.ClientIP(r)),
+				zap.Int64("content_length", r.ContentLength),
+				zap.Int64("max_body_size", route.MaxBodySize),
+			)
+			h.errorPages.Write(w, r, http.StatusRequestEntityTooLarge, errorpage.CodeBodyTooLarge, "Request body too large")
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, route.MaxBodySize)
+	}
+
+	if h.cache != nil && route.CacheTTL > 0 && cache.IsCacheableRequest(r) {
+		if entry, ok := h.cache.Get(r); ok {
+			h.cache.SetStatus(w, cache.StatusHit)
+			logger.Debug("Response served from cache",
+				zap.String("path", r.URL.Path),
+				zap.String("client_ip", h.ipResolver.ClientIP(r)),
+			)
+			entry.WriteTo(w, r)
+			return
+		}
+
+		h.cache.SetStatus(w, cache.StatusMiss)
+		recorder := cache.NewRecorder(w, h.cache.MaxEntrySize())
+		h.forward(recorder, r, route, startTime)
+		h.cache.Store(r, recorder, route.CacheTTL)
+		return
+	}
+
+	h.forward(w, r, route, startTime)
+}
+
+func (h *Handler) forward(w http.ResponseWriter, r *http.Request, route *routing.Route, startTime time.Time) {
+	logger := applog.ForRequest(r.Context(), h.logger).With(requestid.Field(r.Context()))
+	body, replayable, err := bufferRequestBody(r, route.BufferMaxSize)
 	if err != nil {
-		h.logger.Error("Failed to get next backend",
+		logger.Debug("Failed to read request body",
 			zap.String("path", r.URL.Path),
-			zap.String("client_ip", r.RemoteAddr),
+			zap.String("client_ip", h.ipResolver.ClientIP(r)),
 			zap.Error(err),
 		)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(map[string]string{
-			"error": "No healthy backends available",
-		})
+		status, code, message := http.StatusBadRequest, errorpage.CodeBadRequestBody, "Failed to read request body"
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			status, code, message = http.StatusRequestEntityTooLarge, errorpage.CodeBodyTooLarge, "Request body too large"
+		}
+		h.errorPages.Write(w, r, status, code, message)
 		return
 	}
 
-	h.logger.Info("Request forwarded to backend",
-		zap.String("path", r.URL.Path),
-		zap.String("client_ip", r.RemoteAddr),
-		zap.String("backend_id", backend.ID),
-		zap.String("backend_url", backend.URL.String()),
-		zap.Int64("active_connections", backend.ActiveConnections()),
-	)
+	if route.Streaming {
+		w = newFlushResponseWriter(w)
+	}
 
-	backend.ServeHTTP(w, r)
+	if isHedgeable(r, route) {
+		h.forwardHedged(w, r, route, startTime)
+		return
+	}
 
-	elapsed := time.Since(startTime)
-	h.logger.Info("Backend response completed",
-		zap.String("path", r.URL.Path),
-		zap.String("client_ip", r.RemoteAddr),
-		zap.String("backend_id", backend.ID),
-		zap.Duration("response_time", elapsed),
-	)
+	policy := route.Retry
+	policy.RecordRequest()
+	retryable := replayable && policy.Allows(r)
+
+	for attempt := 0; ; attempt++ {
+		backend, err := h.loadBalancer.AcquireBackend(r.Context(), route.Pool)
+		if err != nil {
+			logger.Error("Failed to get next backend",
+				zap.String("pool", route.Pool),
+				zap.String("path", r.URL.Path),
+				zap.String("client_ip", h.ipResolver.ClientIP(r)),
+				zap.Int("attempt", attempt),
+				zap.Error(err),
+			)
+			h.writeBackendError(w, r, err, attempt)
+			return
+		}
+
+		req := r
+		if body != nil {
+			req = r.Clone(r.Context())
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+		}
+
+		var proxyAttempt *lbbackend.Attempt
+		if retryable && attempt < policy.Attempts {
+			var ctx context.Context
+			ctx, proxyAttempt = lbbackend.WithAttempt(req.Context())
+			req = req.WithContext(ctx)
+		}
+
+		cancelTry := func() {}
+		if policy.PerTryTimeout > 0 && !lbbackend.IsWebSocketRequest(r) {
+			ctx, cancel := context.WithCancelCause(req.Context())
+			timer := time.AfterFunc(policy.PerTryTimeout, func() {
+				cancel(retry.ErrPerTryTimeout)
+			})
+			ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+				GotFirstResponseByte: func() {
+					timer.Stop()
+				},
+			})
+			cancelTry = func() {
+				timer.Stop()
+				cancel(nil)
+			}
+			req = req.WithContext(ctx)
+		}
+
+		logger.Info("Request forwarded to backend",
+			zap.String("path", r.URL.Path),
+			zap.String("client_ip", h.ipResolver.ClientIP(r)),
+			zap.String("backend_id", backend.ID),
+			zap.String("backend_url", backend.URL.String()),
+			zap.Int64("active_connections", backend.ActiveConnections()),
+			zap.Int("attempt", attempt),
+		)
+
+		backend.ServeHTTP(w, req)
+		cancelTry()
+
+		if proxyAttempt == nil || proxyAttempt.Err == nil {
+			elapsed := time.Since(startTime)
+			logger.Info("Backend response completed",
+				zap.String("path", r.URL.Path),
+				zap.String("client_ip", h.ipResolver.ClientIP(r)),
+				zap.String("backend_id", backend.ID),
+				zap.Duration("response_time", elapsed),
+			)
+			return
+		}
+
+		if !policy.TryRetry() {
+			logger.Warn("Retry budget exhausted",
+				zap.String("path", r.URL.Path),
+				zap.String("client_ip", h.ipResolver.ClientIP(r)),
+				zap.String("backend_id", backend.ID),
+				zap.Int("attempt", attempt),
+				zap.Error(proxyAttempt.Err),
+			)
+			if errors.Is(proxyAttempt.Err, context.DeadlineExceeded) {
+				h.errorPages.Write(w, r, http.StatusGatewayTimeout, errorpage.CodeUpstreamTimeout, "Backend request timed out")
+				return
+			}
+			h.errorPages.Write(w, r, http.StatusBadGateway, errorpage.CodeUpstreamError, "Backend server error")
+			return
+		}
+
+		logger.Warn("Retrying request on another backend",
+			zap.String("path", r.URL.Path),
+			zap.String("client_ip", h.ipResolver.ClientIP(r)),
+			zap.String("backend_id", backend.ID),
+			zap.Int("attempt", attempt),
+			zap.Error(proxyAttempt.Err),
+		)
+	}
+}
+
+func (h *Handler) writeBackendError(w http.ResponseWriter, r *http.Request, err error, attempt int) {
+	var queueErr *load_balancer.QueueError
+	switch {
+	case errors.As(err, &queueErr):
+		retryAfter := int(math.Ceil(queueErr.RetryAfter.Seconds()))
+		h.errorPages.WriteRetry(w, r, http.StatusServiceUnavailable, errorpage.CodeBackendBusy, "Server is busy, please retry later", retryAfter)
+	case errors.Is(err, lbbackend.ErrRateLimited):
+		h.errorPages.WriteRetry(w, r, http.StatusServiceUnavailable, errorpage.CodeBackendRateLimited, "Backend rate limit exceeded, please retry later", 1)
+	case errors.Is(err, context.DeadlineExceeded):
+		h.errorPages.Write(w, r, http.StatusGatewayTimeout, errorpage.CodeQueueTimeout, "Timed out waiting for an available backend")
+	case attempt > 0:
+		h.errorPages.Write(w, r, http.StatusBadGateway, errorpage.CodeUpstreamError, "Backend server error")
+	default:
+		h.errorPages.Write(w, r, http.StatusServiceUnavailable, errorpage.CodeNoHealthyBackend, "No healthy backends available")
+	}
+}
+
+func bufferRequestBody(r *http.Request, maxSize int64) ([]byte, bool, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true, nil
+	}
+
+	if maxSize <= 0 || r.ContentLength > maxSize {
+		return nil, false, nil
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if int64(len(buf)) > maxSize {
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+		return nil, false, nil
+	}
+
+	r.Body.Close()
+	return buf, true, nil
 }
 
 type captureResponseWriter struct {
@@ -92,29 +405,77 @@ func (crw *captureResponseWriter) WriteHeader(code int) {
 	crw.ResponseWriter.WriteHeader(code)
 }
 
+func (crw *captureResponseWriter) Unwrap() http.ResponseWriter {
+	return crw.ResponseWriter
+}
+
+type flushResponseWriter struct {
+	http.ResponseWriter
+	controller *http.ResponseController
+}
+
+func newFlushResponseWriter(w http.ResponseWriter) *flushResponseWriter {
+	return &flushResponseWriter{
+		ResponseWriter: w,
+		controller:     http.NewResponseController(w),
+	}
+}
+
+func (fw *flushResponseWriter) Write(p []byte) (int, error) {
+	n, err := fw.ResponseWriter.Write(p)
+	if err == nil {
+		fw.controller.Flush()
+	}
+	return n, err
+}
+
+func (fw *flushResponseWriter) Unwrap() http.ResponseWriter {
+	return fw.ResponseWriter
+}
+
 func (h *Handler) AdminGetStats(w http.ResponseWriter, r *http.Request) {
 	backends := h.loadBalancer.GetBackends()
 
 	type backendStat struct {
-		ID                string `json:"id"`
-		URL               string `json:"url"`
-		Healthy           bool   `json:"healthy"`
-		ActiveConnections int64  `json:"active_connections"`
+		ID                string             `json:"id"`
+		Pool              string             `json:"pool"`
+		Group             string             `json:"group"`
+		URL               string             `json:"url"`
+		Healthy           bool               `json:"healthy"`
+		ActiveConnections int64              `json:"active_connections"`
+		ActiveWebSockets  int64              `json:"active_websockets"`
+		TotalRequests     int64              `json:"total_requests"`
+		FailedRequests    int64              `json:"failed_requests"`
+		StatsSince        time.Time          `json:"stats_since"`
+		Cumulative        lbbackend.Snapshot `json:"cumulative"`
+		Last1m            lbbackend.Snapshot `json:"last_1m"`
+		Last5m            lbbackend.Snapshot `json:"last_5m"`
 	}
 
 	stats := make([]backendStat, 0, len(backends))
 	for _, backend := range backends {
 		stats = append(stats, backendStat{
 			ID:                backend.ID,
+			Pool:              backend.Pool,
+			Group:             backend.Group,
 			URL:               backend.URL.String(),
 			Healthy:           backend.IsHealthy(),
 			ActiveConnections: backend.ActiveConnections(),
+			ActiveWebSockets:  backend.ActiveWebSockets(),
+			TotalRequests:     backend.TotalRequests(),
+			FailedRequests:    backend.FailedRequests(),
+			StatsSince:        backend.Stats.Since(),
+			Cumulative:        backend.Stats.Total(),
+			Last1m:            backend.Stats.Window(time.Minute),
+			Last5m:            backend.Stats.Window(5 * time.Minute),
 		})
 	}
 
 	response := map[string]interface{}{
-		"strategy": h.loadBalancer.GetStrategy().Name(),
-		"backends": stats,
+		"strategy":     h.loadBalancer.GetStrategy().Name(),
+		"pools":        h.loadBalancer.GetPools(),
+		"backends":     stats,
+		"rate_limiter": h.rateLimiter.StoreStats(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -122,12 +483,42 @@ func (h *Handler) AdminGetStats(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func (h *Handler) AdminChangeStrategy(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
+func (h *Handler) AdminResetStats(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	id, pool := query.Get("backend"), query.Get("pool")
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if id != "" {
+		if _, _, err := h.loadBalancer.GetBackend(id); err != nil {
+			w.WriteHeader(backendErrorStatus(err))
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
 	}
 
+	reset := make([]string, 0)
+	for _, backend := range h.loadBalancer.GetBackends() {
+		if (id != "" && backend.ID != id) || (pool != "" && backend.Pool != pool) {
+			continue
+		}
+		backend.ResetStats()
+		reset = append(reset, backend.ID)
+	}
+
+	h.logger.Info("Backend stats reset via admin API",
+		zap.Strings("backends", reset),
+	)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  "Stats reset successfully",
+		"backends": reset,
+		"since":    time.Now(),
+	})
+}
+
+func (h *Handler) AdminChangeStrategy(w http.ResponseWriter, r *http.Request) {
 	var request struct {
 		Strategy string `json:"strategy"`
 	}
@@ -145,7 +536,14 @@ func (h *Handler) AdminChangeStrategy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	previous := h.loadBalancer.GetStrategy().Name()
 	h.loadBalancer.SetStrategy(strategy)
+	if previous != strategy.Name() {
+		h.events.Publish(events.StrategyChanged, map[string]string{
+			"strategy": strategy.Name(),
+			"previous": previous,
+		})
+	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
@@ -154,6 +552,116 @@ func (h *Handler) AdminChangeStrategy(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (h *Handler) RateLimitHandler(w http.ResponseWriter, r *http.Request) {
-	h.rateHandler.HandleRateLimit(w, r)
+func (h *Handler) AdminStrategies(w http.ResponseWriter, r *http.Request) {
+	type strategyInfo struct {
+		algorithm.Info
+		Active bool `json:"active"`
+	}
+
+	active := h.loadBalancer.GetStrategy().Name()
+	strategies := make([]strategyInfo, 0)
+	for _, info := range algorithm.Strategies() {
+		strategies = append(strategies, strategyInfo{Info: info, Active: info.Name == active})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"active":     active,
+		"strategies": strategies,
+	})
+}
+
+func (h *Handler) AdminCacheStats(w http.ResponseWriter, r *http.Request) {
+	if !h.cacheEnabled(w) {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.cache.Stats())
+}
+
+func (h *Handler) AdminPurgeCache(w http.ResponseWriter, r *http.Request) {
+	if !h.cacheEnabled(w) {
+		return
+	}
+
+	purged := h.cache.Purge(r.URL.Query().Get("prefix"))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]int{"purged": purged})
+}
+
+func (h *Handler) cacheEnabled(w http.ResponseWriter) bool {
+	w.Header().Set("Content-Type", "application/json")
+
+	if h.cache == nil {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Response cache is disabled"})
+		return false
+	}
+	return true
+}
+
+func (h *Handler) RateLimitGet(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleGet(w, r)
+}
+
+func (h *Handler) RateLimitCreate(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleCreate(w, r)
+}
+
+func (h *Handler) RateLimitUpdate(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleUpdate(w, r)
+}
+
+func (h *Handler) RateLimitDelete(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleDelete(w, r)
+}
+
+func (h *Handler) RateLimitReset(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleReset(w, r)
+}
+
+func (h *Handler) RateLimitList(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleList(w, r)
+}
+
+func (h *Handler) RateLimitExport(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleExport(w, r)
+}
+
+func (h *Handler) RateLimitImport(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleImport(w, r)
+}
+
+func (h *Handler) RateLimitGetAllowlist(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleGetAllowlist(w, r)
+}
+
+func (h *Handler) RateLimitSetAllowlist(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleSetAllowlist(w, r)
+}
+
+func (h *Handler) RateLimitListTiers(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleListTiers(w, r)
+}
+
+func (h *Handler) RateLimitSetTier(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleSetTier(w, r)
+}
+
+func (h *Handler) RateLimitDeleteTier(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleDeleteTier(w, r)
+}
+
+func (h *Handler) RateLimitMetrics(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleMetrics(w, r)
+}
+
+func (h *Handler) RateLimitListBans(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleListBans(w, r)
+}
+
+func (h *Handler) RateLimitLiftBan(w http.ResponseWriter, r *http.Request) {
+	h.rateHandler.HandleLiftBan(w, r)
 }
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"CloudBalancer/internal/errorpage"
+	lbbackend "CloudBalancer/internal/load_balancer/backend"
+	"CloudBalancer/internal/requestid"
+	"CloudBalancer/internal/routing"
+	applog "CloudBalancer/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+var errHedgeLost = errors.New("hedged response discarded")
+
+func isHedgeable(r *http.Request, route *routing.Route) bool {
+	if route.Hedge == nil || lbbackend.IsWebSocketRequest(r) {
+		return false
+	}
+	return (r.Method == http.MethodGet || r.Method == http.MethodHead) && (r.Body == nil || r.Body == http.NoBody)
+}
+
+type hedgeRace struct {
+	dst     http.ResponseWriter
+	route   *routing.Route
+	start   time.Time
+	winner  *hedgeWriter
+	writers []*hedgeWriter
+	mu      sync.Mutex
+}
+
+type hedgeWriter struct {
+	race    *hedgeRace
+	header  http.Header
+	backend *lbbackend.Backend
+	attempt *lbbackend.Attempt
+	cancel  context.CancelCauseFunc
+}
+
+func (hw *hedgeWriter) claim() bool {
+	hw.race.mu.Lock()
+	defer hw.race.mu.Unlock()
+
+	if hw.race.winner == nil {
+		hw.race.winner = hw
+		for name, values := range hw.header {
+			for _, value := range values {
+				hw.race.dst.Header().Add(name, value)
+			}
+		}
+		for _, other := range hw.race.writers {
+			if other != hw {
+				other.cancel(lbbackend.ErrHedgeCanceled)
+			}
+		}
+		hw.race.route.Hedge.Observe(time.Since(hw.race.start))
+	}
+	return hw.race.winner == hw
+}
+
+func (hw *hedgeWriter) won() bool {
+	hw.race.mu.Lock()
+	defer hw.race.mu.Unlock()
+	return hw.race.winner == hw
+}
+
+func (hw *hedgeWriter) Header() http.Header {
+	if hw.won() {
+		return hw.race.dst.Header()
+	}
+	return hw.header
+}
+
+func (hw *hedgeWriter) WriteHeader(code int) {
+	if hw.claim() {
+		hw.race.dst.WriteHeader(code)
+	}
+}
+
+func (hw *hedgeWriter) Write(p []byte) (int, error) {
+	if !hw.claim() {
+		return 0, errHedgeLost
+	}
+	return hw.race.dst.Write(p)
+}
+
+func (hw *hedgeWriter) FlushError() error {
+	if !hw.won() {
+		return errHedgeLost
+	}
+	return http.NewResponseController(hw.race.dst).Flush()
+}
+
+func (h *Handler) forwardHedged(w http.ResponseWriter, r *http.Request, route *routing.Route, startTime time.Time) {
+	logger := applog.ForRequest(r.Context(), h.logger).With(requestid.Field(r.Context()))
+	ctx, cancel := context.WithCancelCause(r.Context())
+	defer cancel(lbbackend.ErrHedgeCanceled)
+
+	race := &hedgeRace{dst: w, route: route}
+	done := make(chan *hedgeWriter, 2)
+
+	launch := func(backend *lbbackend.Backend) {
+		attemptCtx, attemptCancel := context.WithCancelCause(ctx)
+		attemptCtx, attempt := lbbackend.WithAttempt(attemptCtx)
+		hw := &hedgeWriter{
+			race:    race,
+			header:  make(http.Header),
+			backend: backend,
+			attempt: attempt,
+			cancel:  attemptCancel,
+		}
+
+		race.mu.Lock()
+		race.writers = append(race.writers, hw)
+		race.mu.Unlock()
+
+		go func() {
+			defer attemptCancel(nil)
+			backend.ServeHTTP(hw, r.WithContext(attemptCtx))
+			done <- hw
+		}()
+	}
+
+	hedgeBackend := func(previous *lbbackend.Backend) bool {
+		backend, err := h.loadBalancer.GetNextBackend(route.Pool)
+		if err == nil && backend == previous {
+			backend.DecrementConnections()
+			backend, err = h.loadBalancer.GetNextBackend(route.Pool)
+		}
+		if err != nil {
+			logger.Warn("Failed to get backend for hedged request",
+				zap.String("pool", route.Pool),
+				zap.String("path", r.URL.Path),
+				zap.Error(err),
+			)
+			return false
+		}
+		launch(backend)
+		return true
+	}
+
+	primary, err := h.loadBalancer.AcquireBackend(r.Context(), route.Pool)
+	if err != nil {
+		logger.Error("Failed to get next backend",
+			zap.String("pool", route.Pool),
+			zap.String("path", r.URL.Path),
+			zap.String("client_ip", h.ipResolver.ClientIP(r)),
+			zap.Error(err),
+		)
+		h.writeBackendError(w, r, err, 0)
+		return
+	}
+	race.start = time.Now()
+	launch(primary)
+
+	delay := route.Hedge.Delay()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	pending, hedged := 1, false
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if hedged {
+				continue
+			}
+			hedged = true
+			if hedgeBackend(primary) {
+				pending++
+				logger.Debug("Hedged request sent",
+					zap.String("path", r.URL.Path),
+					zap.String("primary_backend", primary.ID),
+					zap.Duration("delay", delay),
+				)
+			}
+		case hw := <-done:
+			pending--
+			if hw.won() {
+				logger.Info("Backend response completed",
+					zap.String("path", r.URL.Path),
+					zap.String("client_ip", h.ipResolver.ClientIP(r)),
+					zap.String("backend_id", hw.backend.ID),
+					zap.Bool("hedged", hedged),
+					zap.Duration("response_time", time.Since(startTime)),
+				)
+				return
+			}
+			lastErr = hw.attempt.Err
+			if !hedged {
+				hedged = true
+				if hedgeBackend(hw.backend) {
+					pending++
+				}
+			}
+		}
+	}
+
+	logger.Error("All hedged requests failed",
+		zap.String("path", r.URL.Path),
+		zap.String("client_ip", h.ipResolver.ClientIP(r)),
+		zap.Error(lastErr),
+	)
+	h.errorPages.Write(w, r, http.StatusBadGateway, errorpage.CodeUpstreamError, "Backend server error")
+}
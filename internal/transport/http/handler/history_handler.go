@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/auth"
+	"CloudBalancer/internal/events"
+
+	"go.uber.org/zap"
+)
+
+type configRevision struct {
+	ID      int             `json:"id"`
+	Time    time.Time       `json:"time"`
+	Source  string          `json:"source"`
+	Changed []config.Change `json:"changed"`
+	config  *config.Config
+}
+
+type configHistory struct {
+	revisions  []configRevision
+	nextID     int
+	maxEntries int
+	mu         sync.Mutex
+}
+
+func newConfigHistory(maxEntries int) *configHistory {
+	return &configHistory{nextID: 1, maxEntries: max(maxEntries, 1)}
+}
+
+func (h *configHistory) record(source string, cfg *config.Config) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	changed := []config.Change{}
+	if len(h.revisions) > 0 {
+		changed = config.Changes(h.revisions[len(h.revisions)-1].config, cfg)
+		if len(changed) == 0 {
+			return
+		}
+	}
+
+	h.revisions = append(h.revisions, configRevision{
+		ID:      h.nextID,
+		Time:    time.Now(),
+		Source:  source,
+		Changed: changed,
+		config:  cfg,
+	})
+	h.nextID++
+	if len(h.revisions) > h.maxEntries {
+		h.revisions = slices.Delete(h.revisions, 0, len(h.revisions)-h.maxEntries)
+	}
+}
+
+func (h *configHistory) list() []configRevision {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	revisions := slices.Clone(h.revisions)
+	slices.Reverse(revisions)
+	return revisions
+}
+
+func (h *configHistory) get(id int) (configRevision, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, revision := range h.revisions {
+		if revision.ID == id {
+			return revision, true
+		}
+	}
+	return configRevision{}, false
+}
+
+func (h *Handler) effectiveConfig() *config.Config {
+	h.configMu.RLock()
+	defer h.configMu.RUnlock()
+	return h.effectiveConfigLocked()
+}
+
+func (h *Handler) effectiveConfigLocked() *config.Config {
+	cfg := *h.config
+
+	cfg.Backends = nil
+	seen := make(map[string]bool)
+	for _, configured := range h.config.Backends {
+		if _, live, err := h.loadBalancer.GetBackend(configured.ID); err == nil {
+			cfg.Backends = append(cfg.Backends, live)
+			seen[configured.ID] = true
+		} else if !configured.Enabled {
+			cfg.Backends = append(cfg.Backends, configured)
+			seen[configured.ID] = true
+		}
+	}
+	for _, b := range h.loadBalancer.GetBackends() {
+		if seen[b.ID] {
+			continue
+		}
+		if _, live, err := h.loadBalancer.GetBackend(b.ID); err == nil {
+			cfg.Backends = append(cfg.Backends, live)
+		}
+	}
+
+	if routes := h.routes.Routes(); !reflect.DeepEqual(routes, cfg.Routes) && len(routes)+len(cfg.Routes) > 0 {
+		cfg.Routes = routes
+	}
+	cfg.LoadBalancer.Method = h.loadBalancer.GetStrategy().Name()
+
+	allowlist := h.rateHandler.allowlist.Config()
+	if !slices.Equal(allowlist.Clients, cfg.RateLimit.Allowlist.Clients) ||
+		!slices.Equal(allowlist.CIDRs, cfg.RateLimit.Allowlist.CIDRs) ||
+		!maps.Equal(allowlist.Headers, cfg.RateLimit.Allowlist.Headers) {
+		cfg.RateLimit.Allowlist = allowlist
+	}
+
+	state := h.maintenance.State()
+	maintenance := cfg.Maintenance
+	maintenance.Enabled = state.Enabled
+	maintenance.Message = state.Message
+	maintenance.RetryAfter = time.Duration(state.RetryAfter) * time.Second
+	if !slices.Equal(state.Pools, maintenance.Pools) {
+		maintenance.Pools = state.Pools
+	}
+	if !slices.Equal(state.Paths, maintenance.Paths) {
+		maintenance.Paths = state.Paths
+	}
+	cfg.Maintenance = maintenance
+
+	return &cfg
+}
+
+func (h *Handler) ConfigHistoryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if !auth.IsReadOnly(r.Method) {
+			h.history.record(r.Method+" "+r.URL.Path, h.effectiveConfig())
+		}
+	})
+}
+
+func (h *Handler) AdminConfigHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"revisions": h.history.list(),
+	})
+}
+
+func (h *Handler) AdminGetConfigRevision(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	revision, ok := h.revisionFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":      revision.ID,
+		"time":    revision.Time,
+		"source":  revision.Source,
+		"changed": revision.Changed,
+		"config":  config.Redacted(revision.config),
+	})
+}
+
+func (h *Handler) AdminRollbackConfig(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	revision, ok := h.revisionFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	h.configMu.Lock()
+	current := h.effectiveConfigLocked()
+	var skipped []string
+	for _, path := range config.Diff(current, revision.config) {
+		if !isReloadable(path) {
+			skipped = append(skipped, path)
+		}
+	}
+	summary, err := h.applyConfig(current, rollbackTarget(current, revision.config))
+	h.configMu.Unlock()
+	summary.Skipped = skipped
+	if err != nil {
+		h.logger.Warn("Config rollback failed", zap.Int("revision", revision.ID), zap.Error(err))
+		h.reloads.Inc("failure")
+		h.events.Publish(events.ConfigReloadFailed, map[string]string{"error": err.Error()})
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.history.record("rollback to revision "+strconv.Itoa(revision.ID), h.effectiveConfig())
+	h.logger.Info("Config rolled back via admin API",
+		zap.Int("revision", revision.ID),
+		zap.Strings("changed", summary.Changed),
+		zap.Strings("skipped", summary.Skipped),
+	)
+	h.events.Publish(events.ConfigReloaded, summary)
+	h.reloads.Inc("success")
+	h.lastReload.Set(float64(time.Now().Unix()))
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(summary)
+}
+
+func rollbackTarget(current, revision *config.Config) *config.Config {
+	target := *current
+	target.Backends = revision.Backends
+	target.Routes = revision.Routes
+	target.Maintenance = revision.Maintenance
+	target.LoadBalancer.Method = revision.LoadBalancer.Method
+	target.RateLimit.Tiers = revision.RateLimit.Tiers
+	target.RateLimit.Allowlist = revision.RateLimit.Allowlist
+	return &target
+}
+
+func (h *Handler) revisionFromPath(w http.ResponseWriter, r *http.Request) (configRevision, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid revision ID"})
+		return configRevision{}, false
+	}
+
+	revision, ok := h.history.get(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Config revision not found"})
+		return configRevision{}, false
+	}
+	return revision, true
+}
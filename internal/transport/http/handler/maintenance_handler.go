@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+func (h *Handler) AdminGetMaintenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.maintenance.State())
+}
+
+func (h *Handler) AdminSetMaintenance(w http.ResponseWriter, r *http.Request) {
+	state := h.maintenance.State()
+	request := struct {
+		Enabled    *bool     `json:"enabled"`
+		Pools      *[]string `json:"pools"`
+		Paths      *[]string `json:"paths"`
+		Message    *string   `json:"message"`
+		RetryAfter *int      `json:"retry_after"`
+	}{}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Enabled == nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	state.Enabled = *request.Enabled
+	if request.Pools != nil {
+		state.Pools = *request.Pools
+	}
+	if request.Paths != nil {
+		state.Paths = *request.Paths
+	}
+	if request.Message != nil {
+		state.Message = *request.Message
+	}
+	if request.RetryAfter != nil {
+		state.RetryAfter = *request.RetryAfter
+	}
+
+	if err := h.maintenance.SetState(state); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Maintenance mode updated via admin API",
+		zap.Bool("enabled", state.Enabled),
+		zap.Strings("pools", state.Pools),
+		zap.Strings("paths", state.Paths),
+	)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":     "Maintenance mode updated successfully",
+		"maintenance": h.maintenance.State(),
+	})
+}
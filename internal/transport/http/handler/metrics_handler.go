@@ -0,0 +1,9 @@
+package handler
+
+import (
+	"net/http"
+)
+
+func (h *Handler) AdminMetrics(w http.ResponseWriter, r *http.Request) {
+	h.registry.Handler().ServeHTTP(w, r)
+}
@@ -0,0 +1,361 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"CloudBalancer/config"
+	"CloudBalancer/internal/audit"
+	"CloudBalancer/internal/buildinfo"
+	"CloudBalancer/internal/cache"
+	"CloudBalancer/internal/capture"
+	"CloudBalancer/internal/events"
+	"CloudBalancer/internal/load_balancer"
+	"CloudBalancer/internal/maintenance"
+	"CloudBalancer/internal/rate_limiter"
+	"CloudBalancer/internal/topk"
+)
+
+type apiParam struct {
+	name        string
+	in          string
+	kind        string
+	description string
+	required    bool
+}
+
+type apiOperation struct {
+	method      string
+	path        string
+	summary     string
+	params      []apiParam
+	request     any
+	status      int
+	response    any
+	stream      bool
+	errorStatus []int
+}
+
+type anyObject map[string]any
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+	objectType   = reflect.TypeOf(anyObject{})
+	rawType      = reflect.TypeOf(json.RawMessage{})
+)
+
+func poolQuery() apiParam {
+	return apiParam{name: "pool", in: "query", kind: "string", description: "Backend pool, defaults to " + config.DefaultPool}
+}
+
+func pathParam(name, description string) apiParam {
+	return apiParam{name: name, in: "path", kind: "string", description: description, required: true}
+}
+
+var adminOperations = []apiOperation{
+	{method: "GET", path: "/admin/openapi.json", summary: "This OpenAPI document", response: anyObject{}},
+	{method: "GET", path: "/admin/events", summary: "Live stream of balancer events as server-sent events, or as WebSocket text messages when the request asks for an upgrade", params: []apiParam{
+		{name: "types", in: "query", kind: "string", description: "Comma-separated event types to receive: " + strings.Join(events.Types, ", ")},
+		{name: "last_event_id", in: "query", kind: "string", description: "Replay recent events after this ID; the Last-Event-ID header takes precedence"},
+	}, response: events.Event{}, stream: true, errorStatus: []int{http.StatusBadRequest}},
+	{method: "GET", path: "/admin/audit", summary: "Audit log of admin mutations, newest first", params: []apiParam{
+		{name: "actor", in: "query", kind: "string", description: "Token subject, or anonymous when auth is disabled"},
+		{name: "method", in: "query", kind: "string"},
+		{name: "path", in: "query", kind: "string", description: "Path prefix"},
+		{name: "since", in: "query", kind: "string", description: "RFC 3339 timestamp"},
+		{name: "until", in: "query", kind: "string", description: "RFC 3339 timestamp"},
+		{name: "limit", in: "query", kind: "integer"},
+	}, response: struct {
+		Entries []audit.Entry `json:"entries"`
+	}{}, errorStatus: []int{400}},
+	{method: "GET", path: "/admin/stats", summary: "Backend and rate limiter statistics, with cumulative and last 1m/5m traffic per backend", response: anyObject{}},
+	{method: "GET", path: "/admin/metrics", summary: "Metrics for the proxy, router, rate limiter, health checker and config reloads in Prometheus text format"},
+	{method: "GET", path: "/admin/loglevel", summary: "Current application log level", response: struct {
+		Level string `json:"level"`
+	}{}},
+	{method: "PUT", path: "/admin/loglevel", summary: "Change the application log level without a restart", request: struct {
+		Level string `json:"level"`
+	}{}, response: struct {
+		Level    string `json:"level"`
+		Previous string `json:"previous"`
+	}{}, errorStatus: []int{http.StatusBadRequest}},
+	{method: "GET", path: "/admin/top", summary: "Approximate heaviest clients (by requests and rate limit rejections) and proxied paths (by total latency in ms and by 5xx responses) in the current or previous window", params: []apiParam{
+		{name: "n", in: "query", kind: "integer", description: "Entries per list, default 10"},
+		{name: "window", in: "query", kind: "string", description: "current (default) or previous"},
+	}, response: topk.Report{}, errorStatus: []int{http.StatusBadRequest, http.StatusNotFound}},
+	{method: "GET", path: "/admin/version", summary: "Build version, commit and Go version of the running binary", response: buildinfo.Info{}},
+	{method: "GET", path: "/admin/debug/rules", summary: "Active debug capture rules", response: struct {
+		Rules []capture.Rule `json:"rules"`
+	}{}},
+	{method: "POST", path: "/admin/debug/rules", summary: "Capture sanitized requests and responses for a client ID and/or route until the TTL expires", request: struct {
+		ClientID string `json:"client_id"`
+		Route    string `json:"route"`
+		TTL      string `json:"ttl"`
+	}{}, status: http.StatusCreated, response: capture.Rule{}, errorStatus: []int{http.StatusBadRequest}},
+	{method: "DELETE", path: "/admin/debug/rules/{id}", summary: "Stop a debug capture rule", params: []apiParam{
+		pathParam("id", "Capture rule ID"),
+	}, status: http.StatusNoContent, errorStatus: []int{http.StatusNotFound}},
+	{method: "GET", path: "/admin/debug/captures", summary: "Captured requests and responses, newest first", params: []apiParam{
+		{name: "rule", in: "query", kind: "string", description: "Only captures recorded by this rule"},
+	}, response: struct {
+		Captures []capture.Capture `json:"captures"`
+	}{}},
+	{method: "DELETE", path: "/admin/debug/captures", summary: "Discard all captured requests and responses", response: struct {
+		Cleared int `json:"cleared"`
+	}{}},
+	{method: "POST", path: "/admin/stats/reset", summary: "Reset backend traffic counters", params: []apiParam{
+		{name: "backend", in: "query", kind: "string", description: "Only reset this backend"},
+		{name: "pool", in: "query", kind: "string", description: "Only reset backends in this pool"},
+	}, response: anyObject{}, errorStatus: []int{http.StatusNotFound}},
+	{method: "POST", path: "/admin/strategy", summary: "Change the balancing strategy", request: struct {
+		Strategy string `json:"strategy"`
+	}{}, response: anyObject{}, errorStatus: []int{400}},
+	{method: "GET", path: "/admin/strategies", summary: "List available balancing strategies", response: anyObject{}},
+	{method: "GET", path: "/admin/config", summary: "Effective configuration with secrets redacted", params: []apiParam{
+		{name: "format", in: "query", kind: "string", description: "json (default) or yaml"},
+	}, response: anyObject{}},
+	{method: "POST", path: "/admin/config/reload", summary: "Reload the config file and apply hot-reloadable sections", response: reloadSummary{}, errorStatus: []int{422}},
+	{method: "GET", path: "/admin/config/history", summary: "List recently applied configurations, newest first, with the paths each one changed", response: struct {
+		Revisions []configRevision `json:"revisions"`
+	}{}},
+	{method: "GET", path: "/admin/config/history/{id}", summary: "Get an applied configuration with secrets redacted", params: []apiParam{pathParam("id", "Revision ID")}, response: anyObject{}, errorStatus: []int{400, 404}},
+	{method: "POST", path: "/admin/config/rollback/{id}", summary: "Revert the hot-reloadable sections to an earlier applied configuration", params: []apiParam{pathParam("id", "Revision ID")}, response: reloadSummary{}, errorStatus: []int{400, 404, 422}},
+	{method: "GET", path: "/admin/backends", summary: "List backends with health and traffic stats", params: []apiParam{
+		{name: "pool", in: "query", kind: "string", description: "Only list backends in this pool"},
+	}, response: struct {
+		Backends []backendView `json:"backends"`
+	}{}},
+	{method: "POST", path: "/admin/backends", summary: "Add a backend", request: backendSpec{}, status: http.StatusCreated, response: backendView{}, errorStatus: []int{400, 409}},
+	{method: "GET", path: "/admin/backends/{id}", summary: "Get a backend", params: []apiParam{pathParam("id", "Backend ID")}, response: backendView{}, errorStatus: []int{404}},
+	{method: "PUT", path: "/admin/backends/{id}", summary: "Update a backend, omitted fields keep their current value", params: []apiParam{pathParam("id", "Backend ID")}, request: backendSpec{}, response: backendView{}, errorStatus: []int{400, 404}},
+	{method: "DELETE", path: "/admin/backends/{id}", summary: "Remove a backend", params: []apiParam{pathParam("id", "Backend ID")}, status: http.StatusNoContent, errorStatus: []int{404, 409}},
+	{method: "POST", path: "/admin/backends/{id}/drain", summary: "Stop sending new requests to a backend", params: []apiParam{pathParam("id", "Backend ID")}, response: backendView{}, errorStatus: []int{404}},
+	{method: "DELETE", path: "/admin/backends/{id}/drain", summary: "Resume sending requests to a drained backend", params: []apiParam{pathParam("id", "Backend ID")}, response: backendView{}, errorStatus: []int{404}},
+	{method: "GET", path: "/admin/cache", summary: "Response cache statistics", response: cache.Stats{}, errorStatus: []int{404}},
+	{method: "DELETE", path: "/admin/cache", summary: "Purge cached responses", params: []apiParam{
+		{name: "prefix", in: "query", kind: "string", description: "Only purge keys with this path prefix"},
+	}, response: map[string]int{}, errorStatus: []int{404}},
+	{method: "GET", path: "/admin/routes", summary: "List routes and pools", response: struct {
+		Routes []routeSpec `json:"routes"`
+		Pools  []string    `json:"pools"`
+	}{}},
+	{method: "PUT", path: "/admin/routes", summary: "Replace all routes", request: []routeSpec{}, response: anyObject{}, errorStatus: []int{400}},
+	{method: "POST", path: "/admin/routes", summary: "Create or update a route", request: routeSpec{}, response: anyObject{}, errorStatus: []int{400}},
+	{method: "DELETE", path: "/admin/routes", summary: "Delete a route", request: routeSpec{}, response: anyObject{}, errorStatus: []int{400, 404}},
+	{method: "GET", path: "/admin/maintenance", summary: "Maintenance mode state", response: maintenance.State{}},
+	{method: "PUT", path: "/admin/maintenance", summary: "Change maintenance mode", request: maintenance.State{}, response: anyObject{}, errorStatus: []int{400}},
+	{method: "GET", path: "/admin/canary", summary: "Canary weight and group stats", params: []apiParam{poolQuery()}, response: anyObject{}, errorStatus: []int{404}},
+	{method: "PUT", path: "/admin/canary", summary: "Change the canary weight", params: []apiParam{poolQuery()}, request: struct {
+		Weight float64 `json:"weight"`
+	}{}, response: anyObject{}, errorStatus: []int{400, 404}},
+	{method: "GET", path: "/admin/deployment", summary: "Blue/green deployment status", params: []apiParam{poolQuery()}, response: struct {
+		Deployment load_balancer.DeploymentStatus `json:"deployment"`
+		Groups     map[string]groupStat           `json:"groups"`
+	}{}, errorStatus: []int{404}},
+	{method: "POST", path: "/admin/deployment", summary: "Switch the active blue/green group", params: []apiParam{poolQuery()}, request: struct {
+		Group        string `json:"group"`
+		AutoRollback bool   `json:"auto_rollback"`
+	}{}, response: anyObject{}, errorStatus: []int{400, 404}},
+	{method: "GET", path: "/admin/ratelimit", summary: "List per-client rate limit overrides", params: []apiParam{
+		{name: "offset", in: "query", kind: "integer"},
+		{name: "limit", in: "query", kind: "integer"},
+		{name: "prefix", in: "query", kind: "string", description: "Client ID prefix"},
+		{name: "min_rate", in: "query", kind: "number"},
+		{name: "max_rate", in: "query", kind: "number"},
+	}, response: ClientLimitsPage{}, errorStatus: []int{400}},
+	{method: "GET", path: "/admin/ratelimit/{clientID}", summary: "Get a client's rate limit", params: []apiParam{pathParam("clientID", "Client ID")}, response: RateLimitRequest{}},
+	{method: "POST", path: "/admin/ratelimit/{clientID}", summary: "Set a client's rate limit or tier", params: []apiParam{pathParam("clientID", "Client ID")}, request: RateLimitRequest{}, status: http.StatusCreated, errorStatus: []int{400}},
+	{method: "PUT", path: "/admin/ratelimit/{clientID}", summary: "Update a client's rate limit or tier", params: []apiParam{pathParam("clientID", "Client ID")}, request: RateLimitRequest{}, errorStatus: []int{400}},
+	{method: "DELETE", path: "/admin/ratelimit/{clientID}", summary: "Remove a client's rate limit override", params: []apiParam{pathParam("clientID", "Client ID")}, status: http.StatusNoContent},
+	{method: "POST", path: "/admin/ratelimit/{clientID}/reset", summary: "Reset a client's limiter and ban state", params: []apiParam{pathParam("clientID", "Client ID")}, status: http.StatusNoContent},
+	{method: "GET", path: "/admin/ratelimit/export", summary: "Export all client rate limits", response: []ClientLimits{}},
+	{method: "POST", path: "/admin/ratelimit/import", summary: "Import client rate limits", params: []apiParam{
+		{name: "replace", in: "query", kind: "boolean", description: "Remove overrides missing from the import"},
+	}, request: []ClientLimits{}, response: anyObject{}, errorStatus: []int{400}},
+	{method: "GET", path: "/admin/ratelimit/allowlist", summary: "Rate limit allowlist", response: config.AllowlistConfig{}},
+	{method: "PUT", path: "/admin/ratelimit/allowlist", summary: "Replace the rate limit allowlist", request: config.AllowlistConfig{}, response: config.AllowlistConfig{}, errorStatus: []int{400}},
+	{method: "GET", path: "/admin/ratelimit/bans", summary: "List active bans", response: []rate_limiter.Ban{}},
+	{method: "DELETE", path: "/admin/ratelimit/bans", summary: "Lift a ban", params: []apiParam{
+		{name: "client", in: "query", kind: "string", required: true},
+	}, status: http.StatusNoContent, errorStatus: []int{400, 404}},
+	{method: "GET", path: "/admin/ratelimit/tiers", summary: "List rate limit tiers", response: []rate_limiter.TierInfo{}},
+	{method: "PUT", path: "/admin/ratelimit/tiers", summary: "Create or update a tier", params: []apiParam{
+		{name: "name", in: "query", kind: "string", required: true},
+	}, request: rate_limiter.Tier{}, response: []rate_limiter.TierInfo{}, errorStatus: []int{400}},
+	{method: "DELETE", path: "/admin/ratelimit/tiers", summary: "Delete a tier", params: []apiParam{
+		{name: "name", in: "query", kind: "string", required: true},
+	}, status: http.StatusNoContent, errorStatus: []int{404, 409}},
+	{method: "GET", path: "/admin/ratelimit/metrics", summary: "Rate limiter outcomes per route and client", params: []apiParam{
+		{name: "limit", in: "query", kind: "integer"},
+	}, response: anyObject{}, errorStatus: []int{400}},
+}
+
+func schemaOf(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return map[string]any{"type": "integer", "description": "Nanoseconds"}
+	case t == objectType:
+		return map[string]any{"type": "object"}
+	case t == rawType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaOf(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]any)
+		addProperties(t, properties)
+		return map[string]any{"type": "object", "properties": properties}
+	default:
+		return map[string]any{}
+	}
+}
+
+func addProperties(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addProperties(field.Type, properties)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = schemaOf(field.Type)
+	}
+}
+
+func jsonContent(value any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{"schema": schemaOf(reflect.TypeOf(value))},
+	}
+}
+
+func openAPIDocument() map[string]any {
+	errorContent := jsonContent(map[string]string{})
+	errorContent["text/plain"] = map[string]any{"schema": map[string]any{"type": "string"}}
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content":     errorContent,
+	}
+
+	paths := make(map[string]any)
+	for _, op := range adminOperations {
+		status := op.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		success := map[string]any{"description": http.StatusText(status)}
+		if op.response != nil {
+			success["content"] = jsonContent(op.response)
+		}
+		if op.stream {
+			success["content"] = map[string]any{
+				"text/event-stream": map[string]any{"schema": schemaOf(reflect.TypeOf(op.response))},
+			}
+		}
+		responses := map[string]any{
+			strconv.Itoa(status): success,
+			"401":                map[string]any{"description": "Missing or invalid bearer token"},
+			"403":                map[string]any{"description": "Token lacks the required role"},
+		}
+		for _, code := range op.errorStatus {
+			responses[strconv.Itoa(code)] = errorResponse
+		}
+
+		operation := map[string]any{
+			"summary":   op.summary,
+			"responses": responses,
+		}
+		if len(op.params) > 0 {
+			params := make([]any, 0, len(op.params))
+			for _, p := range op.params {
+				param := map[string]any{
+					"name":     p.name,
+					"in":       p.in,
+					"required": p.required,
+					"schema":   map[string]any{"type": p.kind},
+				}
+				if p.description != "" {
+					param["description"] = p.description
+				}
+				params = append(params, param)
+			}
+			operation["parameters"] = params
+		}
+		if op.request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(op.request),
+			}
+		}
+
+		path := strings.TrimPrefix(op.path, "/admin")
+		item, ok := paths[path].(map[string]any)
+		if !ok {
+			item = make(map[string]any)
+			paths[path] = item
+		}
+		item[strings.ToLower(op.method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "CloudBalancer Admin API",
+			"version": "1.0.0",
+			"description": "Read-only operations require the viewer role, all other operations require the operator role. " +
+				"Every operation is also reachable under its unversioned /admin path, which is deprecated.",
+		},
+		"servers": []any{
+			map[string]any{"url": "/api/v1", "description": "Dedicated admin listener, used when admin.address is set"},
+			map[string]any{"url": "/admin/api/v1", "description": "Public listener, used when admin.address is not set, so /api/v1 stays free for proxied traffic"},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":         "http",
+					"scheme":       "bearer",
+					"bearerFormat": "JWT",
+				},
+			},
+		},
+		"security": []any{map[string]any{"bearerAuth": []any{}}},
+	}
+}
+
+func (h *Handler) AdminOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(openAPIDocument())
+}
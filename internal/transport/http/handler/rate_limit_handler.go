@@ -2,9 +2,16 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 
+	"CloudBalancer/config"
 	"CloudBalancer/internal/rate_limiter"
 
 	"go.uber.org/zap"
@@ -12,12 +19,20 @@ import (
 
 type RateLimitHandler struct {
 	rateLimiter rate_limiter.RateLimiter
+	allowlist   *rate_limiter.Allowlist
+	bans        *rate_limiter.BanList
+	tiers       *rate_limiter.Tiers
+	metrics     *rate_limiter.Metrics
 	logger      *zap.Logger
 }
 
-func NewRateLimitHandler(rateLimiter rate_limiter.RateLimiter, logger *zap.Logger) *RateLimitHandler {
+func NewRateLimitHandler(rateLimiter rate_limiter.RateLimiter, allowlist *rate_limiter.Allowlist, bans *rate_limiter.BanList, tiers *rate_limiter.Tiers, metrics *rate_limiter.Metrics, logger *zap.Logger) *RateLimitHandler {
 	return &RateLimitHandler{
 		rateLimiter: rateLimiter,
+		allowlist:   allowlist,
+		bans:        bans,
+		tiers:       tiers,
+		metrics:     metrics,
 		logger:      logger,
 	}
 }
@@ -25,45 +40,169 @@ func NewRateLimitHandler(rateLimiter rate_limiter.RateLimiter, logger *zap.Logge
 type RateLimitRequest struct {
 	Rate  float64 `json:"rate"`
 	Burst int     `json:"burst"`
+	Tier  string  `json:"tier,omitempty"`
 }
 
-func (h *RateLimitHandler) HandleRateLimit(w http.ResponseWriter, r *http.Request) {
-	h.logger.Debug("Rate limit API request",
-		zap.String("method", r.Method),
-		zap.String("path", r.URL.Path),
-	)
+type ClientLimits struct {
+	ClientID string  `json:"client_id"`
+	Rate     float64 `json:"rate"`
+	Burst    int     `json:"burst"`
+}
+
+type ClientLimitsPage struct {
+	Total   int            `json:"total"`
+	Offset  int            `json:"offset"`
+	Limit   int            `json:"limit"`
+	Clients []ClientLimits `json:"clients"`
+}
+
+const (
+	defaultPageLimit = 100
+	maxPageLimit     = 1000
+)
 
-	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) < 4 {
-		h.logger.Debug("Invalid rate limit API URL format")
-		http.Error(w, "Invalid URL format. Use /admin/ratelimit/{clientID}", http.StatusBadRequest)
+func (h *RateLimitHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	offset, err := queryInt(query, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(query, "limit", defaultPageLimit)
+	if err != nil || limit <= 0 || limit > maxPageLimit {
+		http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxPageLimit), http.StatusBadRequest)
+		return
+	}
+	minRate, err := queryFloat(query, "min_rate", math.Inf(-1))
+	if err != nil {
+		http.Error(w, "min_rate must be a number", http.StatusBadRequest)
 		return
 	}
-	clientID := parts[3]
-	h.logger.Debug("Processing rate limit for client", zap.String("clientID", clientID))
+	maxRate, err := queryFloat(query, "max_rate", math.Inf(1))
+	if err != nil {
+		http.Error(w, "max_rate must be a number", http.StatusBadRequest)
+		return
+	}
+	prefix := query.Get("prefix")
 
-	switch r.Method {
-	case http.MethodGet:
-		h.getRateLimit(w, clientID)
-	case http.MethodPost:
-		h.createRateLimit(w, r, clientID)
-	case http.MethodPut:
-		h.updateRateLimit(w, r, clientID)
-	case http.MethodDelete:
-		h.deleteRateLimit(w, clientID)
-	default:
-		h.logger.Debug("Unsupported method for rate limit API", zap.String("method", r.Method))
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	clients := make([]ClientLimits, 0)
+	for _, client := range h.clientLimits() {
+		if strings.HasPrefix(client.ClientID, prefix) && client.Rate >= minRate && client.Rate <= maxRate {
+			clients = append(clients, client)
+		}
 	}
+
+	page := ClientLimitsPage{Total: len(clients), Offset: offset, Limit: limit}
+	start, end := min(offset, len(clients)), min(offset+limit, len(clients))
+	page.Clients = clients[start:end]
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+func (h *RateLimitHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="ratelimits.json"`)
+	json.NewEncoder(w).Encode(h.clientLimits())
 }
 
-func (h *RateLimitHandler) getRateLimit(w http.ResponseWriter, clientID string) {
+func (h *RateLimitHandler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	replace, err := strconv.ParseBool(r.URL.Query().Get("replace"))
+	if err != nil && r.URL.Query().Has("replace") {
+		http.Error(w, "replace must be a boolean", http.StatusBadRequest)
+		return
+	}
+
+	var clients []ClientLimits
+	if err := json.NewDecoder(r.Body).Decode(&clients); err != nil {
+		h.logger.Debug("Error decoding request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	seen := make(map[string]bool, len(clients))
+	for i, client := range clients {
+		if client.ClientID == "" {
+			http.Error(w, fmt.Sprintf("entry %d: client_id is required", i), http.StatusBadRequest)
+			return
+		}
+		if seen[client.ClientID] {
+			http.Error(w, fmt.Sprintf("entry %d: duplicate client_id %q", i, client.ClientID), http.StatusBadRequest)
+			return
+		}
+		if client.Rate <= 0 || client.Burst <= 0 {
+			http.Error(w, fmt.Sprintf("entry %d: rate and burst must be positive", i), http.StatusBadRequest)
+			return
+		}
+		seen[client.ClientID] = true
+	}
+
+	removed := 0
+	if replace {
+		for clientID := range h.rateLimiter.ListClientLimits() {
+			if !seen[clientID] {
+				if err := h.deleteClient(clientID); err != nil {
+					h.writeMutationError(w, err)
+					return
+				}
+				removed++
+			}
+		}
+	}
+	for _, client := range clients {
+		if err := h.setClient(client.ClientID, client.Rate, client.Burst); err != nil {
+			h.writeMutationError(w, err)
+			return
+		}
+	}
+
+	h.logger.Info("Client rate limits imported",
+		zap.Int("imported", len(clients)),
+		zap.Int("removed", removed),
+		zap.Bool("replace", replace),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{
+		"imported": len(clients),
+		"removed":  removed,
+	})
+}
+
+func (h *RateLimitHandler) clientLimits() []ClientLimits {
+	clients := make([]ClientLimits, 0)
+	for clientID, limits := range h.rateLimiter.ListClientLimits() {
+		clients = append(clients, ClientLimits{ClientID: clientID, Rate: limits.Rate, Burst: limits.Burst})
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ClientID < clients[j].ClientID
+	})
+	return clients
+}
+
+func queryInt(query url.Values, key string, def int) (int, error) {
+	if value := query.Get(key); value != "" {
+		return strconv.Atoi(value)
+	}
+	return def, nil
+}
+
+func queryFloat(query url.Values, key string, def float64) (float64, error) {
+	if value := query.Get(key); value != "" {
+		return strconv.ParseFloat(value, 64)
+	}
+	return def, nil
+}
+
+func (h *RateLimitHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	clientID := r.PathValue("clientID")
 	h.logger.Debug("Getting rate limit for client", zap.String("clientID", clientID))
 
 	limits := h.rateLimiter.GetClientLimits(clientID)
 	response := RateLimitRequest{
 		Rate:  limits.Rate,
 		Burst: limits.Burst,
+		Tier:  h.tiers.TierOf(clientID),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -73,7 +212,8 @@ func (h *RateLimitHandler) getRateLimit(w http.ResponseWriter, clientID string)
 	}
 }
 
-func (h *RateLimitHandler) createRateLimit(w http.ResponseWriter, r *http.Request, clientID string) {
+func (h *RateLimitHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	clientID := r.PathValue("clientID")
 	h.logger.Debug("Creating rate limit for client", zap.String("clientID", clientID))
 
 	var limits RateLimitRequest
@@ -83,6 +223,11 @@ func (h *RateLimitHandler) createRateLimit(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if limits.Tier != "" {
+		h.assignTier(w, clientID, limits.Tier, http.StatusCreated)
+		return
+	}
+
 	if limits.Rate <= 0 || limits.Burst <= 0 {
 		h.logger.Debug("Invalid rate/burst values",
 			zap.Float64("rate", limits.Rate),
@@ -92,7 +237,10 @@ func (h *RateLimitHandler) createRateLimit(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	h.rateLimiter.SetClientLimits(clientID, limits.Rate, limits.Burst)
+	if err := h.setClient(clientID, limits.Rate, limits.Burst); err != nil {
+		h.writeMutationError(w, err)
+		return
+	}
 	h.logger.Info("Rate limit created for client",
 		zap.String("clientID", clientID),
 		zap.Float64("rate", limits.Rate),
@@ -102,7 +250,8 @@ func (h *RateLimitHandler) createRateLimit(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusCreated)
 }
 
-func (h *RateLimitHandler) updateRateLimit(w http.ResponseWriter, r *http.Request, clientID string) {
+func (h *RateLimitHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	clientID := r.PathValue("clientID")
 	h.logger.Debug("Updating rate limit for client", zap.String("clientID", clientID))
 
 	var limits RateLimitRequest
@@ -112,6 +261,11 @@ func (h *RateLimitHandler) updateRateLimit(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if limits.Tier != "" {
+		h.assignTier(w, clientID, limits.Tier, http.StatusOK)
+		return
+	}
+
 	if limits.Rate <= 0 || limits.Burst <= 0 {
 		h.logger.Debug("Invalid rate/burst values",
 			zap.Float64("rate", limits.Rate),
@@ -121,10 +275,17 @@ func (h *RateLimitHandler) updateRateLimit(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	h.rateLimiter.UpdateClientLimits(clientID, func(ul *rate_limiter.UserLimits) {
+	if err := h.tiers.Unassign(clientID); err != nil {
+		h.writeMutationError(w, err)
+		return
+	}
+	if err := h.rateLimiter.UpdateClientLimits(clientID, func(ul *rate_limiter.UserLimits) {
 		ul.Rate = limits.Rate
 		ul.Burst = limits.Burst
-	})
+	}); err != nil {
+		h.writeMutationError(w, err)
+		return
+	}
 
 	h.logger.Info("Rate limit updated for client",
 		zap.String("clientID", clientID),
@@ -135,11 +296,184 @@ func (h *RateLimitHandler) updateRateLimit(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 }
 
-func (h *RateLimitHandler) deleteRateLimit(w http.ResponseWriter, clientID string) {
+func (h *RateLimitHandler) HandleReset(w http.ResponseWriter, r *http.Request) {
+	clientID := r.PathValue("clientID")
+
+	h.rateLimiter.Reset(clientID)
+	h.bans.Reset(clientID)
+	h.logger.Info("Rate limit reset for client", zap.String("clientID", clientID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RateLimitHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	clientID := r.PathValue("clientID")
 	h.logger.Debug("Deleting rate limit for client", zap.String("clientID", clientID))
 
-	h.rateLimiter.DeleteClientLimits(clientID)
+	if err := h.deleteClient(clientID); err != nil {
+		h.writeMutationError(w, err)
+		return
+	}
 	h.logger.Info("Rate limit deleted for client", zap.String("clientID", clientID))
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *RateLimitHandler) setClient(clientID string, rate float64, burst int) error {
+	if err := h.tiers.Unassign(clientID); err != nil {
+		return err
+	}
+	return h.rateLimiter.SetClientLimits(clientID, rate, burst)
+}
+
+func (h *RateLimitHandler) deleteClient(clientID string) error {
+	if err := h.tiers.Unassign(clientID); err != nil {
+		return err
+	}
+	return h.rateLimiter.DeleteClientLimits(clientID)
+}
+
+func (h *RateLimitHandler) writeMutationError(w http.ResponseWriter, err error) {
+	if !errors.Is(err, rate_limiter.ErrStore) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.logger.Error("Failed to persist rate limit change", zap.Error(err))
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func (h *RateLimitHandler) HandleGetAllowlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.allowlist.Config())
+}
+
+func (h *RateLimitHandler) HandleSetAllowlist(w http.ResponseWriter, r *http.Request) {
+	var allowlist config.AllowlistConfig
+	if err := json.NewDecoder(r.Body).Decode(&allowlist); err != nil {
+		h.logger.Debug("Error decoding request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.allowlist.Set(allowlist); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.logger.Info("Rate limit allowlist updated",
+		zap.Int("clients", len(allowlist.Clients)),
+		zap.Int("cidrs", len(allowlist.CIDRs)),
+		zap.Int("headers", len(allowlist.Headers)),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.allowlist.Config())
+}
+
+func (h *RateLimitHandler) HandleListBans(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.bans.List())
+}
+
+func (h *RateLimitHandler) HandleLiftBan(w http.ResponseWriter, r *http.Request) {
+	clientID := r.URL.Query().Get("client")
+	if clientID == "" {
+		http.Error(w, "Missing client query parameter", http.StatusBadRequest)
+		return
+	}
+
+	if !h.bans.Lift(clientID) {
+		http.Error(w, "Client is not banned", http.StatusNotFound)
+		return
+	}
+
+	h.logger.Info("Rate limit ban lifted", zap.String("clientID", clientID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RateLimitHandler) assignTier(w http.ResponseWriter, clientID, tier string, status int) {
+	if err := h.tiers.Assign(clientID, tier); err != nil {
+		h.writeMutationError(w, err)
+		return
+	}
+
+	h.logger.Info("Client assigned to rate limit tier",
+		zap.String("clientID", clientID),
+		zap.String("tier", tier),
+	)
+
+	w.WriteHeader(status)
+}
+
+func (h *RateLimitHandler) HandleListTiers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.tiers.List())
+}
+
+func (h *RateLimitHandler) HandleSetTier(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	var tier rate_limiter.Tier
+	if err := json.NewDecoder(r.Body).Decode(&tier); err != nil {
+		h.logger.Debug("Error decoding request body", zap.Error(err))
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.tiers.Set(name, tier); err != nil {
+		h.writeMutationError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.tiers.List())
+}
+
+func (h *RateLimitHandler) HandleDeleteTier(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	switch err := h.tiers.Delete(name); {
+	case errors.Is(err, rate_limiter.ErrUnknownTier):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, rate_limiter.ErrTierInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		h.logger.Info("Rate limit tier deleted", zap.String("tier", name))
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type ClientMetrics struct {
+	ClientID string `json:"client_id"`
+	rate_limiter.Counters
+	Tokens float64 `json:"tokens"`
+}
+
+func (h *RateLimitHandler) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	limit, err := queryInt(r.URL.Query(), "limit", defaultPageLimit)
+	if err != nil || limit <= 0 || limit > maxPageLimit {
+		http.Error(w, fmt.Sprintf("limit must be between 1 and %d", maxPageLimit), http.StatusBadRequest)
+		return
+	}
+
+	clients := make([]ClientMetrics, 0)
+	for clientID, counters := range h.metrics.Clients() {
+		clients = append(clients, ClientMetrics{ClientID: clientID, Counters: counters})
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].Rejected() != clients[j].Rejected() {
+			return clients[i].Rejected() > clients[j].Rejected()
+		}
+		return clients[i].ClientID < clients[j].ClientID
+	})
+	clients = clients[:min(limit, len(clients))]
+	for i := range clients {
+		clients[i].Tokens = h.rateLimiter.GetTokens(clients[i].ClientID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"total":    h.metrics.Total(),
+		"routes":   h.metrics.Routes(),
+		"clients":  clients,
+		"limiters": h.rateLimiter.StoreStats(),
+	})
+}
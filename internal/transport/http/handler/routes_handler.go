@@ -0,0 +1,377 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"CloudBalancer/config"
+
+	"go.uber.org/zap"
+)
+
+type routeSpec struct {
+	Host         string                      `json:"host,omitempty"`
+	Path         string                      `json:"path"`
+	PathRegex    string                      `json:"path_regex,omitempty"`
+	Rewrite      string                      `json:"rewrite,omitempty"`
+	Pool         string                      `json:"pool"`
+	Methods      []string                    `json:"methods,omitempty"`
+	MatchHeaders map[string]string           `json:"match_headers,omitempty"`
+	Timeout      string                      `json:"timeout,omitempty"`
+	Streaming    bool                        `json:"streaming,omitempty"`
+	MaxBodySize  int64                       `json:"max_body_size,omitempty"`
+	CacheTTL     string                      `json:"cache_ttl,omitempty"`
+	Hedge        *hedgeSpec                  `json:"hedge,omitempty"`
+	Retry        *retrySpec                  `json:"retry,omitempty"`
+	Static       *staticSpec                 `json:"static,omitempty"`
+	Cost         int                         `json:"cost,omitempty"`
+	Headers      config.HeaderRulesConfig    `json:"headers"`
+	AccessLog    config.RouteAccessLogConfig `json:"access_log"`
+}
+
+type hedgeSpec struct {
+	Percentile float64 `json:"percentile,omitempty"`
+	Delay      string  `json:"delay,omitempty"`
+}
+
+type retrySpec struct {
+	Attempts      *int     `json:"attempts,omitempty"`
+	Methods       []string `json:"methods,omitempty"`
+	PerTryTimeout string   `json:"per_try_timeout,omitempty"`
+}
+
+type staticSpec struct {
+	Root         string `json:"root"`
+	Index        string `json:"index,omitempty"`
+	SPA          bool   `json:"spa,omitempty"`
+	CacheControl string `json:"cache_control,omitempty"`
+	StripPrefix  bool   `json:"strip_prefix,omitempty"`
+}
+
+func newRouteSpec(route config.RouteConfig) routeSpec {
+	spec := routeSpec{
+		Host:         route.Host,
+		Path:         route.Path,
+		PathRegex:    route.PathRegex,
+		Rewrite:      route.Rewrite,
+		Pool:         route.Pool,
+		Methods:      route.Methods,
+		MatchHeaders: route.MatchHeaders,
+		Streaming:    route.Streaming,
+		MaxBodySize:  route.MaxBodySize,
+		Cost:         route.Cost,
+		Headers:      route.Headers,
+		AccessLog:    route.AccessLog,
+	}
+	if route.Timeout > 0 {
+		spec.Timeout = route.Timeout.String()
+	}
+	if route.CacheTTL > 0 {
+		spec.CacheTTL = route.CacheTTL.String()
+	}
+	if route.Hedge.Enabled {
+		spec.Hedge = &hedgeSpec{Percentile: route.Hedge.Percentile}
+		if route.Hedge.Delay > 0 {
+			spec.Hedge.Delay = route.Hedge.Delay.String()
+		}
+	}
+	if route.Retry.Attempts != nil || len(route.Retry.Methods) > 0 || route.Retry.PerTryTimeout > 0 {
+		spec.Retry = &retrySpec{Attempts: route.Retry.Attempts, Methods: route.Retry.Methods}
+		if route.Retry.PerTryTimeout > 0 {
+			spec.Retry.PerTryTimeout = route.Retry.PerTryTimeout.String()
+		}
+	}
+	if route.Static.Root != "" {
+		spec.Static = &staticSpec{
+			Root:         route.Static.Root,
+			Index:        route.Static.Index,
+			SPA:          route.Static.SPA,
+			CacheControl: route.Static.CacheControl,
+			StripPrefix:  route.Static.StripPrefix,
+		}
+	}
+	return spec
+}
+
+func (s routeSpec) toConfig() (config.RouteConfig, error) {
+	route := config.RouteConfig{
+		Host:        strings.ToLower(s.Host),
+		Path:        s.Path,
+		Pool:        s.Pool,
+		Streaming:   s.Streaming,
+		MaxBodySize: s.MaxBodySize,
+		Cost:        s.Cost,
+		Headers:     s.Headers,
+		AccessLog:   s.AccessLog,
+	}
+	for _, method := range s.Methods {
+		route.Methods = append(route.Methods, strings.ToUpper(method))
+	}
+	if len(s.MatchHeaders) > 0 {
+		route.MatchHeaders = make(map[string]string, len(s.MatchHeaders))
+		for name, value := range s.MatchHeaders {
+			route.MatchHeaders[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	if route.Path == "" {
+		route.Path = "/"
+	}
+	if s.Static != nil {
+		route.Static = config.StaticConfig{
+			Root:         s.Static.Root,
+			Index:        s.Static.Index,
+			SPA:          s.Static.SPA,
+			CacheControl: s.Static.CacheControl,
+			StripPrefix:  s.Static.StripPrefix,
+		}
+	}
+	if route.Pool == "" && route.Static.Root == "" {
+		route.Pool = config.DefaultPool
+	}
+
+	var err error
+	if s.Timeout != "" {
+		if route.Timeout, err = time.ParseDuration(s.Timeout); err != nil {
+			return route, fmt.Errorf("invalid timeout %q: %w", s.Timeout, err)
+		}
+	}
+	if s.CacheTTL != "" {
+		if route.CacheTTL, err = time.ParseDuration(s.CacheTTL); err != nil {
+			return route, fmt.Errorf("invalid cache TTL %q: %w", s.CacheTTL, err)
+		}
+	}
+	if s.Hedge != nil {
+		route.Hedge = config.HedgeConfig{Enabled: true, Percentile: s.Hedge.Percentile}
+		if s.Hedge.Delay != "" {
+			if route.Hedge.Delay, err = time.ParseDuration(s.Hedge.Delay); err != nil {
+				return route, fmt.Errorf("invalid hedge delay %q: %w", s.Hedge.Delay, err)
+			}
+		}
+	}
+	if s.Retry != nil {
+		route.Retry.Attempts = s.Retry.Attempts
+		for _, method := range s.Retry.Methods {
+			route.Retry.Methods = append(route.Retry.Methods, strings.ToUpper(method))
+		}
+		if s.Retry.PerTryTimeout != "" {
+			if route.Retry.PerTryTimeout, err = time.ParseDuration(s.Retry.PerTryTimeout); err != nil {
+				return route, fmt.Errorf("invalid retry per-try timeout %q: %w", s.Retry.PerTryTimeout, err)
+			}
+		}
+	}
+
+	return route, config.ValidateRoute(route)
+}
+
+func (h *Handler) AdminGetRoutes(w http.ResponseWriter, r *http.Request) {
+	routes := h.routes.Routes()
+	specs := make([]routeSpec, 0, len(routes))
+	for _, route := range routes {
+		specs = append(specs, newRouteSpec(route))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"routes": specs,
+		"pools":  h.loadBalancer.GetPools(),
+	})
+}
+
+func (h *Handler) AdminReplaceRoutes(w http.ResponseWriter, r *http.Request) {
+	var specs []routeSpec
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&specs); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	routes := make([]config.RouteConfig, 0, len(specs))
+	for i, spec := range specs {
+		route, err := h.validateRouteSpec(spec)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("route #%d: %s", i, err)})
+			return
+		}
+		for j, other := range routes {
+			if config.RoutesOverlap(other, route) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("route #%d overlaps route #%d", i, j)})
+				return
+			}
+		}
+		routes = append(routes, route)
+	}
+
+	h.routesMu.Lock()
+	err := h.routes.SetRoutes(routes)
+	h.routesMu.Unlock()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Routes replaced via admin API", zap.Int("routes", len(routes)))
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Routes replaced successfully",
+		"routes":  len(routes),
+	})
+}
+
+func (h *Handler) AdminUpsertRoute(w http.ResponseWriter, r *http.Request) {
+	var spec routeSpec
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	route, err := h.validateRouteSpec(spec)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.routesMu.Lock()
+	routes := h.routes.Routes()
+	index := slices.IndexFunc(routes, func(existing config.RouteConfig) bool {
+		return sameMatcher(existing, route)
+	})
+	for i, existing := range routes {
+		if i != index && config.RoutesOverlap(existing, route) {
+			h.routesMu.Unlock()
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("route overlaps existing route #%d", i)})
+			return
+		}
+	}
+	if index >= 0 {
+		routes[index] = route
+	} else {
+		routes = append(routes, route)
+	}
+	err = h.routes.SetRoutes(routes)
+	h.routesMu.Unlock()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Route updated via admin API",
+		zap.String("host", route.Host),
+		zap.String("path", route.Path),
+		zap.String("pool", route.Pool),
+	)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Route saved successfully",
+		"route":   newRouteSpec(route),
+	})
+}
+
+func (h *Handler) AdminDeleteRoute(w http.ResponseWriter, r *http.Request) {
+	var spec routeSpec
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewDecoder(r.Body).Decode(&spec); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+
+	route, err := spec.toConfig()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.routesMu.Lock()
+	defer h.routesMu.Unlock()
+
+	routes := h.routes.Routes()
+	index := slices.IndexFunc(routes, func(existing config.RouteConfig) bool {
+		return sameMatcher(existing, route)
+	})
+	if index < 0 {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Route not found"})
+		return
+	}
+	if err := h.routes.SetRoutes(slices.Delete(routes, index, index+1)); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("Route deleted via admin API",
+		zap.String("host", route.Host),
+		zap.String("path", route.Path),
+	)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Route deleted successfully"})
+}
+
+func sameMatcher(a, b config.RouteConfig) bool {
+	return a.Host == b.Host &&
+		a.Path == b.Path &&
+		a.PathRegex == b.PathRegex &&
+		slices.Equal(slices.Sorted(slices.Values(a.Methods)), slices.Sorted(slices.Values(b.Methods))) &&
+		maps.Equal(a.MatchHeaders, b.MatchHeaders)
+}
+
+func (h *Handler) validateRouteSpec(spec routeSpec) (config.RouteConfig, error) {
+	route, err := spec.toConfig()
+	if err != nil {
+		return route, err
+	}
+	if err := h.rateLimitConfig().ValidateCost(route.Cost); err != nil {
+		return route, err
+	}
+	if route.Static.Root != "" && !h.staticRootConfigured(route.Static.Root) {
+		return route, fmt.Errorf("static root %q is not used by any route in the config file", route.Static.Root)
+	}
+	if route.Static.Root == "" && !slices.Contains(h.loadBalancer.GetPools(), route.Pool) {
+		return route, fmt.Errorf("unknown backend pool %q", route.Pool)
+	}
+	return route, nil
+}
+
+func (h *Handler) rateLimitConfig() config.RateLimitConfig {
+	h.configMu.RLock()
+	defer h.configMu.RUnlock()
+	return h.config.RateLimit
+}
+
+func (h *Handler) staticRootConfigured(root string) bool {
+	h.configMu.RLock()
+	defer h.configMu.RUnlock()
+
+	for _, route := range h.config.Routes {
+		if route.Static.Root != "" && filepath.Clean(route.Static.Root) == filepath.Clean(root) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+
+	"CloudBalancer/internal/errorpage"
+	"CloudBalancer/internal/headers"
+	"CloudBalancer/internal/requestid"
+	"CloudBalancer/internal/routing"
+	"CloudBalancer/internal/static"
+
+	"go.uber.org/zap"
+)
+
+func (h *Handler) serveStatic(w http.ResponseWriter, r *http.Request, route *routing.Route) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		h.errorPages.Write(w, r, http.StatusMethodNotAllowed, errorpage.CodeMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	file, err := route.Static.Open(r.URL.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, static.ErrHidden) {
+			h.errorPages.Write(w, r, http.StatusNotFound, errorpage.CodeNotFound, "Not found")
+			return
+		}
+		h.logger.Error("Failed to open static file",
+			requestid.Field(r.Context()),
+			zap.String("path", r.URL.Path),
+			zap.Error(err),
+		)
+		h.errorPages.Write(w, r, http.StatusInternalServerError, errorpage.CodeInternalError, "Failed to read static file")
+		return
+	}
+	defer file.Close()
+
+	route.ResponseHeaders.Transform(w.Header(), &headers.Context{Request: r})
+	file.Serve(w, r)
+}
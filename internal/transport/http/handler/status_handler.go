@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	"CloudBalancer/internal/buildinfo"
+
+	"go.uber.org/zap"
+)
+
+const statusRecentEvents = 20
+
+//go:embed status.html
+var statusHTML string
+
+var statusTemplate = template.Must(template.New("status").Parse(statusHTML))
+
+type statusBackend struct {
+	ID                string
+	Pool              string
+	URL               string
+	State             string
+	Class             string
+	ActiveConnections int64
+	TotalRequests     int64
+	FailedRequests    int64
+	LatencyP95Ms      float64
+}
+
+type statusEvent struct {
+	Time time.Time
+	Type string
+	Data string
+}
+
+func (h *Handler) Status(w http.ResponseWriter, r *http.Request) {
+	build := buildinfo.Get()
+	page := struct {
+		Version     string
+		Commit      string
+		Started     time.Time
+		Uptime      time.Duration
+		Now         time.Time
+		Strategy    string
+		Maintenance bool
+		Healthy     int
+		Backends    []statusBackend
+		Events      []statusEvent
+	}{
+		Version:     build.Version,
+		Commit:      build.Commit,
+		Started:     build.StartedAt,
+		Uptime:      buildinfo.Uptime().Truncate(time.Second),
+		Now:         time.Now(),
+		Strategy:    h.loadBalancer.GetStrategy().Name(),
+		Maintenance: h.maintenance.State().Enabled,
+	}
+
+	for _, b := range h.loadBalancer.GetBackends() {
+		backend := statusBackend{
+			ID:                b.ID,
+			Pool:              b.Pool,
+			URL:               b.URL.String(),
+			State:             "healthy",
+			Class:             "ok",
+			ActiveConnections: b.ActiveConnections(),
+			TotalRequests:     b.TotalRequests(),
+			FailedRequests:    b.FailedRequests(),
+			LatencyP95Ms:      b.Stats.Window(time.Minute).LatencyP95Ms,
+		}
+		switch {
+		case !b.IsHealthy():
+			backend.State, backend.Class = "unhealthy", "bad"
+		case b.IsDraining():
+			backend.State, backend.Class = "draining", "warn"
+		}
+		if b.IsHealthy() {
+			page.Healthy++
+		}
+		page.Backends = append(page.Backends, backend)
+	}
+
+	for _, event := range h.events.Recent(statusRecentEvents) {
+		var data string
+		if event.Data != nil {
+			raw, _ := json.Marshal(event.Data)
+			data = string(raw)
+		}
+		page.Events = append(page.Events, statusEvent{Time: event.Time, Type: event.Type, Data: data})
+	}
+
+	var buf bytes.Buffer
+	if err := statusTemplate.Execute(&buf, page); err != nil {
+		h.logger.Error("Failed to render status page", zap.Error(err))
+		http.Error(w, "Failed to render status page", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
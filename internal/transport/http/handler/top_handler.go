@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const defaultTopLimit = 10
+
+func (h *Handler) AdminTop(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	limit := defaultTopLimit
+	if value := query.Get("n"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil || limit <= 0 || limit > maxPageLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "n must be between 1 and " + strconv.Itoa(maxPageLimit)})
+			return
+		}
+	}
+
+	var previous bool
+	switch query.Get("window") {
+	case "", "current":
+	case "previous":
+		previous = true
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "window must be current or previous"})
+		return
+	}
+
+	report, ok := h.top.Report(limit, previous)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "No completed window yet"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"CloudBalancer/internal/auth"
+	"CloudBalancer/internal/requestid"
+
+	"go.uber.org/zap"
+)
+
+type AdminAuthMiddleware struct {
+	authenticator *auth.Authenticator
+	logger        *zap.Logger
+}
+
+func NewAdminAuthMiddleware(authenticator *auth.Authenticator, logger *zap.Logger) *AdminAuthMiddleware {
+	return &AdminAuthMiddleware{
+		authenticator: authenticator,
+		logger:        logger,
+	}
+}
+
+func (m *AdminAuthMiddleware) Middleware(next http.Handler) http.Handler {
+	return m.wrap(next, func(identity auth.Identity, r *http.Request) error {
+		return identity.Authorize(r.Method)
+	})
+}
+
+func (m *AdminAuthMiddleware) OperatorMiddleware(next http.Handler) http.Handler {
+	return m.wrap(next, func(identity auth.Identity, r *http.Request) error {
+		return identity.AuthorizeOperator()
+	})
+}
+
+func (m *AdminAuthMiddleware) wrap(next http.Handler, authorize func(auth.Identity, *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.authenticator.Enabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		identity, err := m.authenticator.Authenticate(r)
+		if err != nil {
+			m.logger.Debug("Admin API authentication failed",
+				requestid.Field(r.Context()),
+				zap.String("path", r.URL.Path),
+				zap.Error(err),
+			)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeAuthError(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if err := authorize(identity, r); err != nil {
+			m.logger.Warn("Admin API request forbidden",
+				requestid.Field(r.Context()),
+				zap.String("subject", identity.Subject),
+				zap.Strings("roles", identity.Roles),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+			)
+			writeAuthError(w, http.StatusForbidden, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithIdentity(r.Context(), identity)))
+	})
+}
+
+func writeAuthError(w http.ResponseWriter, status int, err error) {
+	message := err.Error()
+	if status == http.StatusUnauthorized && !errors.Is(err, auth.ErrMissingToken) {
+		message = "invalid bearer token"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
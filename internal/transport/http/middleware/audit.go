@@ -0,0 +1,210 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"CloudBalancer/internal/audit"
+	"CloudBalancer/internal/auth"
+	"CloudBalancer/internal/requestid"
+)
+
+const (
+	maxAuditBodySize = 64 << 10
+	redactedValue    = "[REDACTED]"
+)
+
+var secretFieldNames = []string{"password", "secret", "authorization", "cookie", "apikey"}
+
+type AuditMiddleware struct {
+	log      *audit.Log
+	snapshot http.Handler
+}
+
+func NewAuditMiddleware(log *audit.Log, snapshot http.Handler) *AuditMiddleware {
+	return &AuditMiddleware{
+		log:      log,
+		snapshot: snapshot,
+	}
+}
+
+func (m *AuditMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.IsReadOnly(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := audit.Entry{
+			Time:       time.Now(),
+			Actor:      "anonymous",
+			RemoteAddr: r.RemoteAddr,
+			RequestID:  requestid.FromContext(r.Context()),
+			Method:     r.Method,
+			Path:       r.URL.Path,
+			Query:      r.URL.RawQuery,
+		}
+		if identity, ok := auth.IdentityFromContext(r.Context()); ok {
+			entry.Actor = identity.Subject
+			entry.Roles = identity.Roles
+		}
+
+		if r.Body != nil {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBodySize+1))
+			if err == nil {
+				entry.Request = rawJSON(body)
+			}
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		}
+
+		snapshotPath := auditSnapshotPath(r.URL.Path)
+		if snapshotPath != "" {
+			entry.Before = m.capture(r, snapshotPath)
+		}
+
+		captureWriter := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(captureWriter, r)
+		entry.Status = captureWriter.statusCode
+
+		if entry.Status >= http.StatusBadRequest {
+			entry.Before = nil
+		} else if snapshotPath != "" {
+			entry.After = m.capture(r, snapshotPath)
+		}
+
+		m.log.Record(entry)
+	})
+}
+
+func (m *AuditMiddleware) capture(r *http.Request, path string) json.RawMessage {
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, path, nil)
+	if err != nil {
+		return nil
+	}
+	req.URL.RawQuery = r.URL.RawQuery
+
+	rec := &snapshotRecorder{header: make(http.Header), statusCode: http.StatusOK}
+	m.snapshot.ServeHTTP(rec, req)
+	if rec.statusCode != http.StatusOK || rec.overflow {
+		return nil
+	}
+	return rawJSON(rec.body.Bytes())
+}
+
+func auditSnapshotPath(path string) string {
+	switch {
+	case path == "/admin/strategy":
+		return "/admin/strategies"
+	case path == "/admin/config/reload", strings.HasPrefix(path, "/admin/config/rollback/"):
+		return "/admin/config"
+	case path == "/admin/ratelimit/import":
+		return "/admin/ratelimit/export"
+	case strings.HasSuffix(path, "/reset"):
+		return strings.TrimSuffix(path, "/reset")
+	case strings.HasSuffix(path, "/drain"):
+		return strings.TrimSuffix(path, "/drain")
+	default:
+		return path
+	}
+}
+
+func rawJSON(body []byte) json.RawMessage {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || len(body) > maxAuditBodySize {
+		return nil
+	}
+	if json.Valid(body) {
+		return redactJSON(body)
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
+
+func redactJSON(body []byte) json.RawMessage {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return json.RawMessage(body)
+	}
+	redacted, err := json.Marshal(redactSecrets(value))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func redactSecrets(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSecretField(key) && field != nil && field != "" {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactSecrets(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = redactSecrets(item)
+		}
+	}
+	return value
+}
+
+func isSecretField(name string) bool {
+	name = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(name))
+	if strings.HasSuffix(name, "token") {
+		return true
+	}
+	for _, secret := range secretFieldNames {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+type snapshotRecorder struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+	overflow   bool
+}
+
+func (rec *snapshotRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *snapshotRecorder) WriteHeader(code int) {
+	rec.statusCode = code
+}
+
+func (rec *snapshotRecorder) Write(p []byte) (int, error) {
+	if rec.body.Len()+len(p) > maxAuditBodySize {
+		rec.overflow = true
+		return len(p), nil
+	}
+	return rec.body.Write(p)
+}
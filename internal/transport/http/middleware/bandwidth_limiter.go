@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"CloudBalancer/internal/clientip"
+	"CloudBalancer/internal/rate_limiter"
+)
+
+type BandwidthLimiterMiddleware struct {
+	limiter    *rate_limiter.BandwidthLimiter
+	allowlist  *rate_limiter.Allowlist
+	ipResolver *clientip.Resolver
+}
+
+func NewBandwidthLimiterMiddleware(limiter *rate_limiter.BandwidthLimiter, allowlist *rate_limiter.Allowlist, ipResolver *clientip.Resolver) *BandwidthLimiterMiddleware {
+	return &BandwidthLimiterMiddleware{
+		limiter:    limiter,
+		allowlist:  allowlist,
+		ipResolver: ipResolver,
+	}
+}
+
+func (m *BandwidthLimiterMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !m.limiter.Enabled() || strings.HasPrefix(r.URL.Path, "/admin/") || r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		clientID := getClientID(r, m.ipResolver)
+		if m.allowlist.Allows(r, clientID, m.ipResolver.ClientIP(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(&throttledWriter{
+			ResponseWriter: w,
+			ctx:            r.Context(),
+			clientID:       clientID,
+			limiter:        m.limiter,
+		}, r)
+	})
+}
+
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx      context.Context
+	clientID string
+	limiter  *rate_limiter.BandwidthLimiter
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := min(len(p), tw.limiter.Burst())
+		if err := tw.limiter.WaitN(tw.ctx, tw.clientID, chunk); err != nil {
+			return written, err
+		}
+
+		n, err := tw.ResponseWriter.Write(p[:chunk])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[chunk:]
+	}
+	return written, nil
+}
+
+func (tw *throttledWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
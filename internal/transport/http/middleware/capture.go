@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"CloudBalancer/internal/capture"
+	"CloudBalancer/internal/clientip"
+	"CloudBalancer/internal/requestid"
+	"CloudBalancer/internal/rewrite"
+	"CloudBalancer/internal/routing"
+)
+
+type CaptureMiddleware struct {
+	store      *capture.Store
+	routes     *routing.Table
+	rewrites   *rewrite.Engine
+	ipResolver *clientip.Resolver
+}
+
+func NewCaptureMiddleware(store *capture.Store, routes *routing.Table, rewrites *rewrite.Engine, ipResolver *clientip.Resolver) *CaptureMiddleware {
+	return &CaptureMiddleware{
+		store:      store,
+		routes:     routes,
+		rewrites:   rewrites,
+		ipResolver: ipResolver,
+	}
+}
+
+func (m *CaptureMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID := getClientID(r, m.ipResolver)
+		rewritten, _ := m.rewrites.Request(r)
+		route := m.routes.Match(rewritten).Path
+		ruleID, ok := m.store.Match(clientID, route)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		limit := m.store.MaxBodySize()
+		requestHeaders := r.Header.Clone()
+
+		requestBody, _ := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(requestBody), r.Body), r.Body}
+		requestTruncated := len(requestBody) > limit
+		if requestTruncated {
+			requestBody = requestBody[:limit]
+		}
+
+		recorder := &captureWriter{ResponseWriter: w, status: http.StatusOK, limit: limit}
+		next.ServeHTTP(recorder, r)
+		if recorder.headers == nil {
+			recorder.headers = w.Header().Clone()
+		}
+
+		m.store.Record(capture.Capture{
+			Time:       start,
+			RuleID:     ruleID,
+			RequestID:  requestid.FromContext(r.Context()),
+			ClientID:   clientID,
+			Route:      route,
+			Method:     r.Method,
+			URL:        r.URL.String(),
+			Status:     recorder.status,
+			DurationMs: float64(time.Since(start)) / float64(time.Millisecond),
+			Request:    m.store.Message(requestHeaders, requestBody, requestTruncated),
+			Response:   m.store.Message(recorder.headers, recorder.body.Bytes(), recorder.truncated),
+		})
+	})
+}
+
+type captureWriter struct {
+	http.ResponseWriter
+	status    int
+	headers   http.Header
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (cw *captureWriter) WriteHeader(code int) {
+	if cw.headers == nil && code >= http.StatusOK {
+		cw.status = code
+		cw.headers = cw.ResponseWriter.Header().Clone()
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *captureWriter) Write(b []byte) (int, error) {
+	if cw.headers == nil {
+		cw.WriteHeader(http.StatusOK)
+	}
+	room := max(cw.limit-cw.body.Len(), 0)
+	if len(b) > room {
+		cw.truncated = true
+	}
+	cw.body.Write(b[:min(len(b), room)])
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *captureWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"CloudBalancer/internal/clientip"
+	"CloudBalancer/internal/errorpage"
+	"CloudBalancer/internal/rate_limiter"
+	"CloudBalancer/internal/requestid"
+	"CloudBalancer/internal/routing"
+	applog "CloudBalancer/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+type ConcurrencyLimiterMiddleware struct {
+	limiter    *rate_limiter.ConcurrencyLimiter
+	allowlist  *rate_limiter.Allowlist
+	metrics    *rate_limiter.Metrics
+	ipResolver *clientip.Resolver
+	errorPages *errorpage.Pages
+	logger     *zap.Logger
+}
+
+func NewConcurrencyLimiterMiddleware(limiter *rate_limiter.ConcurrencyLimiter, allowlist *rate_limiter.Allowlist, metrics *rate_limiter.Metrics, ipResolver *clientip.Resolver, errorPages *errorpage.Pages, logger *zap.Logger) *ConcurrencyLimiterMiddleware {
+	return &ConcurrencyLimiterMiddleware{
+		limiter:    limiter,
+		allowlist:  allowlist,
+		metrics:    metrics,
+		ipResolver: ipResolver,
+		errorPages: errorPages,
+		logger:     logger,
+	}
+}
+
+func (m *ConcurrencyLimiterMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/admin/") || r.URL.Path == "/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		clientID := getClientID(r, m.ipResolver)
+		if m.allowlist.Allows(r, clientID, m.ipResolver.ClientIP(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		release, err := m.limiter.Acquire(clientID)
+		if err != nil {
+			var routePath string
+			if route := routing.RouteFromContext(r.Context()); route != nil {
+				routePath = route.Path
+			}
+			m.metrics.Record(clientID, routePath, rate_limiter.OutcomeConcurrency)
+
+			applog.ForRequest(r.Context(), m.logger).Debug("Concurrency limit exceeded",
+				requestid.Field(r.Context()),
+				zap.String("client_id", clientID),
+				zap.String("path", r.URL.Path),
+				zap.Int("active", m.limiter.Active(clientID)),
+				zap.Int("total", m.limiter.Total()),
+				zap.Error(err),
+			)
+
+			if errors.Is(err, rate_limiter.ErrGlobalConcurrency) {
+				m.errorPages.WriteRetry(w, r, http.StatusServiceUnavailable, errorpage.CodeOverloaded, "Server is handling too many requests, please retry later", 1)
+				return
+			}
+			m.errorPages.WriteRetry(w, r, http.StatusTooManyRequests, errorpage.CodeConcurrencyLimited, "Too many concurrent requests", 1)
+			return
+		}
+		defer release()
+
+		next.ServeHTTP(w, r)
+	})
+}
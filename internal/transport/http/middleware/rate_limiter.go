@@ -1,23 +1,52 @@
 package middleware
 
 import (
-	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"CloudBalancer/internal/clientip"
+	"CloudBalancer/internal/errorpage"
+	"CloudBalancer/internal/events"
 	"CloudBalancer/internal/rate_limiter"
+	"CloudBalancer/internal/requestid"
+	"CloudBalancer/internal/rewrite"
+	"CloudBalancer/internal/routing"
+	applog "CloudBalancer/pkg/logger"
 
 	"go.uber.org/zap"
 )
 
 type RateLimiterMiddleware struct {
 	rateLimiter rate_limiter.RateLimiter
+	allowlist   *rate_limiter.Allowlist
+	bans        *rate_limiter.BanList
+	routes      *routing.Table
+	rewrites    *rewrite.Engine
+	maxWait     time.Duration
+	quota       *rate_limiter.Quota
+	metrics     *rate_limiter.Metrics
+	ipResolver  *clientip.Resolver
+	errorPages  *errorpage.Pages
+	events      *events.Bus
 	logger      *zap.Logger
 }
 
-func NewRateLimiterMiddleware(rateLimiter rate_limiter.RateLimiter, logger *zap.Logger) *RateLimiterMiddleware {
+func NewRateLimiterMiddleware(rateLimiter rate_limiter.RateLimiter, allowlist *rate_limiter.Allowlist, bans *rate_limiter.BanList, routes *routing.Table, rewrites *rewrite.Engine, maxWait time.Duration, quota *rate_limiter.Quota, metrics *rate_limiter.Metrics, ipResolver *clientip.Resolver, errorPages *errorpage.Pages, bus *events.Bus, logger *zap.Logger) *RateLimiterMiddleware {
 	return &RateLimiterMiddleware{
 		rateLimiter: rateLimiter,
+		allowlist:   allowlist,
+		bans:        bans,
+		routes:      routes,
+		rewrites:    rewrites,
+		maxWait:     maxWait,
+		quota:       quota,
+		metrics:     metrics,
+		ipResolver:  ipResolver,
+		errorPages:  errorPages,
+		events:      bus,
 		logger:      logger,
 	}
 }
@@ -29,38 +58,117 @@ func (m *RateLimiterMiddleware) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		clientID := getClientID(r)
+		clientID := getClientID(r, m.ipResolver)
+		if m.allowlist.Allows(r, clientID, m.ipResolver.ClientIP(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rewritten, _ := m.rewrites.Request(r)
+		route := m.routes.Match(rewritten)
+		r = r.WithContext(routing.WithRoute(r.Context(), route))
+
+		if ban, banned := m.bans.Check(clientID); banned {
+			m.metrics.Record(clientID, route.Path, rate_limiter.OutcomeBanned)
+			retryAfter := max(int(math.Ceil(time.Until(ban.Until).Seconds())), 1)
+			m.errorPages.WriteRetry(w, r, m.bans.Status(), errorpage.CodeClientBanned, "Client is temporarily banned due to repeated rate limit violations.", retryAfter)
+			return
+		}
+
+		cost := route.Cost
+		allowed, delay := m.allow(clientID, cost)
+		status := m.rateLimiter.Status(clientID)
+		setRateLimitHeaders(w.Header(), status)
+
+		if allowed && m.quota.Enabled() {
+			quotaStatus, ok := m.quota.Consume(clientID)
+			setQuotaHeaders(w.Header(), quotaStatus)
+			if !ok {
+				m.metrics.Record(clientID, route.Path, rate_limiter.OutcomeQuotaExceeded)
+				applog.ForRequest(r.Context(), m.logger).Debug("Request quota exhausted",
+					requestid.Field(r.Context()),
+					zap.String("client_id", clientID),
+					zap.String("path", r.URL.Path),
+					zap.Int64("limit", quotaStatus.Limit),
+					zap.Time("reset", quotaStatus.Reset),
+				)
+
+				retryAfter := max(int(math.Ceil(time.Until(quotaStatus.Reset).Seconds())), 1)
+				m.errorPages.WriteRetry(w, r, http.StatusTooManyRequests, errorpage.CodeQuotaExceeded, "Request quota exhausted for the current period.", retryAfter)
+				return
+			}
+		}
+
+		if allowed && delay > 0 {
+			applog.ForRequest(r.Context(), m.logger).Debug("Request delayed by rate limit shaping",
+				requestid.Field(r.Context()),
+				zap.String("client_id", clientID),
+				zap.String("path", r.URL.Path),
+				zap.Duration("delay", delay),
+			)
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+		}
 
-		if !m.rateLimiter.Allow(clientID) {
-			m.logger.Debug("Rate limit exceeded",
+		if !allowed {
+			retryAfter := m.rateLimiter.RetryAfterN(clientID, cost)
+			m.metrics.Record(clientID, route.Path, rate_limiter.OutcomeRateLimited)
+			applog.ForRequest(r.Context(), m.logger).Debug("Rate limit exceeded",
+				requestid.Field(r.Context()),
 				zap.String("client_id", clientID),
 				zap.String("path", r.URL.Path),
+				zap.Int("cost", cost),
 				zap.Float64("rate", m.rateLimiter.GetRate(clientID)),
 				zap.Int("burst", m.rateLimiter.GetBurst(clientID)),
+				zap.Duration("retry_after", retryAfter),
 			)
 
-			w.Header().Set("Content-Type", "application/json")
-			w.Header().Set("Retry-After", "60")
-			w.WriteHeader(http.StatusTooManyRequests)
-			json.NewEncoder(w).Encode(map[string]string{
-				"error": "Rate limit exceeded. Please slow down your requests.",
-			})
+			if ban, banned := m.bans.RecordViolation(clientID); banned {
+				m.logger.Warn("Client temporarily banned",
+					requestid.Field(r.Context()),
+					zap.String("client_id", clientID),
+					zap.Time("until", ban.Until),
+					zap.Int("offenses", ban.Offenses),
+				)
+				m.events.Publish(events.ClientBanned, ban)
+			}
+
+			m.errorPages.WriteRetry(w, r, http.StatusTooManyRequests, errorpage.CodeRateLimited, "Rate limit exceeded. Please slow down your requests.", max(int(math.Ceil(retryAfter.Seconds())), 1))
 			return
 		}
 
+		m.metrics.Record(clientID, route.Path, rate_limiter.OutcomeAllowed)
 		next.ServeHTTP(w, r)
 	})
 }
 
-func getClientID(r *http.Request) string {
+func (m *RateLimiterMiddleware) allow(clientID string, cost int) (bool, time.Duration) {
+	delay, allowed := m.rateLimiter.ReserveN(clientID, cost, m.maxWait)
+	return allowed, delay
+}
+
+func setRateLimitHeaders(h http.Header, status rate_limiter.Status) {
+	h.Set("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(max(status.Remaining, 0)))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(status.Reset.Seconds()))))
+}
+
+func setQuotaHeaders(h http.Header, status rate_limiter.QuotaStatus) {
+	h.Set("X-Quota-Limit", strconv.FormatInt(status.Limit, 10))
+	h.Set("X-Quota-Remaining", strconv.FormatInt(status.Remaining, 10))
+	h.Set("X-Quota-Reset", strconv.Itoa(int(math.Ceil(time.Until(status.Reset).Seconds()))))
+}
+
+func getClientID(r *http.Request, ipResolver *clientip.Resolver) string {
 	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
 		return "api:" + apiKey
 	}
 
-	if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
-		ips := strings.Split(forwardedFor, ",")
-		return strings.TrimSpace(ips[0])
-	}
-
-	return strings.Split(r.RemoteAddr, ":")[0]
+	return ipResolver.ClientIP(r)
 }
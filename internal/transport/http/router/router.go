@@ -1,37 +1,141 @@
 package router
 
 import (
+	"context"
+	"expvar"
 	"net/http"
+	"net/http/pprof"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"CloudBalancer/config"
+	"CloudBalancer/internal/accesslog"
+	"CloudBalancer/internal/audit"
+	"CloudBalancer/internal/auth"
+	"CloudBalancer/internal/cache"
+	"CloudBalancer/internal/capture"
+	"CloudBalancer/internal/clientip"
+	"CloudBalancer/internal/errorpage"
+	"CloudBalancer/internal/events"
 	"CloudBalancer/internal/load_balancer"
+	"CloudBalancer/internal/maintenance"
+	"CloudBalancer/internal/metrics"
 	"CloudBalancer/internal/rate_limiter"
+	"CloudBalancer/internal/requestid"
+	"CloudBalancer/internal/rewrite"
+	"CloudBalancer/internal/routing"
+	"CloudBalancer/internal/topk"
+	"CloudBalancer/internal/traceheaders"
 	"CloudBalancer/internal/transport/http/handler"
 	"CloudBalancer/internal/transport/http/middleware"
+	applog "CloudBalancer/pkg/logger"
 
 	"go.uber.org/zap"
 )
 
 type Router struct {
-	mux          *http.ServeMux
-	logger       *zap.Logger
-	handler      *handler.Handler
-	loadBalancer load_balancer.LoadBalancer
-	rateLimiter  rate_limiter.RateLimiter
+	mux           *http.ServeMux
+	adminMux      *http.ServeMux
+	logger        *zap.Logger
+	handler       *handler.Handler
+	loadBalancer  load_balancer.LoadBalancer
+	rateLimiter   rate_limiter.RateLimiter
+	shapingWait   time.Duration
+	quota         *rate_limiter.Quota
+	concurrency   *rate_limiter.ConcurrencyLimiter
+	bandwidth     *rate_limiter.BandwidthLimiter
+	allowlist     *rate_limiter.Allowlist
+	bans          *rate_limiter.BanList
+	tiers         *rate_limiter.Tiers
+	metrics       *rate_limiter.Metrics
+	routes        *routing.Table
+	rewrites      *rewrite.Engine
+	ipResolver    *clientip.Resolver
+	errorPages    *errorpage.Pages
+	authenticator *auth.Authenticator
+	audit         *audit.Log
+	events        *events.Bus
+	requests      *metrics.CounterVec
+	duration      *metrics.HistogramVec
+	inFlight      *metrics.GaugeVec
+	accessLog     *accesslog.Logger
+	sampler       *applog.Sampler
+	top           *topk.Tracker
+	pprof         bool
+	captures      *capture.Store
+	traceHeaders  *traceheaders.Policy
 }
 
-func NewRouter(logger *zap.Logger, lb load_balancer.LoadBalancer, rl rate_limiter.RateLimiter) *Router {
+func NewRouter(cfg *config.Config, logger *zap.Logger, lb load_balancer.LoadBalancer, rl rate_limiter.RateLimiter, shapingWait time.Duration, quota *rate_limiter.Quota, concurrencyLimiter *rate_limiter.ConcurrencyLimiter, bandwidthLimiter *rate_limiter.BandwidthLimiter, allowlist *rate_limiter.Allowlist, bans *rate_limiter.BanList, tiers *rate_limiter.Tiers, rateLimitMetrics *rate_limiter.Metrics, ipResolver *clientip.Resolver, routes *routing.Table, rewrites *rewrite.Engine, responseCache *cache.Cache, errorPages *errorpage.Pages, maintenanceMode *maintenance.Mode, authenticator *auth.Authenticator, auditLog *audit.Log, bus *events.Bus, registry *metrics.Registry, accessLog *accesslog.Logger, logLevel zap.AtomicLevel, top *topk.Tracker, captures *capture.Store) *Router {
 	return &Router{
-		mux:          http.NewServeMux(),
-		logger:       logger,
-		loadBalancer: lb,
-		rateLimiter:  rl,
-		handler:      handler.NewHandler(lb, rl, logger),
+		mux:           http.NewServeMux(),
+		adminMux:      http.NewServeMux(),
+		logger:        logger,
+		loadBalancer:  lb,
+		rateLimiter:   rl,
+		shapingWait:   shapingWait,
+		quota:         quota,
+		concurrency:   concurrencyLimiter,
+		bandwidth:     bandwidthLimiter,
+		allowlist:     allowlist,
+		bans:          bans,
+		tiers:         tiers,
+		metrics:       rateLimitMetrics,
+		routes:        routes,
+		rewrites:      rewrites,
+		ipResolver:    ipResolver,
+		errorPages:    errorPages,
+		authenticator: authenticator,
+		audit:         auditLog,
+		events:        bus,
+		accessLog:     accessLog,
+		sampler:       newSampler(cfg.Logging.Sampling),
+		top:           top,
+		pprof:         cfg.Observability.Pprof.Enabled,
+		captures:      captures,
+		traceHeaders:  traceheaders.NewPolicy(cfg.Server.TraceHeaders),
+		requests: registry.NewCounter("cloudbalancer_http_requests_total",
+			"HTTP requests by matched route, method and status code.", "route", "method", "code"),
+		duration: registry.NewHistogram("cloudbalancer_http_request_duration_seconds",
+			"End-to-end HTTP request latency by matched route and method.", metrics.DefaultBuckets, "route", "method"),
+		inFlight: registry.NewGauge("cloudbalancer_http_requests_in_flight",
+			"HTTP requests currently being served."),
+		handler: handler.NewHandler(cfg, lb, rl, allowlist, bans, tiers, rateLimitMetrics, routes, rewrites, responseCache, errorPages, maintenanceMode, auditLog, bus, registry, top, captures, ipResolver, logLevel, logger),
 	}
 }
 
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.serve(r.mux, w, req)
+}
+
+func (r *Router) BeginShutdown() {
+	r.handler.BeginShutdown()
+}
+
+func (r *Router) ReloadRemoteConfig() {
+	r.handler.ReloadRemoteConfig()
+}
+
+func (r *Router) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.serve(r.adminMux, w, req)
+	})
+}
+
+func (r *Router) serve(mux *http.ServeMux, w http.ResponseWriter, req *http.Request) {
 	start := time.Now()
+
+	requestID := r.traceHeaders.RequestID(req)
+	r.traceHeaders.Apply(req.Header)
+	req.Header.Set(requestid.Header, requestID)
+	w.Header().Set(requestid.Header, requestID)
+	sampled := r.sampler == nil || r.sampler.Sample()
+	pattern := new(string)
+	ctx := requestid.WithID(req.Context(), requestID)
+	ctx = applog.WithSampled(ctx, sampled)
+	req = req.WithContext(context.WithValue(ctx, patternKey{}, pattern))
 	path := req.URL.Path
 	raw := req.URL.RawQuery
 
@@ -40,42 +144,298 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		statusCode:     http.StatusOK,
 	}
 
-	r.mux.ServeHTTP(captureWriter, req)
+	r.inFlight.Add(1)
+	defer r.inFlight.Add(-1)
+	mux.ServeHTTP(captureWriter, req)
 
 	latency := time.Since(start)
-	clientIP := req.RemoteAddr
+	clientIP := r.ipResolver.ClientIP(req)
 	method := req.Method
 	statusCode := captureWriter.statusCode
 
+	var matched *routing.Route
+	if mux == r.mux {
+		matched = r.matchRoute(req)
+	}
+	route := r.routeLabel(req, *pattern, matched)
+	r.requests.Inc(route, methodLabel(method), strconv.Itoa(statusCode))
+	r.duration.Observe(latency.Seconds(), route, methodLabel(method))
+	if req.Pattern == "/" {
+		r.top.ObservePath(req.URL.Path, latency, statusCode >= http.StatusInternalServerError)
+	}
+
+	var accessLogPolicy *accesslog.RoutePolicy
+	if matched != nil {
+		accessLogPolicy = matched.AccessLog
+	}
+	if !accessLogPolicy.Keep(statusCode) {
+		return
+	}
+	extra := accessLogPolicy.Fields(req)
+
+	if r.accessLog != nil {
+		user, _, _ := req.BasicAuth()
+		r.accessLog.Log(accesslog.Entry{
+			Time:       start,
+			RequestID:  requestID,
+			ClientIP:   clientIP,
+			RemoteAddr: req.RemoteAddr,
+			Method:     method,
+			URI:        req.RequestURI,
+			Path:       path,
+			Query:      raw,
+			Protocol:   req.Proto,
+			Host:       req.Host,
+			Status:     statusCode,
+			Bytes:      captureWriter.bytes,
+			Duration:   latency,
+			Referer:    req.Referer(),
+			UserAgent:  req.UserAgent(),
+			Route:      route,
+			User:       user,
+			Extra:      extra,
+		})
+		return
+	}
+
+	if r.sampler != nil && !r.sampler.Keep(sampled, statusCode, latency) {
+		return
+	}
+
 	if raw != "" {
 		path = path + "?" + raw
 	}
 
-	r.logger.Info("Request processed",
+	fields := []zap.Field{
+		zap.String("request_id", requestID),
 		zap.String("path", path),
 		zap.String("client_ip", clientIP),
 		zap.String("method", method),
 		zap.Int("status_code", statusCode),
 		zap.Duration("latency", latency),
-	)
+	}
+	for _, field := range extra {
+		fields = append(fields, zap.String(field.Name, field.Value))
+	}
+	r.logger.Info("Request processed", fields...)
 }
 
-func (r *Router) SetupRoutes() {
-	rateLimiterMiddleware := middleware.NewRateLimiterMiddleware(r.rateLimiter, r.logger)
+func (r *Router) SetupRoutes(separateAdmin bool) {
+	rateLimiterMiddleware := middleware.NewRateLimiterMiddleware(r.rateLimiter, r.allowlist, r.bans, r.routes, r.rewrites, r.shapingWait, r.quota, r.metrics, r.ipResolver, r.errorPages, r.events, r.logger)
+	captureMiddleware := middleware.NewCaptureMiddleware(r.captures, r.routes, r.rewrites, r.ipResolver)
+	concurrencyLimiterMiddleware := middleware.NewConcurrencyLimiterMiddleware(r.concurrency, r.allowlist, r.metrics, r.ipResolver, r.errorPages, r.logger)
+	bandwidthLimiterMiddleware := middleware.NewBandwidthLimiterMiddleware(r.bandwidth, r.allowlist, r.ipResolver)
+	adminAuthMiddleware := middleware.NewAdminAuthMiddleware(r.authenticator, r.logger)
+
+	admin := http.NewServeMux()
+	auditMiddleware := middleware.NewAuditMiddleware(r.audit, admin)
+	admin.HandleFunc("GET /admin/audit", r.handler.AdminAudit)
+	admin.HandleFunc("GET /admin/events", r.handler.AdminEvents)
+	admin.HandleFunc("GET /admin/config", r.handler.AdminConfig)
+	admin.HandleFunc("GET /admin/metrics", r.handler.AdminMetrics)
+	admin.HandleFunc("GET /admin/loglevel", r.handler.AdminGetLogLevel)
+	admin.HandleFunc("PUT /admin/loglevel", r.handler.AdminSetLogLevel)
+	admin.HandleFunc("POST /admin/config/reload", r.handler.AdminReloadConfig)
+	admin.HandleFunc("GET /admin/config/history", r.handler.AdminConfigHistory)
+	admin.HandleFunc("GET /admin/config/history/{id}", r.handler.AdminGetConfigRevision)
+	admin.HandleFunc("POST /admin/config/rollback/{id}", r.handler.AdminRollbackConfig)
+	admin.HandleFunc("GET /admin/openapi.json", r.handler.AdminOpenAPI)
+	admin.HandleFunc("GET /admin/stats", r.handler.AdminGetStats)
+	admin.HandleFunc("GET /admin/top", r.handler.AdminTop)
+	admin.HandleFunc("POST /admin/stats/reset", r.handler.AdminResetStats)
+	admin.HandleFunc("GET /admin/backends", r.handler.AdminListBackends)
+	admin.HandleFunc("POST /admin/backends", r.handler.AdminCreateBackend)
+	admin.HandleFunc("GET /admin/backends/{id}", r.handler.AdminGetBackend)
+	admin.HandleFunc("PUT /admin/backends/{id}", r.handler.AdminUpdateBackend)
+	admin.HandleFunc("DELETE /admin/backends/{id}", r.handler.AdminDeleteBackend)
+	admin.HandleFunc("POST /admin/backends/{id}/drain", r.handler.AdminDrainBackend)
+	admin.HandleFunc("DELETE /admin/backends/{id}/drain", r.handler.AdminUndrainBackend)
+	admin.HandleFunc("POST /admin/strategy", r.handler.AdminChangeStrategy)
+	admin.HandleFunc("GET /admin/strategies", r.handler.AdminStrategies)
+	admin.HandleFunc("GET /admin/cache", r.handler.AdminCacheStats)
+	admin.HandleFunc("DELETE /admin/cache", r.handler.AdminPurgeCache)
+	admin.HandleFunc("GET /admin/routes", r.handler.AdminGetRoutes)
+	admin.HandleFunc("PUT /admin/routes", r.handler.AdminReplaceRoutes)
+	admin.HandleFunc("POST /admin/routes", r.handler.AdminUpsertRoute)
+	admin.HandleFunc("DELETE /admin/routes", r.handler.AdminDeleteRoute)
+	admin.HandleFunc("GET /admin/maintenance", r.handler.AdminGetMaintenance)
+	admin.HandleFunc("PUT /admin/maintenance", r.handler.AdminSetMaintenance)
+	admin.HandleFunc("POST /admin/maintenance", r.handler.AdminSetMaintenance)
+	admin.HandleFunc("GET /admin/canary", r.handler.AdminGetCanary)
+	admin.HandleFunc("PUT /admin/canary", r.handler.AdminSetCanary)
+	admin.HandleFunc("POST /admin/canary", r.handler.AdminSetCanary)
+	admin.HandleFunc("GET /admin/deployment", r.handler.AdminGetDeployment)
+	admin.HandleFunc("POST /admin/deployment", r.handler.AdminSwitchDeployment)
+	admin.HandleFunc("GET /admin/ratelimit", r.handler.RateLimitList)
+	admin.HandleFunc("GET /admin/ratelimit/{clientID}", r.handler.RateLimitGet)
+	admin.HandleFunc("POST /admin/ratelimit/{clientID}", r.handler.RateLimitCreate)
+	admin.HandleFunc("PUT /admin/ratelimit/{clientID}", r.handler.RateLimitUpdate)
+	admin.HandleFunc("DELETE /admin/ratelimit/{clientID}", r.handler.RateLimitDelete)
+	admin.HandleFunc("POST /admin/ratelimit/{clientID}/reset", r.handler.RateLimitReset)
+	admin.HandleFunc("GET /admin/ratelimit/export", r.handler.RateLimitExport)
+	admin.HandleFunc("POST /admin/ratelimit/import", r.handler.RateLimitImport)
+	admin.HandleFunc("GET /admin/ratelimit/allowlist", r.handler.RateLimitGetAllowlist)
+	admin.HandleFunc("PUT /admin/ratelimit/allowlist", r.handler.RateLimitSetAllowlist)
+	admin.HandleFunc("GET /admin/ratelimit/bans", r.handler.RateLimitListBans)
+	admin.HandleFunc("DELETE /admin/ratelimit/bans", r.handler.RateLimitLiftBan)
+	admin.HandleFunc("GET /admin/ratelimit/tiers", r.handler.RateLimitListTiers)
+	admin.HandleFunc("PUT /admin/ratelimit/tiers", r.handler.RateLimitSetTier)
+	admin.HandleFunc("DELETE /admin/ratelimit/tiers", r.handler.RateLimitDeleteTier)
+	admin.HandleFunc("GET /admin/ratelimit/metrics", r.handler.RateLimitMetrics)
+	admin.HandleFunc("GET /admin/version", r.handler.AdminVersion)
+	admin.HandleFunc("GET /admin/debug/rules", r.handler.AdminListCaptureRules)
+	admin.HandleFunc("POST /admin/debug/rules", r.handler.AdminCreateCaptureRule)
+	admin.HandleFunc("DELETE /admin/debug/rules/{id}", r.handler.AdminDeleteCaptureRule)
+	admin.HandleFunc("GET /admin/debug/captures", r.handler.AdminListCaptures)
+	admin.HandleFunc("DELETE /admin/debug/captures", r.handler.AdminClearCaptures)
+
+	adminAPI := adminAuthMiddleware.Middleware(auditMiddleware.Middleware(r.handler.ConfigHistoryMiddleware(recordPattern(admin))))
+
+	status := adminAuthMiddleware.Middleware(http.HandlerFunc(r.handler.Status))
+
+	var debug http.Handler
+	if r.pprof {
+		debug = adminAuthMiddleware.OperatorMiddleware(debugMux())
+	}
 
 	r.mux.HandleFunc("/health", r.handler.HealthCheck)
-	r.mux.Handle("/", rateLimiterMiddleware.Middleware(http.HandlerFunc(r.handler.LoadBalancer)))
-	r.mux.HandleFunc("/admin/stats", r.handler.AdminGetStats)
-	r.mux.HandleFunc("/admin/strategy", r.handler.AdminChangeStrategy)
-	r.mux.HandleFunc("/admin/ratelimit/", r.handler.RateLimitHandler)
+	r.mux.HandleFunc("GET /healthz", r.handler.HealthCheck)
+	r.mux.HandleFunc("GET /readyz", r.handler.ReadinessCheck)
+	r.mux.Handle("/", captureMiddleware.Middleware(rateLimiterMiddleware.Middleware(concurrencyLimiterMiddleware.Middleware(bandwidthLimiterMiddleware.Middleware(http.HandlerFunc(r.handler.LoadBalancer))))))
+	if separateAdmin {
+		r.adminMux.HandleFunc("/health", r.handler.HealthCheck)
+		r.adminMux.HandleFunc("GET /healthz", r.handler.HealthCheck)
+		r.adminMux.HandleFunc("GET /readyz", r.handler.ReadinessCheck)
+		r.adminMux.HandleFunc("GET /admin/ui", r.handler.AdminDashboard)
+		r.adminMux.Handle("GET /status", status)
+		r.adminMux.Handle(adminAPIPrefix+"/", versionedAdmin(adminAPIPrefix, adminAPI))
+		r.adminMux.Handle("/admin/", legacyAdmin(adminAPIPrefix, adminAPI))
+		r.mux.Handle("/admin/", http.NotFoundHandler())
+		if debug != nil {
+			r.adminMux.Handle("/debug/", debug)
+		}
+		return
+	}
+	if debug != nil {
+		r.mux.Handle("/debug/", debug)
+	}
+	r.mux.HandleFunc("GET /admin/ui", r.handler.AdminDashboard)
+	r.mux.Handle("GET /status", status)
+	r.mux.Handle(sharedAdminAPIPrefix+"/", versionedAdmin(sharedAdminAPIPrefix, adminAPI))
+	r.mux.Handle("/admin/", legacyAdmin(sharedAdminAPIPrefix, adminAPI))
+}
+
+const (
+	adminAPIVersion      = "v1"
+	adminAPIPrefix       = "/api/" + adminAPIVersion
+	sharedAdminAPIPrefix = "/admin" + adminAPIPrefix
+)
+
+func versionedAdmin(prefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Api-Version", adminAPIVersion)
+
+		r2 := new(http.Request)
+		*r2 = *req
+		r2.URL = new(url.URL)
+		*r2.URL = *req.URL
+		r2.URL.Path = "/admin" + strings.TrimPrefix(req.URL.Path, prefix)
+		r2.URL.RawPath = ""
+		next.ServeHTTP(w, r2)
+	})
+}
+
+func legacyAdmin(prefix string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Api-Version", adminAPIVersion)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", "<"+prefix+strings.TrimPrefix(req.URL.Path, "/admin")+`>; rel="successor-version"`)
+		next.ServeHTTP(w, req)
+	})
+}
+
+func newSampler(cfg config.LogSamplingConfig) *applog.Sampler {
+	if !cfg.Enabled {
+		return nil
+	}
+	return applog.NewSampler(cfg.Rate, cfg.SlowThreshold)
+}
+
+func debugMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /debug/pprof/", pprof.Index)
+	mux.HandleFunc("GET /debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("GET /debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("GET /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("POST /debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("GET /debug/pprof/trace", pprof.Trace)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	return mux
+}
+
+type patternKey struct{}
+
+func recordPattern(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mux.ServeHTTP(w, req)
+		if pattern, ok := req.Context().Value(patternKey{}).(*string); ok {
+			*pattern = req.Pattern
+		}
+	})
+}
+
+func (r *Router) matchRoute(req *http.Request) *routing.Route {
+	rewritten, _ := r.rewrites.Request(req)
+	return r.routes.Match(rewritten)
+}
+
+func (r *Router) routeLabel(req *http.Request, inner string, matched *routing.Route) string {
+	pattern := inner
+	if pattern == "" {
+		pattern = req.Pattern
+	}
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		pattern = path
+	}
+
+	switch pattern {
+	case "":
+		return "unmatched"
+	case "/":
+		if matched == nil {
+			matched = r.matchRoute(req)
+		}
+		return matched.Path
+	}
+	return pattern
+}
+
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
 }
 
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	bytes      int64
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
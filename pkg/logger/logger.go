@@ -1,15 +1,20 @@
 package logger
 
 import (
+	"os"
+	"time"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 type Logger struct {
 	*zap.Logger
+	Level   zap.AtomicLevel
+	buffers []*zapcore.BufferedWriteSyncer
 }
 
-func NewLogger(env string) (*Logger, error) {
+func NewLogger(env, level string, sinks []Sink, bufferSize int, flushInterval time.Duration) (*Logger, error) {
 	var cfg zap.Config
 
 	if env == "production" {
@@ -18,21 +23,72 @@ func NewLogger(env string) (*Logger, error) {
 		cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	} else {
 		cfg = zap.NewDevelopmentConfig()
-		cfg.EncoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 
-	logger, err := cfg.Build()
-	if err != nil {
-		return nil, err
+	if level != "" {
+		if err := cfg.Level.UnmarshalText([]byte(level)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(sinks) == 0 {
+		sinks = []Sink{{Output: StreamOutput(zapcore.Lock(os.Stderr)), Level: zapcore.DebugLevel, Terminal: true}}
+	}
+
+	var buffers []*zapcore.BufferedWriteSyncer
+	cores := make([]zapcore.Core, 0, len(sinks))
+	for _, sink := range sinks {
+		output := sink.Output
+		if stream, ok := output.(*streamOutput); ok && bufferSize > 0 {
+			buffer := &zapcore.BufferedWriteSyncer{WS: stream.ws, Size: bufferSize, FlushInterval: flushInterval}
+			buffers = append(buffers, buffer)
+			output = StreamOutput(buffer)
+		}
+
+		encoderConfig := cfg.EncoderConfig
+		if cfg.Development && sink.Terminal {
+			encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		}
+		encoder := zapcore.NewJSONEncoder(encoderConfig)
+		if cfg.Encoding == "console" {
+			encoder = zapcore.NewConsoleEncoder(encoderConfig)
+		}
+		cores = append(cores, &sinkCore{
+			LevelEnabler: levelFloor{base: cfg.Level, floor: sink.Level},
+			encoder:      encoder,
+			output:       output,
+		})
+	}
+
+	core := zapcore.NewTee(cores...)
+	if cfg.Sampling != nil {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, cfg.Sampling.Initial, cfg.Sampling.Thereafter)
+	}
+
+	options := []zap.Option{zap.AddCaller(), zap.ErrorOutput(zapcore.Lock(os.Stderr))}
+	if cfg.Development {
+		options = append(options, zap.Development(), zap.AddStacktrace(zapcore.WarnLevel))
+	} else {
+		options = append(options, zap.AddStacktrace(zapcore.ErrorLevel))
 	}
 
-	return &Logger{logger}, nil
+	return &Logger{zap.New(core, options...), cfg.Level, buffers}, nil
 }
 
 func (l *Logger) With(fields ...zap.Field) *Logger {
-	return &Logger{l.Logger.With(fields...)}
+	return &Logger{l.Logger.With(fields...), l.Level, l.buffers}
 }
 
 func (l *Logger) Sync() error {
 	return l.Logger.Sync()
 }
+
+func (l *Logger) Close() error {
+	err := l.Logger.Sync()
+	for _, buffer := range l.buffers {
+		if stopErr := buffer.Stop(); err == nil {
+			err = stopErr
+		}
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type sampledKey struct{}
+
+type Sampler struct {
+	rate          uint64
+	slowThreshold time.Duration
+	counter       atomic.Uint64
+}
+
+func NewSampler(rate int, slowThreshold time.Duration) *Sampler {
+	return &Sampler{rate: uint64(max(rate, 1)), slowThreshold: slowThreshold}
+}
+
+func (s *Sampler) Sample() bool {
+	return (s.counter.Add(1)-1)%s.rate == 0
+}
+
+func (s *Sampler) Keep(sampled bool, status int, latency time.Duration) bool {
+	return sampled || status >= 500 || (s.slowThreshold > 0 && latency >= s.slowThreshold)
+}
+
+func WithSampled(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, sampledKey{}, sampled)
+}
+
+func ForRequest(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if sampled, ok := ctx.Value(sampledKey{}).(bool); ok && !sampled && logger.Core().Enabled(zapcore.WarnLevel) {
+		return logger.WithOptions(zap.IncreaseLevel(zapcore.WarnLevel))
+	}
+	return logger
+}
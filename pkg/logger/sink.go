@@ -0,0 +1,238 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type Output interface {
+	Write(level zapcore.Level, p []byte) error
+	Sync() error
+}
+
+type Sink struct {
+	Output   Output
+	Level    zapcore.Level
+	Terminal bool
+}
+
+type streamOutput struct {
+	ws zapcore.WriteSyncer
+}
+
+func StreamOutput(ws zapcore.WriteSyncer) Output {
+	return &streamOutput{ws: ws}
+}
+
+func (o *streamOutput) Write(level zapcore.Level, p []byte) error {
+	_, err := o.ws.Write(p)
+	return err
+}
+
+func (o *streamOutput) Sync() error {
+	return o.ws.Sync()
+}
+
+type sinkCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	output  Output
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &sinkCore{LevelEnabler: c.LevelEnabler, encoder: c.encoder.Clone(), output: c.output}
+	for _, field := range fields {
+		field.AddTo(clone.encoder)
+	}
+	return clone
+}
+
+func (c *sinkCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *sinkCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	err = c.output.Write(entry.Level, buf.Bytes())
+	buf.Free()
+	if err != nil {
+		return err
+	}
+	if entry.Level > zapcore.ErrorLevel {
+		c.output.Sync()
+	}
+	return nil
+}
+
+func (c *sinkCore) Sync() error {
+	return c.output.Sync()
+}
+
+type levelFloor struct {
+	base  zapcore.LevelEnabler
+	floor zapcore.Level
+}
+
+func (l levelFloor) Enabled(level zapcore.Level) bool {
+	return level >= l.floor && l.base.Enabled(level)
+}
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level >= zapcore.DPanicLevel:
+		return 2
+	case level == zapcore.ErrorLevel:
+		return 3
+	case level == zapcore.WarnLevel:
+		return 4
+	case level == zapcore.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+type syslogOutput struct {
+	network  string
+	address  string
+	facility int
+	tag      string
+	hostname string
+	conn     net.Conn
+	mu       sync.Mutex
+}
+
+func NewSyslogOutput(network, address, facility, tag string) (Output, error) {
+	code, ok := syslogFacilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", facility)
+	}
+	hostname, _ := os.Hostname()
+
+	o := &syslogOutput{network: network, address: address, facility: code, tag: tag, hostname: hostname}
+	if err := o.connect(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *syslogOutput) connect() error {
+	if o.network != "" {
+		conn, err := net.DialTimeout(o.network, o.address, 5*time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog at %s://%s: %w", o.network, o.address, err)
+		}
+		o.conn = conn
+		return nil
+	}
+
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+			if conn, err := net.Dial(network, path); err == nil {
+				o.conn = conn
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no local syslog socket found")
+}
+
+func (o *syslogOutput) Write(level zapcore.Level, p []byte) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	message := strings.TrimRight(string(p), "\n")
+	priority := o.facility*8 + syslogSeverity(level)
+
+	var line string
+	if o.network == "" {
+		line = fmt.Sprintf("<%d>%s %s[%d]: %s", priority, time.Now().Format(time.Stamp), o.tag, os.Getpid(), message)
+	} else {
+		line = fmt.Sprintf("<%d>1 %s %s %s %d - - %s", priority, time.Now().Format(time.RFC3339Nano), o.hostname, o.tag, os.Getpid(), message)
+	}
+	if o.network == "tcp" {
+		line = strconv.Itoa(len(line)) + " " + line
+	}
+
+	if _, err := o.conn.Write([]byte(line)); err != nil {
+		o.conn.Close()
+		if err := o.connect(); err != nil {
+			return err
+		}
+		_, err = o.conn.Write([]byte(line))
+		return err
+	}
+	return nil
+}
+
+func (o *syslogOutput) Sync() error {
+	return nil
+}
+
+const journaldSocket = "/run/systemd/journal/socket"
+
+type journaldOutput struct {
+	identifier string
+	conn       *net.UnixConn
+	addr       *net.UnixAddr
+}
+
+func NewJournaldOutput(identifier string) (Output, error) {
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journald socket: %w", err)
+	}
+	addr := &net.UnixAddr{Name: journaldSocket, Net: "unixgram"}
+	if _, err := os.Stat(journaldSocket); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("journald is not available: %w", err)
+	}
+	return &journaldOutput{identifier: identifier, conn: conn, addr: addr}, nil
+}
+
+func (o *journaldOutput) Write(level zapcore.Level, p []byte) error {
+	var b strings.Builder
+	writeJournalField(&b, "PRIORITY", strconv.Itoa(syslogSeverity(level)))
+	writeJournalField(&b, "SYSLOG_IDENTIFIER", o.identifier)
+	writeJournalField(&b, "MESSAGE", strings.TrimRight(string(p), "\n"))
+
+	_, _, err := o.conn.WriteMsgUnix([]byte(b.String()), nil, o.addr)
+	return err
+}
+
+func writeJournalField(b *strings.Builder, name, value string) {
+	if !strings.Contains(value, "\n") {
+		b.WriteString(name + "=" + value + "\n")
+		return
+	}
+
+	b.WriteString(name + "\n")
+	size := uint64(len(value))
+	for i := 0; i < 8; i++ {
+		b.WriteByte(byte(size >> (8 * i)))
+	}
+	b.WriteString(value + "\n")
+}
+
+func (o *journaldOutput) Sync() error {
+	return nil
+}